@@ -50,6 +50,7 @@ type MeshFederationSpec struct {
 	// +kubebuilder:validation:Required
 	Network string `json:"network"`
 
+	// SPIFFE trust domain of the local mesh, which is advertised to remote peers to verify identities of exported services.
 	// +kubebuilder:default:=cluster.local
 	TrustDomain string `json:"trustDomain"`
 
@@ -79,7 +80,7 @@ type MeshFederationStatus struct {
 type PortConfig struct {
 	// TODO: Needs clarification: This was marked as optional in the CRD proposal, but the comment states it cannot be empty
 	// Port name of the ingress gateway Service.
-	// This is relevant only when the ingress type is openshift-router, but it cannot be empty
+	// This is relevant only when the ingress type is openshift-router or gateway-api, but it cannot be empty
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
 
@@ -100,15 +101,17 @@ type GatewayConfig struct {
 
 type IngressConfig struct {
 	// Local ingress type specifies how to expose exported services.
-	// Currently, only two types are supported: istio and openshift-router.
+	// Currently, three types are supported: istio, openshift-router and gateway-api.
 	// If "istio" is set, then the controller assumes that the Service associated with federation ingress gateway
 	// is LoadBalancer or NodePort and is directly accessible for remote peers, and then it only creates
 	// an auto-passthrough Gateway to expose exported Services.
 	// When "openshift-router" is enabled, then the controller creates also OpenShift Routes and applies EnvoyFilters
 	// to customize the SNI filter in the auto-passthrough Gateway, because the default SNI DNAT format used by Istio
 	// is not supported by OpenShift Router.
+	// When "gateway-api" is enabled, then the controller creates a Kubernetes Gateway API Gateway with a TLS passthrough
+	// listener and TLSRoutes for exported services.
 	// +kubebuilder:default:=istio
-	// +kubebuilder:validation:Enum=istio;openshift-router
+	// +kubebuilder:validation:Enum=istio;openshift-router;gateway-api
 	Type string `json:"type"`
 
 	// Specifies the selector and port config of the ingress gateway
@@ -117,6 +120,11 @@ type IngressConfig struct {
 }
 
 type ExportRules struct {
+	// NamespaceSelector is a label query over namespaces, which restricts ServiceSelectors
+	// to Services in matching namespaces.
+	// A null namespace selector matches all namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// ServiceSelectors is a label query over K8s Services in all namespaces.
 	// The result of matchLabels and matchExpressions are ANDed.
 	// An empty service selector matches all Services.
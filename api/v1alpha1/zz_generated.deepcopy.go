@@ -26,6 +26,11 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportRules) DeepCopyInto(out *ExportRules) {
 	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ServiceSelectors != nil {
 		in, out := &in.ServiceSelectors, &out.ServiceSelectors
 		*out = new(v1.LabelSelector)
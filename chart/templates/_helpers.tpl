@@ -13,7 +13,28 @@ Create chart name and version as used by the chart label.
 {{- end }}
 
 {{- define "chart.serviceName" -}}
-{{- printf "federation-discovery-service-%s" (default .Release.Name .Values.federation.meshPeers.local.name) }}
+{{- $default := printf "federation-discovery-service-%s" (default .Release.Name .Values.federation.meshPeers.local.name) }}
+{{- dig "discoveryService" "name" $default .Values.federation.meshPeers.local }}
+{{- end }}
+
+{{- define "chart.discoveryPort" -}}
+{{- dig "discoveryService" "port" 15080 .Values.federation.meshPeers.local }}
+{{- end }}
+
+{{/*
+Mesh peers passed to the controller, with the name of the local peer defaulted to the release name
+*/}}
+{{- define "federation.meshPeers" -}}
+{{- $meshPeers := deepCopy .Values.federation.meshPeers }}
+{{- $_ := set $meshPeers.local "name" (default .Release.Name $meshPeers.local.name) }}
+{{- $meshPeers | toJson }}
+{{- end }}
+
+{{/*
+Name of cluster-scoped resources, which must be unique for each release installed in the cluster
+*/}}
+{{- define "chart.clusterScopedName" -}}
+{{- printf "%s-%s" (include "chart.name" .) .Release.Namespace | trunc 63 | trimSuffix "-" }}
 {{- end }}
 
 {{/*
@@ -37,15 +58,48 @@ app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
 
 {{/*
-Checks if any of the remotes have ingress type of openshift-router
+Checks if any of the remotes imports its trust bundle
 */}}
-{{- define "remotes.hasOpenshiftRouterPeer" -}}
+{{- define "remotes.importTrustBundle" -}}
 {{- $remotes := .Values.federation.meshPeers.remotes | default list -}}
-{{- $found := false -}}
 {{- range $remotes }}
-  {{- if eq .ingressType "openshift-router" }}
-    {{- $found = true -}}
-  {{- end }}
+  {{- if .importTrustBundle }}true{{- end }}
+{{- end }}
+{{- end -}}
+
+{{/*
+Checks if the local mesh runs in ambient mode
+*/}}
+{{- define "local.isAmbient" -}}
+{{- if eq (dig "dataplaneMode" "sidecar" .Values.federation.meshPeers.local) "ambient" }}true{{- end }}
+{{- end -}}
+
+{{/*
+Checks if exported services are selected by MCS ServiceExports
+*/}}
+{{- define "exportedServiceSet.hasServiceExportRule" -}}
+{{- $rules := dig "exportedServiceSet" "rules" list .Values.federation -}}
+{{- range $rules }}
+  {{- if eq .type "ServiceExport" }}true{{- end }}
+{{- end }}
+{{- end -}}
+
+{{/*
+Checks if any export rule is restricted to namespaces matching a selector
+*/}}
+{{- define "exportedServiceSet.hasNamespaceSelector" -}}
+{{- $rules := dig "exportedServiceSet" "rules" list .Values.federation -}}
+{{- range $rules }}
+  {{- if .namespaceSelector }}true{{- end }}
+{{- end }}
+{{- end -}}
+
+{{/*
+Checks if imported services should be represented as MCS ServiceImports
+*/}}
+{{- define "importedServiceSet.hasServiceImportRule" -}}
+{{- $rules := dig "importedServiceSet" "rules" list .Values.federation -}}
+{{- range $rules }}
+  {{- if eq .type "ServiceImport" }}true{{- end }}
 {{- end }}
-{{- $found -}}
 {{- end -}}
@@ -18,31 +18,52 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
-	"sort"
+	"slices"
+	"strconv"
 	"syscall"
 	"time"
 
 	routev1client "github.com/openshift/client-go/route/clientset/versioned"
+	"google.golang.org/grpc/backoff"
 	istiokube "istio.io/istio/pkg/kube"
 	istiolog "istio.io/istio/pkg/log"
-	"istio.io/istio/pkg/slices"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	v1 "k8s.io/client-go/listers/core/v1"
+	discoveryv1listers "k8s.io/client-go/listers/discovery/v1"
 	// +kubebuilder:scaffold:imports
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	mcsclientset "sigs.k8s.io/mcs-api/pkg/client/clientset/versioned"
+	mcsinformers "sigs.k8s.io/mcs-api/pkg/client/informers/externalversions"
+	mcslisters "sigs.k8s.io/mcs-api/pkg/client/listers/apis/v1alpha1"
 
 	"github.com/openshift-service-mesh/federation/internal/controller"
 	"github.com/openshift-service-mesh/federation/internal/controller/federatedservice"
 	"github.com/openshift-service-mesh/federation/internal/controller/meshfederation"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
+	"github.com/openshift-service-mesh/federation/internal/pkg/exportstatus"
+	"github.com/openshift-service-mesh/federation/internal/pkg/gatewayapi"
 	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/fds"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/informer"
@@ -50,6 +71,7 @@ import (
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds/adsc"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds/adss"
+	"github.com/openshift-service-mesh/federation/internal/pkg/mcs"
 	"github.com/openshift-service-mesh/federation/internal/pkg/networking"
 	"github.com/openshift-service-mesh/federation/internal/pkg/openshift"
 
@@ -67,8 +89,18 @@ var (
 	probeAddr string
 
 	enableLeaderElection,
+	dryRun,
 	useCtrls bool
 
+	maxConcurrentApplies int
+
+	resyncPeriod,
+	gatewayProbeInterval time.Duration
+
+	// reconnectBackoff limits delays between reconnects to discovery servers of remote peers,
+	// so that peers recover within half a minute after an outage of the remote controller.
+	reconnectBackoff = backoff.Config{BaseDelay: time.Second, Multiplier: 1.6, Jitter: 0.2, MaxDelay: 30 * time.Second}
+
 	loggingOptions = istiolog.DefaultOptions()
 	log            = istiolog.RegisterScope("default", "default logging scope")
 
@@ -79,7 +111,12 @@ func init() {
 	controller.MustAddToScheme(scheme)
 }
 
-const reconnectDelay = time.Second * 5
+const (
+	// importSyncTimeout limits how long a new leader waits for services from remotes before reconciling.
+	importSyncTimeout = time.Second * 30
+
+	legacyLeaderElectionID = "federation-controller-leader"
+)
 
 // parseFlags parses command-line flags using the standard flag package.
 func parseFlags() {
@@ -93,7 +130,22 @@ func parseFlags() {
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
-			"Enabling this will ensure there is only one active controller manager.")
+			"Enabling this will ensure there is only one active controller manager. "+
+			"In legacy mode, all replicas serve FDS, but only the leader applies the mesh configuration.")
+
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute,
+		"Period of reconciling all generated resources in legacy mode, regardless of received changes. "+
+			"Set to 0 to disable periodic resync.")
+
+	flag.DurationVar(&gatewayProbeInterval, "gateway-probe-interval", 0,
+		"Period of probing addresses of remote ingress gateways with TLS handshakes in legacy mode. Unreachable addresses "+
+			"are removed from endpoints of imported services. Set to 0 to disable probing.")
+
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Log changes of the mesh configuration in legacy mode as diffs against live resources without persisting them. "+
+			"A dry-run instance does not take part in leader election, so it can run next to the active controller.")
+	flag.IntVar(&maxConcurrentApplies, "max-concurrent-applies", 1,
+		"Maximum number of parallel apply and delete requests sent by each reconciler in legacy mode.")
 
 	flag.BoolVar(&useCtrls, "use-ctrls", false,
 		"feature-flag: enables controller-runtime reconcilers instead of legacy mode.")
@@ -126,6 +178,8 @@ func main() {
 
 	if useCtrls {
 		runCtrls(ctx, cancel)
+	} else {
+		startMetricsServer(ctx)
 	}
 
 	runLegacyMode(ctx, cfg)
@@ -192,33 +246,157 @@ func runLegacyMode(ctx context.Context, cfg *config.Federation) {
 	fdsPushRequests := make(chan xds.PushRequest)
 	meshConfigPushRequests := make(chan xds.PushRequest)
 
+	// A dry-run instance only observes the mesh configuration, so it does not record events.
+	var eventRecorder record.EventRecorder
+	var recorder *events.Recorder
+	if !dryRun {
+		eventRecorder = newEventRecorder(ctx, istioClient.Kube())
+		recorder = events.NewRecorder(eventRecorder, events.PodReference(cfg.Namespace(), podName()))
+	}
+
 	informerFactory := informers.NewSharedInformerFactory(istioClient.Kube(), 0)
 	serviceInformer := informerFactory.Core().V1().Services().Informer()
 	serviceLister := informerFactory.Core().V1().Services().Lister()
+	endpointSliceInformer := informerFactory.Discovery().V1().EndpointSlices().Informer()
+	endpointSliceLister := informerFactory.Discovery().V1().EndpointSlices().Lister()
+	var namespaceInformer cache.SharedIndexInformer
+	var namespaceLister v1.NamespaceLister
+	if cfg.ExportedServiceSet.UseNamespaceSelectors() {
+		namespaceInformer = informerFactory.Core().V1().Namespaces().Informer()
+		namespaceLister = informerFactory.Core().V1().Namespaces().Lister()
+	}
 	informerFactory.Start(ctx.Done())
 
+	var mcsClient mcsclientset.Interface
+	var serviceExportLister mcslisters.ServiceExportLister
+	if cfg.ExportedServiceSet.UseServiceExports() || cfg.ImportedServiceSet.UseServiceImports() {
+		mcsClient, err = mcsclientset.NewForConfig(kubeConfig)
+		if err != nil {
+			log.Fatalf("failed to create MCS client: %v", err)
+		}
+	}
+	if cfg.ExportedServiceSet.UseServiceExports() {
+		mcsInformerFactory := mcsinformers.NewSharedInformerFactory(mcsClient, 0)
+		serviceExportInformer := mcsInformerFactory.Multicluster().V1alpha1().ServiceExports().Informer()
+		serviceExportLister = mcsInformerFactory.Multicluster().V1alpha1().ServiceExports().Lister()
+		mcsInformerFactory.Start(ctx.Done())
+
+		serviceExportController, err := informer.NewResourceController(serviceExportInformer, mcsv1alpha1.ServiceExport{},
+			informer.NewMCSServiceExportEventHandler(fdsPushRequests, meshConfigPushRequests, serviceLister, recorder))
+		if err != nil {
+			log.Fatalf("failed to create service export informer: %v", err)
+		}
+		serviceExportController.RunAndWait(ctx.Done())
+	}
+	exportedServiceLister := common.NewExportedServiceLister(*cfg, serviceLister, serviceExportLister, namespaceLister)
+
+	if namespaceInformer != nil {
+		namespaceController, err := informer.NewResourceController(namespaceInformer, corev1.Namespace{},
+			informer.NewNamespaceEventHandler(exportedServiceLister, fdsPushRequests, meshConfigPushRequests))
+		if err != nil {
+			log.Fatalf("failed to create namespace informer: %v", err)
+		}
+		namespaceController.RunAndWait(ctx.Done())
+	}
+
 	serviceController, err := informer.NewResourceController(serviceInformer, corev1.Service{},
-		informer.NewServiceExportEventHandler(*cfg, fdsPushRequests, meshConfigPushRequests))
+		informer.NewServiceExportEventHandler(exportedServiceLister, fdsPushRequests, meshConfigPushRequests, recorder))
 	if err != nil {
 		log.Fatalf("failed to create service informer: %v", err)
 	}
 	serviceController.RunAndWait(ctx.Done())
 
-	startFederationServer(ctx, cfg, serviceLister, fdsPushRequests)
+	endpointSliceController, err := informer.NewResourceController(endpointSliceInformer, discoveryv1.EndpointSlice{},
+		informer.NewEndpointSliceEventHandler(serviceLister, exportedServiceLister, fdsPushRequests, meshConfigPushRequests))
+	if err != nil {
+		log.Fatalf("failed to create endpoint slice informer: %v", err)
+	}
+	endpointSliceController.RunAndWait(ctx.Done())
 
-	if cfg.MeshPeers.Local.IngressType == config.OpenShiftRouter {
-		go resolveRemoteIP(ctx, cfg.MeshPeers.Remotes, meshConfigPushRequests)
+	trustBundleGenerator := startTrustBundleInformer(ctx, cfg, istioClient, fdsPushRequests)
+
+	startFederationServer(ctx, cfg, exportedServiceLister, endpointSliceLister, trustBundleGenerator, fdsPushRequests)
+
+	addressCache := startAddressCache(ctx, cfg, meshConfigPushRequests)
+	var gatewayProber *networking.GatewayProber
+	if gatewayProbeInterval > 0 {
+		gatewayProber = startGatewayProber(ctx, cfg, addressCache, meshConfigPushRequests, recorder)
 	}
 
 	importedServiceStore := fds.NewImportedServiceStore()
+	trustBundleStore := fds.NewTrustBundleStore()
 	for _, remote := range cfg.MeshPeers.Remotes {
-		startFDSClient(ctx, remote, meshConfigPushRequests, importedServiceStore)
+		startFDSClient(ctx, remote, serviceLister, meshConfigPushRequests, importedServiceStore, trustBundleStore, recorder)
 	}
 
-	startReconciler(ctx, cfg, serviceLister, meshConfigPushRequests, importedServiceStore)
+	startReconciler(ctx, cfg, serviceLister, exportedServiceLister, endpointSliceLister, mcsClient, serviceExportLister,
+		meshConfigPushRequests, importedServiceStore, trustBundleStore, addressCache, gatewayProber, eventRecorder, recorder)
 }
 
-func startReconciler(ctx context.Context, cfg *config.Federation, serviceLister v1.ServiceLister, meshConfigPushRequests chan xds.PushRequest, importedServiceStore *fds.ImportedServiceStore) {
+// startTrustBundleInformer watches only the ConfigMap or Secret containing root certificates of the local mesh
+// and returns a generator serving them to remote peers.
+func startTrustBundleInformer(
+	ctx context.Context,
+	cfg *config.Federation,
+	istioClient istiokube.Client,
+	fdsPushRequests chan xds.PushRequest,
+) *fds.TrustBundleGenerator {
+	name := fds.RootCertConfigMapName
+	namespace := cfg.Namespace()
+	if caCerts := cfg.MeshPeers.Local.CACertificates; caCerts != nil {
+		name = caCerts.SecretName
+		namespace = cfg.MeshPeers.Local.ControlPlane.Namespace
+	}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(istioClient.Kube(), 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+
+	// Only the informer of the configured source is created, because the controller is not allowed to watch the other one
+	var rootCertInformer cache.SharedIndexInformer
+	var resourceType any
+	var configMapLister v1.ConfigMapLister
+	var secretLister v1.SecretLister
+	if cfg.MeshPeers.Local.CACertificates != nil {
+		rootCertInformer = informerFactory.Core().V1().Secrets().Informer()
+		resourceType = corev1.Secret{}
+		secretLister = informerFactory.Core().V1().Secrets().Lister()
+	} else {
+		rootCertInformer = informerFactory.Core().V1().ConfigMaps().Informer()
+		resourceType = corev1.ConfigMap{}
+		configMapLister = informerFactory.Core().V1().ConfigMaps().Lister()
+	}
+	generator := fds.NewTrustBundleGenerator(*cfg, configMapLister, secretLister, cfg.Namespace())
+	informerFactory.Start(ctx.Done())
+
+	rootCertController, err := informer.NewResourceController(rootCertInformer, resourceType,
+		informer.NewTrustBundleEventHandler(fdsPushRequests))
+	if err != nil {
+		log.Fatalf("failed to create root certificate informer: %v", err)
+	}
+	rootCertController.RunAndWait(ctx.Done())
+
+	return generator
+}
+
+func startReconciler(
+	ctx context.Context,
+	cfg *config.Federation,
+	serviceLister v1.ServiceLister,
+	exportedServiceLister *common.ExportedServiceLister,
+	endpointSliceLister discoveryv1listers.EndpointSliceLister,
+	mcsClient mcsclientset.Interface,
+	serviceExportLister mcslisters.ServiceExportLister,
+	meshConfigPushRequests chan xds.PushRequest,
+	importedServiceStore *fds.ImportedServiceStore,
+	trustBundleStore *fds.TrustBundleStore,
+	addressCache *networking.AddressCache,
+	gatewayProber *networking.GatewayProber,
+	eventRecorder record.EventRecorder,
+	recorder *events.Recorder,
+) {
 
 	kubeConfig, err := rest.InClusterConfig()
 	if err != nil {
@@ -230,18 +408,44 @@ func startReconciler(ctx context.Context, cfg *config.Federation, serviceLister
 		log.Fatalf("failed to create Istio client: %v", err)
 	}
 
+	apiVersions, err := kube.DiscoverAPIVersions(istioClient.Kube().Discovery())
+	if err != nil {
+		log.Fatalf("failed to discover Istio API versions: %v", err)
+	}
+	log.Infof("Applying Istio resources using %s and %s APIs", apiVersions.Networking, apiVersions.Security)
+
 	namespace := cfg.Namespace()
+	reconcileOpts := kube.ReconcileOptions{
+		DryRun:         dryRun,
+		MaxConcurrency: maxConcurrentApplies,
+		Peer:           cfg.MeshPeers.Local.Name,
+		// Resources labeled by previous versions of the controller are adopted only in namespaces of this federation,
+		// as other federations in the cluster may not be upgraded yet.
+		LegacyNamespaces: []string{cfg.MeshPeers.Local.ControlPlane.Namespace, namespace},
+		DeletedResources: kube.NewDeletedResources(),
+	}
 
-	istioConfigFactory := istio.NewConfigFactory(*cfg, serviceLister, importedServiceStore, namespace)
+	istioConfigFactory := istio.NewConfigFactory(*cfg, serviceLister, exportedServiceLister, endpointSliceLister, importedServiceStore, addressCache, gatewayProber, namespace)
 	reconcilers := []kube.Reconciler{
-		kube.NewGatewayResourceReconciler(istioClient, istioConfigFactory),
-		kube.NewServiceEntryReconciler(istioClient, istioConfigFactory),
-		kube.NewWorkloadEntryReconciler(istioClient, istioConfigFactory),
-		kube.NewPeerAuthResourceReconciler(istioClient, namespace),
+		kube.NewServiceEntryReconciler(istioClient, istioConfigFactory, apiVersions, reconcileOpts),
+		kube.NewWorkloadEntryReconciler(istioClient, istioConfigFactory, apiVersions, reconcileOpts),
+		kube.NewPeerAuthResourceReconciler(istioClient, namespace, apiVersions, reconcileOpts),
+		kube.NewAuthorizationPolicyReconciler(istioClient, istioConfigFactory, apiVersions, reconcileOpts),
+		kube.NewDestinationRuleReconciler(istioClient, istioConfigFactory, apiVersions, reconcileOpts),
+		kube.NewServiceAnnotationReconciler(istioClient.Kube(), serviceLister,
+			exportstatus.NewConfigFactory(*cfg, serviceLister, exportedServiceLister), reconcileOpts),
 	}
 
-	if cfg.MeshPeers.AnyRemotePeerWithOpenshiftRouterIngress() {
-		reconcilers = append(reconcilers, kube.NewDestinationRuleReconciler(istioClient, istioConfigFactory))
+	gatewayAPIConfigFactory := gatewayapi.NewConfigFactory(*cfg, exportedServiceLister, namespace)
+	switch {
+	case cfg.MeshPeers.Local.IsAmbient():
+		// Exported services are reachable through the east-west gateway, which routes HBONE tunnels without any routes.
+		reconcilers = append(reconcilers, kube.NewKubernetesGatewayReconciler(istioClient, gatewayAPIConfigFactory, reconcileOpts))
+	case cfg.MeshPeers.Local.IngressType == config.GatewayAPI:
+		reconcilers = append(reconcilers, kube.NewKubernetesGatewayReconciler(istioClient, gatewayAPIConfigFactory, reconcileOpts))
+		reconcilers = append(reconcilers, kube.NewTLSRouteReconciler(istioClient, gatewayAPIConfigFactory, reconcileOpts))
+	default:
+		reconcilers = append(reconcilers, kube.NewGatewayResourceReconciler(istioClient, istioConfigFactory, apiVersions, reconcileOpts))
 	}
 
 	if cfg.MeshPeers.Local.IngressType == config.OpenShiftRouter {
@@ -250,22 +454,155 @@ func startReconciler(ctx context.Context, cfg *config.Federation, serviceLister
 			log.Fatalf("failed to create Route client: %v", err)
 		}
 
-		reconcilers = append(reconcilers, kube.NewEnvoyFilterReconciler(istioClient, istioConfigFactory))
-		reconcilers = append(reconcilers, kube.NewRouteReconciler(routeClient, openshift.NewConfigFactory(*cfg, serviceLister)))
+		reconcilers = append(reconcilers, kube.NewEnvoyFilterReconciler(istioClient, istioConfigFactory, reconcileOpts))
+		reconcilers = append(reconcilers, kube.NewRouteReconciler(routeClient, openshift.NewConfigFactory(*cfg, exportedServiceLister), reconcileOpts))
 	}
 
-	rm := kube.NewReconcilerManager(meshConfigPushRequests, reconcilers...)
-	if err := rm.ReconcileAll(ctx); err != nil {
-		log.Fatalf("initial Istio resource reconciliation failed: %v", err)
+	if cfg.MeshPeers.AnyRemotePeerImportingTrustBundle() {
+		reconcilers = append(reconcilers, kube.NewClusterTrustBundleReconciler(istioClient.Kube(), cfg.MeshPeers.Remotes, trustBundleStore, reconcileOpts))
 	}
 
+	mcsConfigFactory := mcs.NewConfigFactory(*cfg, serviceLister, serviceExportLister, importedServiceStore, trustBundleStore, addressCache)
+	if cfg.ExportedServiceSet.UseServiceExports() {
+		reconcilers = append(reconcilers, kube.NewServiceExportStatusReconciler(mcsClient, mcsConfigFactory, reconcileOpts))
+	}
+	if cfg.ImportedServiceSet.UseServiceImports() {
+		reconcilers = append(reconcilers, kube.NewServiceImportReconciler(mcsClient, mcsConfigFactory, reconcileOpts))
+	}
+
+	rm := kube.NewReconcilerManager(meshConfigPushRequests, resyncPeriod, recorder, reconcilers...)
 	go rm.Start(ctx)
+
+	if dryRun {
+		log.Infof("Running in dry-run mode, changes of the mesh configuration will not be persisted")
+		if err := rm.Lead(ctx); err != nil {
+			log.Errorf("dry-run reconciliation failed: %v", err)
+		}
+		return
+	}
+
+	driftDetector := kube.NewDriftDetector(istioClient.Dynamic(), apiVersions, reconcilers, meshConfigPushRequests,
+		eventRecorder, rm.IsLeading, reconcileOpts)
+	if err := driftDetector.Start(ctx); err != nil {
+		log.Fatalf("failed to start informers of generated resources: %v", err)
+	}
+
+	lead := func(ctx context.Context) {
+		if err := rm.Lead(ctx); err != nil {
+			log.Fatalf("initial Istio resource reconciliation failed: %v", err)
+		}
+	}
+	if !enableLeaderElection {
+		lead(ctx)
+		return
+	}
+	go runLeaderElection(ctx, istioClient.Kube(), cfg.Namespace(), func(ctx context.Context) {
+		// All replicas import services from remotes, so the new leader usually has the complete state
+		// and does not prune imported services that have not been received yet.
+		waitForImportedServices(ctx, cfg.MeshPeers.Remotes, importedServiceStore)
+		lead(ctx)
+	})
+}
+
+func newEventRecorder(ctx context.Context, client kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme, corev1.EventSource{Component: "federation-controller"})
+}
+
+// startMetricsServer serves metrics in legacy mode. Controller-runtime reconcilers serve them from the manager.
+func startMetricsServer(ctx context.Context) {
+	metricsServer, err := server.NewServer(server.Options{BindAddress: metricsAddr}, nil, nil)
+	if err != nil {
+		log.Fatalf("failed to create metrics server: %v", err)
+	}
+	if metricsServer == nil {
+		return
+	}
+	go func() {
+		if err := metricsServer.Start(ctx); err != nil {
+			log.Fatalf("failed to start metrics server: %v", err)
+		}
+	}()
+}
+
+// runLeaderElection blocks until the context is done. All replicas serve FDS and import services from remotes,
+// but only the leader applies the configuration. The process exits when the leadership is lost.
+func runLeaderElection(ctx context.Context, client kubernetes.Interface, namespace string, onStartedLeading func(context.Context)) {
+	identity := podName()
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      legacyLeaderElectionID,
+				Namespace: namespace,
+			},
+			Client: client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: identity,
+			},
+		},
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("Started leading as %s", identity)
+				onStartedLeading(ctx)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Fatalf("leader election lost by %s", identity)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.Infof("Current leader: %s", leader)
+				}
+			},
+		},
+	})
+}
+
+// podName returns the name of the controller Pod, which identifies this replica in leader election and events.
+func podName() string {
+	name := os.Getenv("POD_NAME")
+	if name == "" {
+		var err error
+		if name, err = os.Hostname(); err != nil {
+			log.Fatalf("failed to determine pod name: %v", err)
+		}
+	}
+	return name
+}
+
+func waitForImportedServices(ctx context.Context, remotes []config.Remote, importedServiceStore *fds.ImportedServiceStore) {
+	ctx, cancel := context.WithTimeout(ctx, importSyncTimeout)
+	defer cancel()
+
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(context.Context) (bool, error) {
+		return importedServiceStore.HasSynced(remotes), nil
+	})
+	if err != nil {
+		log.Warnf("services were not received from all remotes within %s, services of unavailable remotes will not be imported", importSyncTimeout)
+	}
 }
 
-func startFederationServer(ctx context.Context, cfg *config.Federation, serviceLister v1.ServiceLister, fdsPushRequests chan xds.PushRequest) {
+func startFederationServer(
+	ctx context.Context,
+	cfg *config.Federation,
+	exportedServiceLister *common.ExportedServiceLister,
+	endpointSliceLister discoveryv1listers.EndpointSliceLister,
+	trustBundleGenerator *fds.TrustBundleGenerator,
+	fdsPushRequests chan xds.PushRequest,
+) {
 	federationServer := adss.NewServer(
+		fmt.Sprintf(":%d", cfg.DiscoveryServicePort()),
 		fdsPushRequests,
-		fds.NewExportedServicesGenerator(*cfg, serviceLister),
+		fds.NewExportedServicesGenerator(exportedServiceLister, endpointSliceLister),
+		trustBundleGenerator,
 	)
 
 	go func() {
@@ -275,74 +612,127 @@ func startFederationServer(ctx context.Context, cfg *config.Federation, serviceL
 	}()
 }
 
-func resolveRemoteIP(ctx context.Context, remotes []config.Remote, meshConfigPushRequests chan xds.PushRequest) {
-	var prevIPs []string
-	for _, remote := range remotes {
-		prevIPs = append(prevIPs, networking.Resolve(remote.Addresses[0])...)
+// startAddressCache resolves hostnames of all remote addresses and keeps refreshing them in the background.
+// Resources that contain resolved addresses are reconciled whenever resolved addresses change.
+func startAddressCache(ctx context.Context, cfg *config.Federation, meshConfigPushRequests chan xds.PushRequest) *networking.AddressCache {
+	var addrs []string
+	for _, remote := range cfg.MeshPeers.Remotes {
+		addrs = append(addrs, remote.Addresses...)
 	}
-
-	resolveIPs := func() {
-		var currIPs []string
-		for _, remote := range remotes {
-			log.Debugf("Resolving %s", remote.Name)
-			currIPs = append(currIPs, networking.Resolve(remote.Addresses[0])...)
-		}
-
-		sort.Strings(currIPs)
-		if !slices.Equal(prevIPs, currIPs) {
-			log.Infof("IP addresses have changed")
-			prevIPs = currIPs
-			meshConfigPushRequests <- xds.PushRequest{TypeUrl: xds.WorkloadEntryTypeUrl}
-		}
+	resolver, err := networking.NewDNSResolver()
+	if err != nil {
+		log.Fatalf("failed to create DNS resolver: %v", err)
 	}
+	addressCache := networking.NewAddressCache(resolver, addrs, func() {
+		log.Infof("Resolved addresses of remotes have changed")
+		meshConfigPushRequests <- xds.PushRequest{TypeUrl: xds.WorkloadEntryTypeUrl}
+		// ServiceEntries contain resolved addresses in ambient mode and when IP families of the remote are configured
+		meshConfigPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+		meshConfigPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceImportTypeUrl}
+	})
+	addressCache.Start(ctx)
+	return addressCache
+}
 
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-resolveLoop:
-	for {
-		select {
-		case <-ctx.Done():
-			break resolveLoop
-		case <-ticker.C:
-			resolveIPs()
+// startGatewayProber probes addresses of remote ingress gateways, including hostnames and their resolved addresses,
+// because endpoints of imported services contain either of them. Health changes are recorded as events,
+// and endpoints are reconciled to exclude unreachable addresses.
+func startGatewayProber(
+	ctx context.Context,
+	cfg *config.Federation,
+	addressCache *networking.AddressCache,
+	meshConfigPushRequests chan xds.PushRequest,
+	recorder *events.Recorder,
+) *networking.GatewayProber {
+	targets := func() []networking.ProbeTarget {
+		var targets []networking.ProbeTarget
+		for _, remote := range cfg.MeshPeers.Remotes {
+			seen := make(map[string]bool)
+			for _, addr := range append(slices.Clone(remote.Addresses), addressCache.Resolve(remote.Addresses...)...) {
+				if seen[addr] {
+					continue
+				}
+				seen[addr] = true
+				targets = append(targets, networking.ProbeTarget{
+					Remote:  remote.Name,
+					Address: addr,
+					Port:    remote.GetPort(),
+					SNI:     discoverySNI(remote),
+				})
+			}
 		}
+		return targets
 	}
+	gatewayProber := networking.NewGatewayProber(targets, gatewayProbeInterval, func(results []networking.ProbeResult) {
+		for _, result := range results {
+			if result.Healthy {
+				recorder.Federation(corev1.EventTypeNormal, events.ReasonGatewayReachable,
+					"Gateway of remote peer %s is reachable at %s", result.Target.Remote, result.Target.Address)
+			} else {
+				recorder.Federation(corev1.EventTypeWarning, events.ReasonGatewayUnreachable,
+					"Gateway of remote peer %s is unreachable at %s: %v", result.Target.Remote, result.Target.Address, result.Err)
+			}
+		}
+		meshConfigPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+		meshConfigPushRequests <- xds.PushRequest{TypeUrl: xds.WorkloadEntryTypeUrl}
+	})
+	gatewayProber.Start(ctx)
+	return gatewayProber
+}
 
+// discoverySNI returns the SNI that proxies send to the remote ingress gateway to reach the remote discovery service.
+func discoverySNI(remote config.Remote) string {
+	if remote.RequiresRouterCompatibleSNI() {
+		return common.RouterCompatibleSNI(remote.ServiceName(), remote.ServiceNamespace(), remote.ServicePort())
+	}
+	return fmt.Sprintf("outbound_.%d_._.%s", remote.ServicePort(), remote.ServiceFQDN())
 }
 
-func startFDSClient(ctx context.Context, remote config.Remote, meshConfigPushRequests chan xds.PushRequest, importedServiceStore *fds.ImportedServiceStore) {
-	var discoveryAddr string
+// discoveryAddrs returns endpoints of the remote discovery server. When remote addresses are IPs, the discovery service
+// is reached by its hostname, which the local proxy routes to all addresses of the remote using the generated ServiceEntry.
+// Otherwise, every hostname of the remote is an endpoint, so that the client fails over between them.
+func discoveryAddrs(remote config.Remote) []string {
+	port := strconv.Itoa(int(remote.ServicePort()))
 	if networking.IsIP(remote.Addresses[0]) {
-		discoveryAddr = fmt.Sprintf("%s:%d", remote.ServiceFQDN(), remote.ServicePort())
-	} else {
-		discoveryAddr = fmt.Sprintf("%s:%d", remote.Addresses[0], remote.ServicePort())
+		return []string{net.JoinHostPort(remote.ServiceFQDN(), port)}
 	}
+	addrs := make([]string, 0, len(remote.Addresses))
+	for _, addr := range remote.Addresses {
+		addrs = append(addrs, net.JoinHostPort(addr, port))
+	}
+	return addrs
+}
 
+func startFDSClient(
+	ctx context.Context,
+	remote config.Remote,
+	serviceLister v1.ServiceLister,
+	meshConfigPushRequests chan xds.PushRequest,
+	importedServiceStore *fds.ImportedServiceStore,
+	trustBundleStore *fds.TrustBundleStore,
+	recorder *events.Recorder,
+) {
 	fdsClient, errClient := adsc.New(&adsc.ADSCConfig{
-		RemoteName:    remote.Name,
-		DiscoveryAddr: discoveryAddr,
-		Authority:     remote.ServiceFQDN(),
+		RemoteName:     remote.Name,
+		DiscoveryAddrs: discoveryAddrs(remote),
+		Authority:      remote.ServiceFQDN(),
+		Backoff:        &reconnectBackoff,
 		Handlers: map[string]adsc.ResponseHandler{
-			xds.ExportedServiceTypeUrl: fds.NewImportedServiceHandler(importedServiceStore, meshConfigPushRequests),
+			xds.ExportedServiceTypeUrl: fds.NewImportedServiceHandler(importedServiceStore, meshConfigPushRequests, serviceLister, recorder),
+			xds.TrustBundleTypeUrl:     fds.NewTrustBundleHandler(remote, trustBundleStore, meshConfigPushRequests, recorder),
+		},
+		OnConnected: func(endpoint string) {
+			recorder.Federation(corev1.EventTypeNormal, events.ReasonPeerConnected,
+				"Connected to the discovery server of remote peer %s at %s", remote.Name, endpoint)
+		},
+		OnDisconnected: func(err error) {
+			recorder.Federation(corev1.EventTypeWarning, events.ReasonPeerDisconnected,
+				"Disconnected from the discovery server of remote peer %s: %v", remote.Name, err)
 		},
-		ReconnectDelay: reconnectDelay,
 	})
 	if errClient != nil {
 		log.Fatalf("failed to create FDS client: %v", errClient)
 	}
 
-	go func() {
-		if errRun := fdsClient.Run(ctx); errRun != nil {
-			log.Errorf("failed to start FDS client, will reconnect in %s: %v", reconnectDelay, errRun)
-			time.AfterFunc(reconnectDelay, func() {
-				if errCtx := ctx.Err(); errCtx != nil {
-					log.Infof("Parent ctx is done: %v", errCtx)
-					return
-				}
-
-				fdsClient.Restart(ctx)
-			})
-		}
-	}()
+	go fdsClient.Run(ctx)
 }
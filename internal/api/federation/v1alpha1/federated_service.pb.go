@@ -35,14 +35,78 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// HealthStatus describes whether the exported service can handle traffic.
+type HealthStatus int32
+
+const (
+	// Health is not reported by the exporting mesh (e.g. it runs an older controller).
+	// Importers must treat such services as healthy.
+	HealthStatus_UNKNOWN HealthStatus = 0
+	// At least one endpoint of the service is ready.
+	HealthStatus_HEALTHY HealthStatus = 1
+	// None of the endpoints of the service is ready.
+	HealthStatus_UNHEALTHY HealthStatus = 2
+)
+
+// Enum value maps for HealthStatus.
+var (
+	HealthStatus_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "HEALTHY",
+		2: "UNHEALTHY",
+	}
+	HealthStatus_value = map[string]int32{
+		"UNKNOWN":   0,
+		"HEALTHY":   1,
+		"UNHEALTHY": 2,
+	}
+)
+
+func (x HealthStatus) Enum() *HealthStatus {
+	p := new(HealthStatus)
+	*p = x
+	return p
+}
+
+func (x HealthStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HealthStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_v1alpha1_federated_service_proto_enumTypes[0].Descriptor()
+}
+
+func (HealthStatus) Type() protoreflect.EnumType {
+	return &file_v1alpha1_federated_service_proto_enumTypes[0]
+}
+
+func (x HealthStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HealthStatus.Descriptor instead.
+func (HealthStatus) EnumDescriptor() ([]byte, []int) {
+	return file_v1alpha1_federated_service_proto_rawDescGZIP(), []int{0}
+}
+
 // FederatedService represents a service available across federated meshes.
 type FederatedService struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Ports         []*ServicePort         `protobuf:"bytes,2,rep,name=ports,proto3" json:"ports,omitempty"`
-	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Hostname string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ports    []*ServicePort         `protobuf:"bytes,2,rep,name=ports,proto3" json:"ports,omitempty"`
+	Labels   map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Number of ready endpoints backing the service in the exporting mesh.
+	ReadyEndpoints uint32 `protobuf:"varint,4,opt,name=readyEndpoints,proto3" json:"readyEndpoints,omitempty"`
+	// Health state of the service computed from its ready endpoints.
+	Health HealthStatus `protobuf:"varint,5,opt,name=health,proto3,enum=v1alpha1.HealthStatus" json:"health,omitempty"`
+	// Hostnames of individual instances of a headless service, e.g. kafka-0.kafka.ns.svc.cluster.local.
+	// Empty for services that are not headless or whose endpoints do not have hostnames.
+	InstanceHostnames []string `protobuf:"bytes,6,rep,name=instanceHostnames,proto3" json:"instanceHostnames,omitempty"`
+	// Names of service accounts of workloads backing the service, declared by the alpha.istio.io/kubernetes-serviceaccounts
+	// annotation. Importers combine them with the trust domain of the exporting mesh to verify identities of the workloads.
+	ServiceAccounts []string `protobuf:"bytes,7,rep,name=serviceAccounts,proto3" json:"serviceAccounts,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *FederatedService) Reset() {
@@ -96,6 +160,34 @@ func (x *FederatedService) GetLabels() map[string]string {
 	return nil
 }
 
+func (x *FederatedService) GetReadyEndpoints() uint32 {
+	if x != nil {
+		return x.ReadyEndpoints
+	}
+	return 0
+}
+
+func (x *FederatedService) GetHealth() HealthStatus {
+	if x != nil {
+		return x.Health
+	}
+	return HealthStatus_UNKNOWN
+}
+
+func (x *FederatedService) GetInstanceHostnames() []string {
+	if x != nil {
+		return x.InstanceHostnames
+	}
+	return nil
+}
+
+func (x *FederatedService) GetServiceAccounts() []string {
+	if x != nil {
+		return x.ServiceAccounts
+	}
+	return nil
+}
+
 type ServicePort struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Number        uint32                 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
@@ -164,12 +256,68 @@ func (x *ServicePort) GetTargetPort() uint32 {
 	return 0
 }
 
+// TrustBundle contains the trust domain and root certificates of a federated mesh, which allow peers to verify
+// identities of workloads in this mesh, even if they use different root CAs.
+type TrustBundle struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Trust domain of workload identities issued in the mesh, e.g. west.local.
+	TrustDomain string `protobuf:"bytes,1,opt,name=trustDomain,proto3" json:"trustDomain,omitempty"`
+	// PEM-encoded root certificates. Empty if root certificates are not available in the mesh.
+	RootCertificates string `protobuf:"bytes,2,opt,name=rootCertificates,proto3" json:"rootCertificates,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TrustBundle) Reset() {
+	*x = TrustBundle{}
+	mi := &file_v1alpha1_federated_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrustBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrustBundle) ProtoMessage() {}
+
+func (x *TrustBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_v1alpha1_federated_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrustBundle.ProtoReflect.Descriptor instead.
+func (*TrustBundle) Descriptor() ([]byte, []int) {
+	return file_v1alpha1_federated_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TrustBundle) GetTrustDomain() string {
+	if x != nil {
+		return x.TrustDomain
+	}
+	return ""
+}
+
+func (x *TrustBundle) GetRootCertificates() string {
+	if x != nil {
+		return x.RootCertificates
+	}
+	return ""
+}
+
 var File_v1alpha1_federated_service_proto protoreflect.FileDescriptor
 
 var file_v1alpha1_federated_service_proto_rawDesc = []byte{
 	0x0a, 0x20, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2f, 0x66, 0x65, 0x64, 0x65, 0x72,
 	0x61, 0x74, 0x65, 0x64, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x08, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x22, 0xd6, 0x01, 0x0a,
+	0x74, 0x6f, 0x12, 0x08, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x22, 0x86, 0x03, 0x0a,
 	0x10, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
 	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a,
@@ -179,7 +327,18 @@ var file_v1alpha1_federated_service_proto_rawDesc = []byte{
 	0x62, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x76, 0x31, 0x61,
 	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x53,
 	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61,
+	0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x72, 0x65,
+	0x61, 0x64, 0x79, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0e, 0x72, 0x65, 0x61, 0x64, 0x79, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x16, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x48, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x68, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x12, 0x2c, 0x0a, 0x11, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x6f,
+	0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x69,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x12, 0x28, 0x0a, 0x0f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61,
 	0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
 	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
@@ -190,9 +349,19 @@ var file_v1alpha1_federated_service_proto_rawDesc = []byte{
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
 	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a,
 	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x42, 0x15, 0x5a, 0x13,
-	0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70,
-	0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x22, 0x5b, 0x0a, 0x0b,
+	0x54, 0x72, 0x75, 0x73, 0x74, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x74,
+	0x72, 0x75, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x74, 0x72, 0x75, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x2a, 0x0a,
+	0x10, 0x72, 0x6f, 0x6f, 0x74, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72, 0x6f, 0x6f, 0x74, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x2a, 0x37, 0x0a, 0x0c, 0x48, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b,
+	0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x48, 0x45, 0x41, 0x4c, 0x54, 0x48,
+	0x59, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x55, 0x4e, 0x48, 0x45, 0x41, 0x4c, 0x54, 0x48, 0x59,
+	0x10, 0x02, 0x42, 0x15, 0x5a, 0x13, 0x66, 0x65, 0x64, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -207,20 +376,24 @@ func file_v1alpha1_federated_service_proto_rawDescGZIP() []byte {
 	return file_v1alpha1_federated_service_proto_rawDescData
 }
 
-var file_v1alpha1_federated_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_v1alpha1_federated_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_v1alpha1_federated_service_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_v1alpha1_federated_service_proto_goTypes = []any{
-	(*FederatedService)(nil), // 0: v1alpha1.FederatedService
-	(*ServicePort)(nil),      // 1: v1alpha1.ServicePort
-	nil,                      // 2: v1alpha1.FederatedService.LabelsEntry
+	(HealthStatus)(0),        // 0: v1alpha1.HealthStatus
+	(*FederatedService)(nil), // 1: v1alpha1.FederatedService
+	(*ServicePort)(nil),      // 2: v1alpha1.ServicePort
+	(*TrustBundle)(nil),      // 3: v1alpha1.TrustBundle
+	nil,                      // 4: v1alpha1.FederatedService.LabelsEntry
 }
 var file_v1alpha1_federated_service_proto_depIdxs = []int32{
-	1, // 0: v1alpha1.FederatedService.ports:type_name -> v1alpha1.ServicePort
-	2, // 1: v1alpha1.FederatedService.labels:type_name -> v1alpha1.FederatedService.LabelsEntry
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2, // 0: v1alpha1.FederatedService.ports:type_name -> v1alpha1.ServicePort
+	4, // 1: v1alpha1.FederatedService.labels:type_name -> v1alpha1.FederatedService.LabelsEntry
+	0, // 2: v1alpha1.FederatedService.health:type_name -> v1alpha1.HealthStatus
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_v1alpha1_federated_service_proto_init() }
@@ -233,13 +406,14 @@ func file_v1alpha1_federated_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_v1alpha1_federated_service_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      1,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_v1alpha1_federated_service_proto_goTypes,
 		DependencyIndexes: file_v1alpha1_federated_service_proto_depIdxs,
+		EnumInfos:         file_v1alpha1_federated_service_proto_enumTypes,
 		MessageInfos:      file_v1alpha1_federated_service_proto_msgTypes,
 	}.Build()
 	File_v1alpha1_federated_service_proto = out.File
@@ -60,3 +60,24 @@ func (in *ServicePort) DeepCopy() *ServicePort {
 func (in *ServicePort) DeepCopyInterface() interface{} {
 	return in.DeepCopy()
 }
+
+// DeepCopyInto supports using TrustBundle within kubernetes types, where deepcopy-gen is used.
+func (in *TrustBundle) DeepCopyInto(out *TrustBundle) {
+	p := proto.Clone(in).(*TrustBundle)
+	*out = *p
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustBundle. Required by controller-gen.
+func (in *TrustBundle) DeepCopy() *TrustBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInterface is an autogenerated deepcopy function, copying the receiver, creating a new TrustBundle. Required by controller-gen.
+func (in *TrustBundle) DeepCopyInterface() interface{} {
+	return in.DeepCopy()
+}
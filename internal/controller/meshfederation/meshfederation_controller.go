@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"slices"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	machinerymeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -29,15 +31,18 @@ import (
 
 	"github.com/openshift-service-mesh/federation/api/v1alpha1"
 	"github.com/openshift-service-mesh/federation/internal/controller"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
 )
 
 // +kubebuilder:rbac:groups=federation.openshift-service-mesh.io,resources=meshfederations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=federation.openshift-service-mesh.io,resources=meshfederations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=federation.openshift-service-mesh.io,resources=meshfederations/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconciler ensure that cluster is configured according to the spec defined in MeshFederation object.
 type Reconciler struct {
 	client.Client
+	recorder record.EventRecorder
 }
 
 var _ controller.Reconciler = (*Reconciler)(nil)
@@ -78,6 +83,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				machinerymeta.SetStatusCondition(&saved.Status.Conditions, condition)
 			}
 		})
+		if errStatusUpdate != nil && r.recorder != nil {
+			r.recorder.Eventf(meshFederation, corev1.EventTypeWarning, events.ReasonApplyFailed,
+				"Failed to update status: %v", errStatusUpdate)
+		}
 		return ctrl.Result{}, errStatusUpdate
 	}
 
@@ -86,6 +95,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("federation-controller")
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("mesh-federation-ctrl").
 		For(&v1alpha1.MeshFederation{}).
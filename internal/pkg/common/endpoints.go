@@ -0,0 +1,47 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// CountReadyEndpoints returns the number of unique ready endpoints in the given EndpointSlices.
+// Endpoints are deduplicated, because dual-stack services have separate EndpointSlices for each IP family.
+func CountReadyEndpoints(slices []*discoveryv1.EndpointSlice) uint32 {
+	ready := make(map[string]struct{})
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			// Nil ready condition should be interpreted as "unknown" state, which consumers should treat as ready.
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			ready[endpointKey(endpoint)] = struct{}{}
+		}
+	}
+	return uint32(len(ready))
+}
+
+func endpointKey(endpoint discoveryv1.Endpoint) string {
+	if endpoint.TargetRef != nil {
+		return fmt.Sprintf("%s/%s/%s", endpoint.TargetRef.Kind, endpoint.TargetRef.Namespace, endpoint.TargetRef.Name)
+	}
+	if len(endpoint.Addresses) > 0 {
+		return endpoint.Addresses[0]
+	}
+	return ""
+}
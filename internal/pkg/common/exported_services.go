@@ -0,0 +1,181 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "k8s.io/client-go/listers/core/v1"
+	mcslisters "sigs.k8s.io/mcs-api/pkg/client/listers/apis/v1alpha1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+// ExportedServiceLister finds local services matching export rules.
+// A service is exported if it matches any of the configured label selectors, or if ServiceExport rules are enabled
+// and a ServiceExport (multicluster.x-k8s.io) with the same name exists in the service namespace.
+// Rules with a namespace selector match only services in namespaces with matching labels.
+type ExportedServiceLister struct {
+	cfg                 config.Federation
+	serviceLister       v1.ServiceLister
+	serviceExportLister mcslisters.ServiceExportLister
+	namespaceLister     v1.NamespaceLister
+}
+
+// NewExportedServiceLister creates a lister of exported services.
+// serviceExportLister may be nil if ServiceExport rules are not enabled,
+// and namespaceLister may be nil if none of the export rules has a namespace selector.
+func NewExportedServiceLister(
+	cfg config.Federation,
+	serviceLister v1.ServiceLister,
+	serviceExportLister mcslisters.ServiceExportLister,
+	namespaceLister v1.NamespaceLister,
+) *ExportedServiceLister {
+	return &ExportedServiceLister{
+		cfg:                 cfg,
+		serviceLister:       serviceLister,
+		serviceExportLister: serviceExportLister,
+		namespaceLister:     namespaceLister,
+	}
+}
+
+// List returns exported services sorted by namespace and name.
+func (l *ExportedServiceLister) List() ([]*corev1.Service, error) {
+	unique := make(map[types.NamespacedName]*corev1.Service)
+	for _, rule := range l.rules(config.LabelSelectorRuleType) {
+		for _, exportLabelSelector := range rule.LabelSelectors {
+			matchLabels := labels.SelectorFromSet(exportLabelSelector.MatchLabels)
+			services, err := l.serviceLister.List(matchLabels)
+			if err != nil {
+				return nil, fmt.Errorf("error listing services (selector=%s): %w", matchLabels, err)
+			}
+			for _, svc := range services {
+				if l.namespaceMatches(rule, svc.Namespace) {
+					unique[types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}] = svc
+				}
+			}
+		}
+	}
+	if l.useServiceExports() {
+		serviceExports, err := l.serviceExportLister.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("error listing service exports: %w", err)
+		}
+		for _, se := range serviceExports {
+			if !l.serviceExportNamespaceMatches(se.Namespace) {
+				continue
+			}
+			svc, err := l.serviceLister.Services(se.Namespace).Get(se.Name)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get Service %s/%s: %w", se.Namespace, se.Name, err)
+			}
+			unique[types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}] = svc
+		}
+	}
+
+	services := make([]*corev1.Service, 0, len(unique))
+	for _, svc := range unique {
+		services = append(services, svc)
+	}
+	// ServiceLister.List is not idempotent, so services are sorted to let consumers generate configs in a stable order.
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// IsExported returns true if the given service matches export rules.
+func (l *ExportedServiceLister) IsExported(svc *corev1.Service) bool {
+	for _, rule := range l.rules(config.LabelSelectorRuleType) {
+		if MatchExportRules(svc, rule.LabelSelectors) && l.namespaceMatches(rule, svc.Namespace) {
+			return true
+		}
+	}
+	if l.useServiceExports() && l.serviceExportNamespaceMatches(svc.Namespace) {
+		_, err := l.serviceExportLister.ServiceExports(svc.Namespace).Get(svc.Name)
+		return err == nil
+	}
+	return false
+}
+
+// MatchesAnyNamespaceSelector returns true if the given namespace labels match a namespace selector of any export rule.
+func (l *ExportedServiceLister) MatchesAnyNamespaceSelector(namespaceLabels map[string]string) bool {
+	for _, rule := range l.cfg.ExportedServiceSet.Rules {
+		if rule.NamespaceSelector != nil && matchNamespaceSelector(rule.NamespaceSelector, namespaceLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *ExportedServiceLister) useServiceExports() bool {
+	return l.serviceExportLister != nil && l.cfg.ExportedServiceSet.UseServiceExports()
+}
+
+func (l *ExportedServiceLister) rules(ruleType string) []config.Rules {
+	var rules []config.Rules
+	for _, rule := range l.cfg.ExportedServiceSet.Rules {
+		if rule.Type == ruleType {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// serviceExportNamespaceMatches returns true if ServiceExports in the given namespace export services
+// according to any of the ServiceExport rules.
+func (l *ExportedServiceLister) serviceExportNamespaceMatches(namespace string) bool {
+	for _, rule := range l.rules(config.ServiceExportRuleType) {
+		if l.namespaceMatches(rule, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *ExportedServiceLister) namespaceMatches(rule config.Rules, namespace string) bool {
+	if rule.NamespaceSelector == nil {
+		return true
+	}
+	if l.namespaceLister == nil {
+		return false
+	}
+	ns, err := l.namespaceLister.Get(namespace)
+	if err != nil {
+		return false
+	}
+	return matchNamespaceSelector(rule.NamespaceSelector, ns.Labels)
+}
+
+// matchNamespaceSelector does not match any namespace if the selector is invalid.
+// Selectors are validated when parsing the configuration, so this should never happen.
+func matchNamespaceSelector(namespaceSelector *config.LabelSelectors, namespaceLabels map[string]string) bool {
+	selector, err := namespaceSelector.AsSelector()
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(namespaceLabels))
+}
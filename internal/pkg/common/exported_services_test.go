@@ -0,0 +1,183 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	mcsfake "sigs.k8s.io/mcs-api/pkg/client/clientset/versioned/fake"
+	mcsinformers "sigs.k8s.io/mcs-api/pkg/client/informers/externalversions"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+func TestExportedServiceLister(t *testing.T) {
+	labelSelectorRule := config.Rules{
+		Type: config.LabelSelectorRuleType,
+		LabelSelectors: []config.LabelSelectors{{
+			MatchLabels: map[string]string{"export": "true"},
+		}},
+	}
+	serviceExportRule := config.Rules{Type: config.ServiceExportRuleType}
+	approvedNamespaces := &config.LabelSelectors{MatchLabels: map[string]string{"federation": "approved"}}
+	labelSelectorRuleInApprovedNamespaces := labelSelectorRule
+	labelSelectorRuleInApprovedNamespaces.NamespaceSelector = approvedNamespaces
+	serviceExportRuleInApprovedNamespaces := serviceExportRule
+	serviceExportRuleInApprovedNamespaces.NamespaceSelector = approvedNamespaces
+
+	namespaces := []*corev1.Namespace{
+		namespace("ns1", map[string]string{"federation": "approved"}),
+		namespace("ns2", nil),
+	}
+
+	services := []*corev1.Service{
+		service("a", "ns1", map[string]string{"export": "true"}),
+		service("b", "ns1", nil),
+		service("c", "ns2", nil),
+		service("a", "ns2", map[string]string{"export": "true"}),
+	}
+	serviceExports := []*mcsv1alpha1.ServiceExport{
+		serviceExport("a", "ns1"),
+		serviceExport("b", "ns1"),
+		// ServiceExport without a Service must be ignored
+		serviceExport("d", "ns2"),
+	}
+
+	testCases := []struct {
+		name             string
+		rules            []config.Rules
+		expectedServices []string
+	}{{
+		name:             "label selectors",
+		rules:            []config.Rules{labelSelectorRule},
+		expectedServices: []string{"ns1/a", "ns2/a"},
+	}, {
+		name:             "service exports",
+		rules:            []config.Rules{serviceExportRule},
+		expectedServices: []string{"ns1/a", "ns1/b"},
+	}, {
+		name:             "label selectors and service exports",
+		rules:            []config.Rules{serviceExportRule, labelSelectorRule},
+		expectedServices: []string{"ns1/a", "ns1/b", "ns2/a"},
+	}, {
+		name:             "label selectors in namespaces matching namespace selector",
+		rules:            []config.Rules{labelSelectorRuleInApprovedNamespaces},
+		expectedServices: []string{"ns1/a"},
+	}, {
+		name:             "service exports in namespaces matching namespace selector",
+		rules:            []config.Rules{serviceExportRuleInApprovedNamespaces},
+		expectedServices: []string{"ns1/a", "ns1/b"},
+	}, {
+		name:             "label selectors in all namespaces and service exports in namespaces matching namespace selector",
+		rules:            []config.Rules{labelSelectorRule, serviceExportRuleInApprovedNamespaces},
+		expectedServices: []string{"ns1/a", "ns1/b", "ns2/a"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			serviceInformer := informerFactory.Core().V1().Services().Informer()
+			serviceLister := informerFactory.Core().V1().Services().Lister()
+			namespaceInformer := informerFactory.Core().V1().Namespaces().Informer()
+			namespaceLister := informerFactory.Core().V1().Namespaces().Lister()
+			mcsClient := mcsfake.NewSimpleClientset()
+			mcsInformerFactory := mcsinformers.NewSharedInformerFactory(mcsClient, 0)
+			serviceExportInformer := mcsInformerFactory.Multicluster().V1alpha1().ServiceExports().Informer()
+			serviceExportLister := mcsInformerFactory.Multicluster().V1alpha1().ServiceExports().Lister()
+			for _, svc := range services {
+				if _, err := client.CoreV1().Services(svc.Namespace).Create(context.Background(), svc, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create service %s/%s: %v", svc.Namespace, svc.Name, err)
+				}
+			}
+			for _, ns := range namespaces {
+				if _, err := client.CoreV1().Namespaces().Create(context.Background(), ns, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create namespace %s: %v", ns.Name, err)
+				}
+			}
+			for _, se := range serviceExports {
+				if _, err := mcsClient.MulticlusterV1alpha1().ServiceExports(se.Namespace).Create(context.Background(), se, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create service export %s/%s: %v", se.Namespace, se.Name, err)
+				}
+			}
+
+			// Informers are started after creating objects, so synced caches contain all of them.
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			mcsInformerFactory.Start(stopCh)
+			if !cache.WaitForCacheSync(stopCh, serviceInformer.HasSynced, serviceExportInformer.HasSynced, namespaceInformer.HasSynced) {
+				t.Fatal("failed to sync informers")
+			}
+
+			lister := NewExportedServiceLister(config.Federation{
+				ExportedServiceSet: config.ExportedServiceSet{Rules: tc.rules},
+			}, serviceLister, serviceExportLister, namespaceLister)
+
+			exported, err := lister.List()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var actual []string
+			for _, svc := range exported {
+				actual = append(actual, svc.Namespace+"/"+svc.Name)
+				if !lister.IsExported(svc) {
+					t.Errorf("expected service %s/%s to be exported", svc.Namespace, svc.Name)
+				}
+			}
+			if !reflect.DeepEqual(actual, tc.expectedServices) {
+				t.Errorf("expected exported services %v, got %v", tc.expectedServices, actual)
+			}
+			if lister.IsExported(service("c", "ns2", nil)) {
+				t.Errorf("expected service ns2/c not to be exported")
+			}
+		})
+	}
+}
+
+func service(name, namespace string, labels map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func serviceExport(name, namespace string) *mcsv1alpha1.ServiceExport {
+	return &mcsv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
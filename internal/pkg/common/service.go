@@ -0,0 +1,137 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/api/annotation"
+	istiokube "istio.io/istio/pkg/config/kube"
+	istioprotocol "istio.io/istio/pkg/config/protocol"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+)
+
+// CheckExportable returns an error describing why the service can't be exported.
+// ExternalName services are not exportable, because they are DNS aliases without endpoints
+// that could be exposed through the federation ingress gateway.
+func CheckExportable(svc *corev1.Service) error {
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return fmt.Errorf("service %s/%s is of type ExternalName, which is not supported for export", svc.Namespace, svc.Name)
+	}
+	return nil
+}
+
+func IsHeadless(svc *corev1.Service) bool {
+	return svc.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
+// InstanceHostnames returns sorted FQDNs of instances of a headless service, e.g. kafka-0.kafka.ns.svc.cluster.local.
+// Only endpoints with hostnames are taken into account, which in practice means pods managed by StatefulSets.
+func InstanceHostnames(svc *corev1.Service, slices []*discoveryv1.EndpointSlice) []string {
+	if !IsHeadless(svc) {
+		return nil
+	}
+	unique := make(map[string]struct{})
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Hostname == nil || *endpoint.Hostname == "" {
+				continue
+			}
+			unique[fmt.Sprintf("%s.%s.%s.svc.cluster.local", *endpoint.Hostname, svc.Name, svc.Namespace)] = struct{}{}
+		}
+	}
+	var hostnames []string
+	for hostname := range unique {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+	return hostnames
+}
+
+// DetectProtocol follows Istio's protocol selection rules: UDP ports are recognized by the K8s protocol,
+// then appProtocol takes precedence over the port name prefix, and unrecognized protocols default to TCP.
+func DetectProtocol(port corev1.ServicePort) istioprotocol.Instance {
+	protocol := istiokube.ConvertProtocol(port.Port, port.Name, port.Protocol, port.AppProtocol)
+	if protocol.IsUnsupported() {
+		return istioprotocol.TCP
+	}
+	return protocol
+}
+
+// RouterCompatibleSNI returns SNI compatible with https://datatracker.ietf.org/doc/html/rfc952 required by OpenShift Router
+// and Gateway API, which do not allow underscores used in SNIs generated by Istio.
+func RouterCompatibleSNI(svcName, svcNs string, port uint32) string {
+	return fmt.Sprintf("%s-%d.%s.svc.cluster.local", svcName, port, svcNs)
+}
+
+// ServiceAccounts returns sorted names of service accounts declared in the alpha.istio.io/kubernetes-serviceaccounts
+// annotation, which Istio uses to determine identities of workloads backing the service.
+func ServiceAccounts(svc *corev1.Service) []string {
+	value, found := svc.Annotations[annotation.AlphaKubernetesServiceAccounts.Name]
+	if !found {
+		return nil
+	}
+	var serviceAccounts []string
+	for _, sa := range strings.Split(value, ",") {
+		if sa = strings.TrimSpace(sa); sa != "" {
+			serviceAccounts = append(serviceAccounts, sa)
+		}
+	}
+	sort.Strings(serviceAccounts)
+	return serviceAccounts
+}
+
+// SpiffeIdentity returns the SPIFFE ID of workloads running with the given service account.
+func SpiffeIdentity(trustDomain, namespace, serviceAccount string) string {
+	return fmt.Sprintf("spiffe://%s/ns/%s/sa/%s", trustDomain, namespace, serviceAccount)
+}
+
+// PortsConflict returns true if ports of the local service differ from ports of the imported service
+// with the same name and namespace. UDP ports are ignored, because they are not exported.
+func PortsConflict(local *corev1.Service, imported *v1alpha1.FederatedService) bool {
+	return localPortSignature(local) != importedPortSignature(imported)
+}
+
+// ImportedPortsConflict returns true if two remotes export services with the same hostname, but different ports.
+func ImportedPortsConflict(a, b *v1alpha1.FederatedService) bool {
+	return importedPortSignature(a) != importedPortSignature(b)
+}
+
+func localPortSignature(svc *corev1.Service) string {
+	var ports []string
+	for _, port := range svc.Spec.Ports {
+		protocol := DetectProtocol(port)
+		if protocol == istioprotocol.UDP {
+			continue
+		}
+		ports = append(ports, fmt.Sprintf("%d/%s", port.Port, strings.ToUpper(string(protocol))))
+	}
+	sort.Strings(ports)
+	return strings.Join(ports, ",")
+}
+
+func importedPortSignature(svc *v1alpha1.FederatedService) string {
+	var ports []string
+	for _, port := range svc.Ports {
+		ports = append(ports, fmt.Sprintf("%d/%s", port.Number, port.Protocol))
+	}
+	sort.Strings(ports)
+	return strings.Join(ports, ",")
+}
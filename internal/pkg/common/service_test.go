@@ -0,0 +1,98 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	istioprotocol "istio.io/istio/pkg/config/protocol"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDetectProtocol(t *testing.T) {
+	testCases := []struct {
+		port             corev1.ServicePort
+		expectedProtocol istioprotocol.Instance
+	}{
+		// Port name prefixes
+		{port: corev1.ServicePort{Name: "http"}, expectedProtocol: istioprotocol.HTTP},
+		{port: corev1.ServicePort{Name: "http-web"}, expectedProtocol: istioprotocol.HTTP},
+		{port: corev1.ServicePort{Name: "https"}, expectedProtocol: istioprotocol.HTTPS},
+		{port: corev1.ServicePort{Name: "http2"}, expectedProtocol: istioprotocol.HTTP2},
+		{port: corev1.ServicePort{Name: "grpc"}, expectedProtocol: istioprotocol.GRPC},
+		{port: corev1.ServicePort{Name: "grpc-web"}, expectedProtocol: istioprotocol.GRPCWeb},
+		{port: corev1.ServicePort{Name: "grpc-web-api"}, expectedProtocol: istioprotocol.GRPCWeb},
+		{port: corev1.ServicePort{Name: "tcp"}, expectedProtocol: istioprotocol.TCP},
+		{port: corev1.ServicePort{Name: "tls"}, expectedProtocol: istioprotocol.TLS},
+		{port: corev1.ServicePort{Name: "mongo"}, expectedProtocol: istioprotocol.Mongo},
+		{port: corev1.ServicePort{Name: "redis-cache"}, expectedProtocol: istioprotocol.Redis},
+		{port: corev1.ServicePort{Name: "mysql"}, expectedProtocol: istioprotocol.MySQL},
+		{port: corev1.ServicePort{Name: "udp"}, expectedProtocol: istioprotocol.UDP},
+		{port: corev1.ServicePort{Name: "HTTP-upper"}, expectedProtocol: istioprotocol.HTTP},
+		// K8s protocol
+		{port: corev1.ServicePort{Name: "http", Protocol: corev1.ProtocolUDP}, expectedProtocol: istioprotocol.UDP},
+		// appProtocol takes precedence over port name
+		{port: corev1.ServicePort{Name: "tcp", AppProtocol: ptr.To("http")}, expectedProtocol: istioprotocol.HTTP},
+		{port: corev1.ServicePort{Name: "http", AppProtocol: ptr.To("https")}, expectedProtocol: istioprotocol.HTTPS},
+		{port: corev1.ServicePort{Name: "web", AppProtocol: ptr.To("grpc-web")}, expectedProtocol: istioprotocol.GRPCWeb},
+		{port: corev1.ServicePort{Name: "http", AppProtocol: ptr.To("kubernetes.io/h2c")}, expectedProtocol: istioprotocol.HTTP2},
+		{port: corev1.ServicePort{Name: "http", AppProtocol: ptr.To("mongo")}, expectedProtocol: istioprotocol.Mongo},
+		// Unsupported protocols default to TCP
+		{port: corev1.ServicePort{Name: "web"}, expectedProtocol: istioprotocol.TCP},
+		{port: corev1.ServicePort{Name: "http", AppProtocol: ptr.To("example.com/custom")}, expectedProtocol: istioprotocol.TCP},
+		{port: corev1.ServicePort{Port: 3306}, expectedProtocol: istioprotocol.TCP},
+	}
+	for _, tc := range testCases {
+		name := tc.port.Name
+		if tc.port.AppProtocol != nil {
+			name += "/" + *tc.port.AppProtocol
+		}
+		t.Run(name, func(t *testing.T) {
+			if protocol := DetectProtocol(tc.port); protocol != tc.expectedProtocol {
+				t.Errorf("expected protocol %s, got %s", tc.expectedProtocol, protocol)
+			}
+		})
+	}
+}
+
+func TestServiceAccounts(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		annotations             map[string]string
+		expectedServiceAccounts []string
+	}{{
+		name:                    "no annotation",
+		expectedServiceAccounts: nil,
+	}, {
+		name:                    "single service account",
+		annotations:             map[string]string{"alpha.istio.io/kubernetes-serviceaccounts": "reviews"},
+		expectedServiceAccounts: []string{"reviews"},
+	}, {
+		name:                    "multiple service accounts are trimmed and sorted",
+		annotations:             map[string]string{"alpha.istio.io/kubernetes-serviceaccounts": "reviews-v2, reviews-v1,,"},
+		expectedServiceAccounts: []string{"reviews-v1", "reviews-v2"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "bookinfo", Annotations: tc.annotations}}
+			if serviceAccounts := ServiceAccounts(svc); !reflect.DeepEqual(serviceAccounts, tc.expectedServiceAccounts) {
+				t.Errorf("expected service accounts %v, got %v", tc.expectedServiceAccounts, serviceAccounts)
+			}
+		})
+	}
+}
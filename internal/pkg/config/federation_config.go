@@ -14,10 +14,38 @@
 
 package config
 
-import "fmt"
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/networking"
+)
+
+// DiscoveryPort is the default port of the federation discovery service.
+const DiscoveryPort = 15080
 
 const (
-	defaultGatewayPort = 15443
+	// PeerLabel marks resources generated by a federation controller. Its value is the name of the local peer,
+	// so that multiple federations running in the same cluster do not reconcile each other's resources.
+	PeerLabel = "federation.openshift-service-mesh.io/peer"
+	// LegacyPeerLabelValue is the value of PeerLabel set by previous versions of the controller.
+	// Resources with this value are matched by every federation, so that they can be updated or removed after upgrade,
+	// but they are deleted only in namespaces of the federation, as they may belong to federations not upgraded yet.
+	LegacyPeerLabelValue = "todo"
+)
+
+const (
+	defaultGatewayPort        = 15443
+	defaultGatewayClassName   = "istio"
+	defaultIngressGatewayName = "federation-ingress-gateway"
+
+	defaultTrustDomain              = "cluster.local"
+	defaultControllerNamespace      = "istio-system"
+	discoveryServiceNamePrefix      = "federation-discovery-service-"
+	defaultControllerServiceAccount = "federation-controller"
+	defaultRootCertKey              = "root-cert.pem"
 )
 
 type Federation struct {
@@ -36,14 +64,49 @@ func (f *Federation) Namespace() string {
 	return f.namespace
 }
 
+// DiscoveryServiceName returns the name of the local discovery service.
+func (f *Federation) DiscoveryServiceName() string {
+	return f.MeshPeers.Local.DiscoveryService.nameOr(discoveryServiceNamePrefix + f.MeshPeers.Local.Name)
+}
+
+// DiscoveryServiceNamespace returns the namespace of the local discovery service advertised to remote peers.
+// It defaults to the namespace of the controller.
+func (f *Federation) DiscoveryServiceNamespace() string {
+	return f.MeshPeers.Local.DiscoveryService.namespaceOr(f.Namespace())
+}
+
+// DiscoveryServicePort returns the port of the local discovery service, which is also the port the controller listens on.
+func (f *Federation) DiscoveryServicePort() uint32 {
+	return f.MeshPeers.Local.DiscoveryService.GetPort()
+}
+
+// PeerLabels returns labels that must be set on all resources generated for this federation.
+func (f *Federation) PeerLabels() map[string]string {
+	return PeerLabels(f.MeshPeers.Local.Name)
+}
+
+// PeerLabels returns labels that must be set on all resources generated for the given local peer.
+func PeerLabels(localName string) map[string]string {
+	return map[string]string{PeerLabel: localName}
+}
+
+// PeerLabelSelector returns a label selector matching resources owned by the given local peer,
+// including resources labeled by previous versions of the controller.
+func PeerLabelSelector(localName string) string {
+	if localName == "" || localName == LegacyPeerLabelValue {
+		return fmt.Sprintf("%s=%s", PeerLabel, LegacyPeerLabelValue)
+	}
+	return fmt.Sprintf("%s in (%s,%s)", PeerLabel, localName, LegacyPeerLabelValue)
+}
+
 type MeshPeers struct {
 	Local   Local    `json:"local"`
 	Remotes []Remote `json:"remotes"`
 }
 
-func (m MeshPeers) AnyRemotePeerWithOpenshiftRouterIngress() bool {
+func (m MeshPeers) AnyRemotePeerImportingTrustBundle() bool {
 	for _, remote := range m.Remotes {
-		if remote.IngressType == OpenShiftRouter {
+		if remote.ImportTrustBundle {
 			return true
 		}
 	}
@@ -52,30 +115,124 @@ func (m MeshPeers) AnyRemotePeerWithOpenshiftRouterIngress() bool {
 }
 
 type Local struct {
-	Name         string       `json:"name"`
-	ControlPlane ControlPlane `json:"controlPlane"`
-	Gateways     Gateways     `json:"gateways"`
-	IngressType  IngressType  `json:"ingressType"`
+	Name          string        `json:"name"`
+	ControlPlane  ControlPlane  `json:"controlPlane"`
+	Gateways      Gateways      `json:"gateways"`
+	IngressType   IngressType   `json:"ingressType"`
+	DataplaneMode DataplaneMode `json:"dataplaneMode,omitempty"`
+	// Network is the name of the local network. It is required by Istio to recognize the east-west gateway
+	// created in ambient mode as a network gateway.
+	Network string `json:"network,omitempty"`
+	// TrustDomain of the local mesh.
+	TrustDomain string `json:"trustDomain,omitempty"`
+	// CACertificates configures the source of root certificates shared with remote peers.
+	CACertificates *CACertificates `json:"caCertificates,omitempty"`
+	// DiscoveryService configures the local discovery service exposed to remote peers.
+	DiscoveryService DiscoveryService `json:"discoveryService,omitempty"`
+}
+
+func (l *Local) GetTrustDomain() string {
+	if l.TrustDomain == "" {
+		return defaultTrustDomain
+	}
+	return l.TrustDomain
+}
+
+// IsAmbient returns true if the local mesh runs in ambient mode.
+func (l *Local) IsAmbient() bool {
+	return l.DataplaneMode == Ambient
 }
 
 type Remote struct {
-	Name        string      `json:"name"`
-	Addresses   []string    `json:"addresses"`
-	IngressType IngressType `json:"ingressType"`
-	Port        *uint32     `json:"port,omitempty"`
-	Network     string      `json:"network"`
+	Name          string        `json:"name"`
+	Addresses     []string      `json:"addresses"`
+	IngressType   IngressType   `json:"ingressType"`
+	Port          *uint32       `json:"port,omitempty"`
+	Network       string        `json:"network"`
+	DataplaneMode DataplaneMode `json:"dataplaneMode,omitempty"`
+	// TrustDomain of the remote mesh used to authorize requests from the remote federation controller.
+	TrustDomain string `json:"trustDomain,omitempty"`
+	// ServiceAccount of the remote federation controller.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// ImportTrustBundle enables writing root certificates received from the remote mesh into a ClusterTrustBundle.
+	ImportTrustBundle bool `json:"importTrustBundle,omitempty"`
+	// DiscoveryService identifies the discovery service of the remote peer.
+	DiscoveryService DiscoveryService `json:"discoveryService,omitempty"`
+	// IPFamilies restricts IP addresses of the remote ingress to the listed families, in the order of preference.
+	// When set, hostnames are resolved by the controller, so that only addresses of these families are used as endpoints.
+	// Defaults to IPv4 and IPv6, and hostnames are then resolved by proxies.
+	IPFamilies []IPFamily `json:"ipFamilies,omitempty"`
+}
+
+// IsAmbient returns true if the remote mesh runs in ambient mode and expects HBONE traffic on its east-west gateway.
+func (r *Remote) IsAmbient() bool {
+	return r.DataplaneMode == Ambient
+}
+
+// RequiresRouterCompatibleSNI returns true if the remote ingress requires SNI compatible with RFC 952,
+// which is the case for OpenShift Router and Gateway API implementations, as they route by hostname.
+func (r *Remote) RequiresRouterCompatibleSNI() bool {
+	return r.IngressType == OpenShiftRouter || r.IngressType == GatewayAPI
+}
+
+func (r *Remote) GetTrustDomain() string {
+	if r.TrustDomain == "" {
+		return defaultTrustDomain
+	}
+	return r.TrustDomain
+}
+
+// Principal returns the identity of the remote federation controller in the format expected by AuthorizationPolicy.
+func (r *Remote) Principal() string {
+	serviceAccount := r.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = defaultControllerServiceAccount
+	}
+	return fmt.Sprintf("%s/ns/%s/sa/%s", r.GetTrustDomain(), r.ServiceNamespace(), serviceAccount)
 }
 
 func (r *Remote) ServiceName() string {
-	return fmt.Sprintf("federation-discovery-service-%s", r.Name)
+	return r.DiscoveryService.nameOr(discoveryServiceNamePrefix + r.Name)
+}
+
+// ServiceNamespace returns the namespace of the remote discovery service, which is also the namespace
+// of the remote federation controller. It defaults to istio-system.
+func (r *Remote) ServiceNamespace() string {
+	return r.DiscoveryService.namespaceOr(defaultControllerNamespace)
 }
 
 func (r *Remote) ServiceFQDN() string {
-	return fmt.Sprintf("%s.istio-system.svc.cluster.local", r.ServiceName())
+	return fmt.Sprintf("%s.%s.svc.cluster.local", r.ServiceName(), r.ServiceNamespace())
 }
 
 func (r *Remote) ServicePort() uint32 {
-	return 15080
+	return r.DiscoveryService.GetPort()
+}
+
+func (r *Remote) GetIPFamilies() []IPFamily {
+	if len(r.IPFamilies) == 0 {
+		return []IPFamily{IPv4, IPv6}
+	}
+	return r.IPFamilies
+}
+
+// HasIPFamilyPreference returns true if IP families of the remote are configured explicitly.
+func (r *Remote) HasIPFamilyPreference() bool {
+	return len(r.IPFamilies) > 0
+}
+
+// FilterIPs returns IP addresses of the remote families, ordered by the family preference.
+// The order of addresses of the same family is preserved.
+func (r *Remote) FilterIPs(ips []string) []string {
+	var filtered []string
+	for _, family := range r.GetIPFamilies() {
+		for _, ip := range ips {
+			if family.Matches(ip) {
+				filtered = append(filtered, ip)
+			}
+		}
+	}
+	return filtered
 }
 
 func (r *Remote) GetPort() uint32 {
@@ -85,6 +242,54 @@ func (r *Remote) GetPort() uint32 {
 	return defaultGatewayPort
 }
 
+// DiscoveryService identifies the federation discovery service of a peer. Remote peers connect to it
+// through the ingress gateway using the service hostname, so local and remote settings must match.
+type DiscoveryService struct {
+	// Name of the service. Defaults to federation-discovery-service-<peer name>.
+	Name string `json:"name,omitempty"`
+	// Namespace of the service. Defaults to the namespace of the controller for the local peer
+	// and to istio-system for remote peers.
+	Namespace string `json:"namespace,omitempty"`
+	// Port of the service. Defaults to 15080.
+	Port *uint32 `json:"port,omitempty"`
+}
+
+func (d *DiscoveryService) GetPort() uint32 {
+	if d.Port == nil {
+		return DiscoveryPort
+	}
+	return *d.Port
+}
+
+func (d *DiscoveryService) nameOr(defaultName string) string {
+	if d.Name == "" {
+		return defaultName
+	}
+	return d.Name
+}
+
+func (d *DiscoveryService) namespaceOr(defaultNamespace string) string {
+	if d.Namespace == "" {
+		return defaultNamespace
+	}
+	return d.Namespace
+}
+
+// CACertificates points to a secret in the control plane namespace, which contains root certificates of the local mesh.
+// If not set, root certificates are read from the istio-ca-root-cert ConfigMap.
+type CACertificates struct {
+	SecretName string `json:"secretName"`
+	// Key of the root certificates in the secret. Defaults to root-cert.pem.
+	Key string `json:"key,omitempty"`
+}
+
+func (c *CACertificates) GetKey() string {
+	if c.Key == "" {
+		return defaultRootCertKey
+	}
+	return c.Key
+}
+
 type ControlPlane struct {
 	Namespace string `json:"namespace"`
 }
@@ -94,8 +299,27 @@ type Gateways struct {
 }
 
 type LocalGateway struct {
+	// Name of the generated gateway exposing exported services. Defaults to federation-ingress-gateway.
+	// It must be unique for each federation running in the same control plane namespace.
+	Name     string            `json:"name,omitempty"`
 	Selector map[string]string `json:"selector"`
 	Port     *GatewayPort      `json:"port,omitempty"`
+	// GatewayClassName is the class of the Gateway API Gateway created when the ingress type is gateway-api.
+	GatewayClassName string `json:"gatewayClassName,omitempty"`
+}
+
+func (g *LocalGateway) GetName() string {
+	if g.Name == "" {
+		return defaultIngressGatewayName
+	}
+	return g.Name
+}
+
+func (g *LocalGateway) GetGatewayClassName() string {
+	if g.GatewayClassName == "" {
+		return defaultGatewayClassName
+	}
+	return g.GatewayClassName
 }
 
 type GatewayPort struct {
@@ -110,22 +334,73 @@ type GatewayPorts struct {
 
 type ExportedServiceSet struct {
 	Rules []Rules `json:"rules"`
+	// AuthorizationPolicies are templates of policies restricting which peers may call matching exported services.
+	AuthorizationPolicies []AuthorizationPolicyTemplate `json:"authorizationPolicies,omitempty"`
+}
+
+// AuthorizationPolicyTemplate allows requests to exported services matching the label selectors only from the local mesh
+// and from the listed remotes, trust domains and principals. Requests from all other peers are denied.
+type AuthorizationPolicyTemplate struct {
+	LabelSelectors []LabelSelectors `json:"labelSelectors"`
+	// Remotes are names of remote peers, whose trust domains are allowed.
+	Remotes []string `json:"remotes,omitempty"`
+	// TrustDomains are allowed trust domains, e.g. west.local.
+	TrustDomains []string `json:"trustDomains,omitempty"`
+	// Principals are allowed identities in the format <trust-domain>/ns/<namespace>/sa/<service-account>.
+	Principals []string `json:"principals,omitempty"`
 }
 
-func (s *ExportedServiceSet) GetLabelSelectors() []LabelSelectors {
-	if s == nil || len(s.Rules) == 0 {
-		return []LabelSelectors{}
+// UseNamespaceSelectors returns true if any export rule is restricted to namespaces matching a selector.
+func (s *ExportedServiceSet) UseNamespaceSelectors() bool {
+	if s == nil {
+		return false
 	}
-	return s.Rules[0].LabelSelectors
+	for _, rule := range s.Rules {
+		if rule.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// UseServiceExports returns true if services marked for export by MCS ServiceExport objects should be exported.
+func (s *ExportedServiceSet) UseServiceExports() bool {
+	return s != nil && hasRuleType(s.Rules, ServiceExportRuleType)
 }
 
 type ImportedServiceSet struct {
 	Rules []Rules `json:"rules"`
 }
 
+// UseServiceImports returns true if imported services should be also represented as MCS ServiceImport objects.
+func (s *ImportedServiceSet) UseServiceImports() bool {
+	return s != nil && hasRuleType(s.Rules, ServiceImportRuleType)
+}
+
+func hasRuleType(rules []Rules, ruleType string) bool {
+	for _, rule := range rules {
+		if rule.Type == ruleType {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// LabelSelectorRuleType selects services by labels.
+	LabelSelectorRuleType = "LabelSelector"
+	// ServiceExportRuleType selects services for which a ServiceExport (multicluster.x-k8s.io) exists.
+	ServiceExportRuleType = "ServiceExport"
+	// ServiceImportRuleType enables creating ServiceImports (multicluster.x-k8s.io) for imported services.
+	ServiceImportRuleType = "ServiceImport"
+)
+
 type Rules struct {
 	Type           string           `json:"type"`
 	LabelSelectors []LabelSelectors `json:"labelSelectors"`
+	// NamespaceSelector restricts export rules to services in namespaces matching the selector.
+	// Services in all namespaces are selected if it is not set.
+	NamespaceSelector *LabelSelectors `json:"namespaceSelector,omitempty"`
 }
 
 type LabelSelectors struct {
@@ -133,6 +408,19 @@ type LabelSelectors struct {
 	MatchExpressions []MatchExpressions `json:"matchExpressions,omitempty"`
 }
 
+// AsSelector converts label selectors to labels.Selector. The results of matchLabels and matchExpressions are ANDed.
+func (s *LabelSelectors) AsSelector() (labels.Selector, error) {
+	labelSelector := &metav1.LabelSelector{MatchLabels: s.MatchLabels}
+	for _, expr := range s.MatchExpressions {
+		labelSelector.MatchExpressions = append(labelSelector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      expr.Key,
+			Operator: metav1.LabelSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	return metav1.LabelSelectorAsSelector(labelSelector)
+}
+
 type MatchExpressions struct {
 	Key      string   `json:"key"`
 	Operator string   `json:"operator"`
@@ -144,4 +432,30 @@ type IngressType string
 const (
 	Istio           IngressType = "istio"
 	OpenShiftRouter IngressType = "openshift-router"
+	GatewayAPI      IngressType = "gateway-api"
+)
+
+type IPFamily string
+
+const (
+	IPv4 IPFamily = "IPv4"
+	IPv6 IPFamily = "IPv6"
+)
+
+// Matches returns true if the address is an IP address of this family.
+func (f IPFamily) Matches(addr string) bool {
+	switch f {
+	case IPv4:
+		return networking.IsIPv4(addr)
+	case IPv6:
+		return networking.IsIPv6(addr)
+	}
+	return false
+}
+
+type DataplaneMode string
+
+const (
+	Sidecar DataplaneMode = "sidecar"
+	Ambient DataplaneMode = "ambient"
 )
@@ -18,6 +18,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/networking"
 )
 
 // ParseArgs parses input arguments passed in JSON format to the config.Federation struct.
@@ -40,6 +44,19 @@ func ParseArgs(meshPeers, exportedServiceSet, importedServiceSet string) (*Feder
 		}
 	}
 
+	if err := validateLocalName(peers.Local); err != nil {
+		return nil, err
+	}
+	if err := validateDataplaneMode(peers); err != nil {
+		return nil, err
+	}
+	if err := validateRemoteAddresses(peers); err != nil {
+		return nil, err
+	}
+	if err := validateNamespaceSelectors(exported); err != nil {
+		return nil, err
+	}
+
 	return &Federation{
 		MeshPeers:          peers,
 		ExportedServiceSet: exported,
@@ -47,6 +64,90 @@ func ParseArgs(meshPeers, exportedServiceSet, importedServiceSet string) (*Feder
 	}, nil
 }
 
+// validateLocalName ensures that the name of the local peer can be used as a label value and in names of
+// generated resources, because it distinguishes resources of federations running in the same cluster.
+func validateLocalName(local Local) error {
+	if errs := validation.IsDNS1123Label(local.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid name of the local peer %q: %s", local.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validateRemoteAddresses ensures that addresses of each remote are either all IP addresses or all hostnames,
+// because the resolution type of endpoints is common for all addresses, and that IP addresses match configured IP families.
+func validateRemoteAddresses(peers MeshPeers) error {
+	for _, remote := range peers.Remotes {
+		families := make(map[IPFamily]bool, len(remote.IPFamilies))
+		for _, family := range remote.IPFamilies {
+			if family != IPv4 && family != IPv6 {
+				return fmt.Errorf("unsupported IP family of remote %s: %s", remote.Name, family)
+			}
+			if families[family] {
+				return fmt.Errorf("duplicated IP family of remote %s: %s", remote.Name, family)
+			}
+			families[family] = true
+		}
+
+		var ips, hostnames []string
+		for _, addr := range remote.Addresses {
+			if networking.IsIP(addr) {
+				ips = append(ips, addr)
+			} else {
+				hostnames = append(hostnames, addr)
+			}
+		}
+		if len(ips) > 0 && len(hostnames) > 0 {
+			return fmt.Errorf("addresses of remote %s must be either all IP addresses or all hostnames, got IPs %v and hostnames %v",
+				remote.Name, ips, hostnames)
+		}
+		if len(ips) > 0 && len(remote.FilterIPs(ips)) != len(ips) {
+			return fmt.Errorf("addresses %v of remote %s do not match its IP families %v", ips, remote.Name, remote.GetIPFamilies())
+		}
+	}
+	return nil
+}
+
+// validateDataplaneMode ensures that ingress types are supported by the dataplane mode of the peer.
+// Ambient meshes accept federated traffic on the east-west gateway speaking HBONE, which can't be exposed
+// by routers or Gateway API implementations passing through TLS.
+func validateDataplaneMode(peers MeshPeers) error {
+	if !isValidDataplaneMode(peers.Local.DataplaneMode) {
+		return fmt.Errorf("unsupported dataplane mode of the local peer: %s", peers.Local.DataplaneMode)
+	}
+	if peers.Local.IsAmbient() && peers.Local.IngressType != "" && peers.Local.IngressType != Istio {
+		return fmt.Errorf("ingress type %s is not supported in ambient mode", peers.Local.IngressType)
+	}
+	for _, remote := range peers.Remotes {
+		if !isValidDataplaneMode(remote.DataplaneMode) {
+			return fmt.Errorf("unsupported dataplane mode of remote %s: %s", remote.Name, remote.DataplaneMode)
+		}
+		if remote.IsAmbient() && remote.RequiresRouterCompatibleSNI() {
+			return fmt.Errorf("ingress type %s of remote %s is not supported in ambient mode", remote.IngressType, remote.Name)
+		}
+	}
+	return nil
+}
+
+// validateNamespaceSelectors ensures that namespace selectors of export rules are valid label selectors.
+func validateNamespaceSelectors(exported ExportedServiceSet) error {
+	for _, rule := range exported.Rules {
+		if rule.NamespaceSelector == nil {
+			continue
+		}
+		if rule.Type != LabelSelectorRuleType && rule.Type != ServiceExportRuleType {
+			return fmt.Errorf("namespace selector is not supported in rules of type %s", rule.Type)
+		}
+		if _, err := rule.NamespaceSelector.AsSelector(); err != nil {
+			return fmt.Errorf("invalid namespace selector in rule of type %s: %w", rule.Type, err)
+		}
+	}
+	return nil
+}
+
+func isValidDataplaneMode(mode DataplaneMode) bool {
+	return mode == "" || mode == Sidecar || mode == Ambient
+}
+
 func unmarshalJSON(input string, out any) error {
 	dec := json.NewDecoder(strings.NewReader(input))
 	dec.DisallowUnknownFields()
@@ -0,0 +1,88 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	const exportAll = `{"rules":[{"type":"LabelSelector","labelSelectors":[{"matchLabels":{"export":"true"}}]}]}`
+
+	testCases := []struct {
+		name               string
+		meshPeers          string
+		exportedServiceSet string
+		expectedErr        string
+	}{{
+		name:               "valid configuration",
+		meshPeers:          `{"local":{"name":"east"},"remotes":[{"name":"west","addresses":["1.1.1.1","2.2.2.2"]}]}`,
+		exportedServiceSet: exportAll,
+	}, {
+		name:               "local name is not a DNS-1123 label",
+		meshPeers:          `{"local":{"name":"East_1"},"remotes":[{"name":"west","addresses":["1.1.1.1"]}]}`,
+		exportedServiceSet: exportAll,
+		expectedErr:        `invalid name of the local peer "East_1"`,
+	}, {
+		name:               "local name is empty",
+		meshPeers:          `{"local":{},"remotes":[{"name":"west","addresses":["1.1.1.1"]}]}`,
+		exportedServiceSet: exportAll,
+		expectedErr:        `invalid name of the local peer ""`,
+	}, {
+		name:               "remote addresses mix IP addresses and hostnames",
+		meshPeers:          `{"local":{"name":"east"},"remotes":[{"name":"west","addresses":["1.1.1.1","west.example.com"]}]}`,
+		exportedServiceSet: exportAll,
+		expectedErr:        "addresses of remote west must be either all IP addresses or all hostnames",
+	}, {
+		name:      "invalid namespace selector",
+		meshPeers: `{"local":{"name":"east"},"remotes":[{"name":"west","addresses":["1.1.1.1"]}]}`,
+		exportedServiceSet: `{"rules":[{"type":"LabelSelector","labelSelectors":[{"matchLabels":{"export":"true"}}],` +
+			`"namespaceSelector":{"matchExpressions":[{"key":"team","operator":"Exists","values":["a"]}]}}]}`,
+		expectedErr: "invalid namespace selector in rule of type LabelSelector",
+	}, {
+		name:               "namespace selector in a rule of unsupported type",
+		meshPeers:          `{"local":{"name":"east"},"remotes":[{"name":"west","addresses":["1.1.1.1"]}]}`,
+		exportedServiceSet: `{"rules":[{"type":"ServiceImport","namespaceSelector":{"matchLabels":{"team":"a"}}}]}`,
+		expectedErr:        "namespace selector is not supported in rules of type ServiceImport",
+	}, {
+		name:               "unknown dataplane mode of the local peer",
+		meshPeers:          `{"local":{"name":"east","dataplaneMode":"proxyless"},"remotes":[{"name":"west","addresses":["1.1.1.1"]}]}`,
+		exportedServiceSet: exportAll,
+		expectedErr:        "unsupported dataplane mode of the local peer: proxyless",
+	}, {
+		name:               "unknown dataplane mode of a remote",
+		meshPeers:          `{"local":{"name":"east"},"remotes":[{"name":"west","addresses":["1.1.1.1"],"dataplaneMode":"proxyless"}]}`,
+		exportedServiceSet: exportAll,
+		expectedErr:        "unsupported dataplane mode of remote west: proxyless",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := ParseArgs(tc.meshPeers, tc.exportedServiceSet, "")
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cfg == nil {
+					t.Fatal("expected configuration, got nil")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of events recorded by the federation controller.
+const (
+	ReasonPeerConnected    = "PeerConnected"
+	ReasonPeerDisconnected = "PeerDisconnected"
+	ReasonServiceImported  = "ServiceImported"
+	ReasonServiceWithdrawn = "ServiceWithdrawn"
+	ReasonExportStarted    = "ExportStarted"
+	ReasonExportStopped    = "ExportStopped"
+	ReasonApplyFailed      = "ApplyFailed"
+	ReasonNamingConflict   = "NamingConflict"
+
+	// ReasonGatewayReachable and ReasonGatewayUnreachable report results of probing addresses of remote ingress gateways.
+	ReasonGatewayReachable   = "GatewayReachable"
+	ReasonGatewayUnreachable = "GatewayUnreachable"
+
+	// ReasonTrustDomainMismatch reports that a remote peer advertises a trust domain different from the configured one.
+	ReasonTrustDomainMismatch = "TrustDomainMismatch"
+)
+
+// Recorder records federation lifecycle events. Events related to a service are recorded on the local Service,
+// so that app teams can see why their service is or isn't federated, and other events are recorded
+// on the federation object, which is the MeshFederation, or the controller Pod in legacy mode.
+// A nil Recorder discards all events.
+type Recorder struct {
+	recorder   record.EventRecorder
+	federation runtime.Object
+}
+
+func NewRecorder(recorder record.EventRecorder, federation runtime.Object) *Recorder {
+	return &Recorder{
+		recorder:   recorder,
+		federation: federation,
+	}
+}
+
+// Federation records an event on the federation object.
+func (r *Recorder) Federation(eventType, reason, messageFmt string, args ...any) {
+	if r == nil {
+		return
+	}
+	r.recorder.Eventf(r.federation, eventType, reason, messageFmt, args...)
+}
+
+// Service records an event on the given local Service. If the service does not exist in the local cluster,
+// i.e. svc is nil, the event is recorded on the federation object and the message is prefixed with the service key.
+func (r *Recorder) Service(svc *corev1.Service, namespace, name, eventType, reason, messageFmt string, args ...any) {
+	if r == nil {
+		return
+	}
+	if svc == nil {
+		r.Federation(eventType, reason, "%s/%s: %s", namespace, name, fmt.Sprintf(messageFmt, args...))
+		return
+	}
+	r.recorder.Eventf(svc, eventType, reason, messageFmt, args...)
+}
+
+// PodReference returns a reference to the controller Pod, which is the federation object in legacy mode.
+func PodReference(namespace, name string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exportstatus
+
+import (
+	"fmt"
+	"strings"
+
+	istioprotocol "istio.io/istio/pkg/config/protocol"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	v1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+const (
+	// AnnotationExportedTo lists names of remote peers the service is exported to.
+	AnnotationExportedTo = "federation.openshift-service-mesh.io/exported-to"
+	// AnnotationExportedHosts lists SNIs or Route hostnames, under which remote peers reach the service.
+	AnnotationExportedHosts = "federation.openshift-service-mesh.io/exported-hosts"
+	// AnnotationExportedGateway is the namespaced name of the generated gateway exposing the service.
+	AnnotationExportedGateway = "federation.openshift-service-mesh.io/exported-gateway"
+	// AnnotationExportErrors describes why the service or some of its ports can't be exposed to remote peers.
+	AnnotationExportErrors = "federation.openshift-service-mesh.io/export-errors"
+)
+
+// Annotations contains keys of all annotations managed by this package, not prefixed with a peer name.
+var Annotations = []string{AnnotationExportedTo, AnnotationExportedHosts, AnnotationExportedGateway, AnnotationExportErrors}
+
+// AnnotationKey returns the key of an export status annotation of the given local peer. Keys are prefixed with
+// the peer name, e.g. east.federation.openshift-service-mesh.io/exported-to, so that federations exporting the same
+// service report their status independently. Keys are not prefixed if the peer name is empty.
+func AnnotationKey(peer, key string) string {
+	if peer == "" {
+		return key
+	}
+	return peer + "." + key
+}
+
+// AnnotationKeys returns keys of all export status annotations of the given local peer.
+func AnnotationKeys(peer string) []string {
+	keys := make([]string, 0, len(Annotations))
+	for _, key := range Annotations {
+		keys = append(keys, AnnotationKey(peer, key))
+	}
+	return keys
+}
+
+// ConfigFactory generates annotations reporting the export state of local services to their owners.
+type ConfigFactory struct {
+	cfg                   config.Federation
+	serviceLister         v1.ServiceLister
+	exportedServiceLister *common.ExportedServiceLister
+}
+
+func NewConfigFactory(
+	cfg config.Federation,
+	serviceLister v1.ServiceLister,
+	exportedServiceLister *common.ExportedServiceLister,
+) *ConfigFactory {
+	return &ConfigFactory{
+		cfg:                   cfg,
+		serviceLister:         serviceLister,
+		exportedServiceLister: exportedServiceLister,
+	}
+}
+
+// ServiceAnnotations returns export status annotations of every exported service. Services that are no longer exported,
+// but still have any of the export status annotations, are returned with nil annotations to remove them.
+func (cf *ConfigFactory) ServiceAnnotations() (map[types.NamespacedName]map[string]string, error) {
+	exportedServices, err := cf.exportedServiceLister.List()
+	if err != nil {
+		return nil, fmt.Errorf("error listing exported services: %w", err)
+	}
+	out := make(map[types.NamespacedName]map[string]string, len(exportedServices))
+	for _, svc := range exportedServices {
+		out[types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}] = cf.annotations(svc)
+	}
+
+	services, err := cf.serviceLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing services: %w", err)
+	}
+	for _, svc := range services {
+		key := types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}
+		if _, exported := out[key]; exported {
+			continue
+		}
+		if HasAnnotations(svc, cf.peer()) {
+			out[key] = nil
+		}
+	}
+	return out, nil
+}
+
+// AnnotationKeys returns keys of export status annotations managed by this federation.
+func (cf *ConfigFactory) AnnotationKeys() []string {
+	return AnnotationKeys(cf.peer())
+}
+
+// HasAnnotations returns true if the service has any of the export status annotations of the given local peer.
+func HasAnnotations(svc *corev1.Service, peer string) bool {
+	for _, key := range AnnotationKeys(peer) {
+		if _, found := svc.Annotations[key]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func (cf *ConfigFactory) peer() string {
+	return cf.cfg.MeshPeers.Local.Name
+}
+
+func (cf *ConfigFactory) annotations(svc *corev1.Service) map[string]string {
+	peer := cf.peer()
+	if err := common.CheckExportable(svc); err != nil {
+		return map[string]string{AnnotationKey(peer, AnnotationExportErrors): err.Error()}
+	}
+
+	var peers []string
+	for _, remote := range cf.cfg.MeshPeers.Remotes {
+		peers = append(peers, remote.Name)
+	}
+	annotations := map[string]string{
+		AnnotationKey(peer, AnnotationExportedTo):      strings.Join(peers, ","),
+		AnnotationKey(peer, AnnotationExportedGateway): fmt.Sprintf("%s/%s", cf.cfg.MeshPeers.Local.ControlPlane.Namespace, cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName()),
+	}
+
+	var hosts, errs []string
+	for _, port := range svc.Spec.Ports {
+		if common.DetectProtocol(port) == istioprotocol.UDP {
+			errs = append(errs, fmt.Sprintf("port %d/UDP cannot be exposed through %s", port.Port, cf.ingress()))
+			continue
+		}
+		host, err := cf.host(svc, port)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) > 0 {
+		annotations[AnnotationKey(peer, AnnotationExportedHosts)] = strings.Join(hosts, ",")
+	}
+	if len(errs) > 0 {
+		annotations[AnnotationKey(peer, AnnotationExportErrors)] = strings.Join(errs, "; ")
+	}
+	return annotations
+}
+
+// host returns the SNI or Route hostname matched by the gateway for the given port. In ambient mode, services are
+// reached through HBONE tunnels terminated by the east-west gateway, so there is no per-service hostname.
+func (cf *ConfigFactory) host(svc *corev1.Service, port corev1.ServicePort) (string, error) {
+	local := cf.cfg.MeshPeers.Local
+	switch {
+	case local.IsAmbient():
+		return "", nil
+	case local.IngressType == config.OpenShiftRouter || local.IngressType == config.GatewayAPI:
+		host := common.RouterCompatibleSNI(svc.Name, svc.Namespace, uint32(port.Port))
+		if label, _, _ := strings.Cut(host, "."); len(label) > validation.DNS1123LabelMaxLength {
+			return "", fmt.Errorf("port %d cannot be exposed through %s, because hostname label %s is longer than %d characters",
+				port.Port, cf.ingress(), label, validation.DNS1123LabelMaxLength)
+		}
+		return host, nil
+	default:
+		return fmt.Sprintf("outbound_.%d_._.%s.%s.svc.cluster.local", port.Port, svc.Name, svc.Namespace), nil
+	}
+}
+
+func (cf *ConfigFactory) ingress() string {
+	local := cf.cfg.MeshPeers.Local
+	switch {
+	case local.IsAmbient():
+		return "east-west gateway"
+	case local.IngressType == "":
+		return string(config.Istio)
+	default:
+		return string(local.IngressType)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exportstatus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+func TestServiceAnnotations(t *testing.T) {
+	exportLabels := map[string]string{"export": "true"}
+	httpbin := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "httpbin", Namespace: "ns1", Labels: exportLabels},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 8000, Protocol: corev1.ProtocolTCP},
+				{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP},
+			},
+		},
+	}
+	externalName := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "ns1", Labels: exportLabels},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "example.com"},
+	}
+	longName := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 60), Namespace: "ns1", Labels: exportLabels},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	unexported := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "reviews",
+			Namespace:   "ns2",
+			Annotations: map[string]string{AnnotationExportedTo: "west"},
+		},
+	}
+	annotatedByOtherPeer := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "details",
+			Namespace:   "ns2",
+			Annotations: map[string]string{AnnotationKey("north", AnnotationExportedTo): "south"},
+		},
+	}
+	unannotated := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "ratings", Namespace: "ns2"},
+	}
+
+	testCases := []struct {
+		name                string
+		local               config.Local
+		services            []runtime.Object
+		expectedAnnotations map[types.NamespacedName]map[string]string
+	}{{
+		name:     "exported service should be annotated with SNIs of the istio ingress gateway",
+		local:    config.Local{ControlPlane: config.ControlPlane{Namespace: "istio-system"}},
+		services: []runtime.Object{httpbin},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{
+			{Namespace: "ns1", Name: "httpbin"}: {
+				AnnotationExportedTo:      "west,central",
+				AnnotationExportedGateway: "istio-system/federation-ingress-gateway",
+				AnnotationExportedHosts:   "outbound_.8000_._.httpbin.ns1.svc.cluster.local",
+				AnnotationExportErrors:    "port 53/UDP cannot be exposed through istio",
+			},
+		},
+	}, {
+		name: "exported service should be annotated with route hostnames",
+		local: config.Local{
+			ControlPlane: config.ControlPlane{Namespace: "istio-system"},
+			IngressType:  config.OpenShiftRouter,
+		},
+		services: []runtime.Object{httpbin, longName},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{
+			{Namespace: "ns1", Name: "httpbin"}: {
+				AnnotationExportedTo:      "west,central",
+				AnnotationExportedGateway: "istio-system/federation-ingress-gateway",
+				AnnotationExportedHosts:   "httpbin-8000.ns1.svc.cluster.local",
+				AnnotationExportErrors:    "port 53/UDP cannot be exposed through openshift-router",
+			},
+			{Namespace: "ns1", Name: longName.Name}: {
+				AnnotationExportedTo:      "west,central",
+				AnnotationExportedGateway: "istio-system/federation-ingress-gateway",
+				AnnotationExportErrors: "port 8080 cannot be exposed through openshift-router, because hostname label " +
+					longName.Name + "-8080 is longer than 63 characters",
+			},
+		},
+	}, {
+		name: "exported service should not be annotated with hosts in ambient mode",
+		local: config.Local{
+			ControlPlane:  config.ControlPlane{Namespace: "istio-system"},
+			DataplaneMode: config.Ambient,
+		},
+		services: []runtime.Object{httpbin},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{
+			{Namespace: "ns1", Name: "httpbin"}: {
+				AnnotationExportedTo:      "west,central",
+				AnnotationExportedGateway: "istio-system/federation-ingress-gateway",
+				AnnotationExportErrors:    "port 53/UDP cannot be exposed through east-west gateway",
+			},
+		},
+	}, {
+		name:     "annotation keys should be prefixed with the local peer name",
+		local:    config.Local{Name: "east", ControlPlane: config.ControlPlane{Namespace: "istio-system"}},
+		services: []runtime.Object{httpbin},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{
+			{Namespace: "ns1", Name: "httpbin"}: {
+				"east.federation.openshift-service-mesh.io/exported-to":      "west,central",
+				"east.federation.openshift-service-mesh.io/exported-gateway": "istio-system/federation-ingress-gateway",
+				"east.federation.openshift-service-mesh.io/exported-hosts":   "outbound_.8000_._.httpbin.ns1.svc.cluster.local",
+				"east.federation.openshift-service-mesh.io/export-errors":    "port 53/UDP cannot be exposed through istio",
+			},
+		},
+	}, {
+		name:     "not exportable service should be annotated only with the error",
+		local:    config.Local{ControlPlane: config.ControlPlane{Namespace: "istio-system"}},
+		services: []runtime.Object{externalName},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{
+			{Namespace: "ns1", Name: "external"}: {
+				AnnotationExportErrors: common.CheckExportable(externalName).Error(),
+			},
+		},
+	}, {
+		name:     "annotations should be removed from services that are no longer exported",
+		local:    config.Local{ControlPlane: config.ControlPlane{Namespace: "istio-system"}},
+		services: []runtime.Object{unexported, unannotated},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{
+			{Namespace: "ns2", Name: "reviews"}: nil,
+		},
+	}, {
+		name:                "annotations of other peers should be ignored",
+		local:               config.Local{Name: "east", ControlPlane: config.ControlPlane{Namespace: "istio-system"}},
+		services:            []runtime.Object{annotatedByOtherPeer},
+		expectedAnnotations: map[types.NamespacedName]map[string]string{},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tc.services...)
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			serviceLister := informerFactory.Core().V1().Services().Lister()
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			informerFactory.WaitForCacheSync(stopCh)
+
+			cfg := config.Federation{
+				MeshPeers: config.MeshPeers{
+					Local:   tc.local,
+					Remotes: []config.Remote{{Name: "west"}, {Name: "central"}},
+				},
+				ExportedServiceSet: config.ExportedServiceSet{
+					Rules: []config.Rules{{
+						Type:           config.LabelSelectorRuleType,
+						LabelSelectors: []config.LabelSelectors{{MatchLabels: exportLabels}},
+					}},
+				},
+			}
+			cf := NewConfigFactory(cfg, serviceLister, common.NewExportedServiceLister(cfg, serviceLister, nil, nil))
+
+			annotations, err := cf.ServiceAnnotations()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(annotations, tc.expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tc.expectedAnnotations, annotations)
+			}
+		})
+	}
+}
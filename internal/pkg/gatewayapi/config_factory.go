@@ -0,0 +1,169 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+const (
+	eastWestGatewayClassName = "istio-east-west"
+	hbonePort                = 15008
+)
+
+type ConfigFactory struct {
+	cfg                   config.Federation
+	exportedServiceLister *common.ExportedServiceLister
+	namespace             string
+}
+
+func NewConfigFactory(
+	cfg config.Federation,
+	exportedServiceLister *common.ExportedServiceLister,
+	namespace string,
+) *ConfigFactory {
+	return &ConfigFactory{
+		cfg:                   cfg,
+		exportedServiceLister: exportedServiceLister,
+		namespace:             namespace,
+	}
+}
+
+// Gateway returns a Gateway with a single TLS passthrough listener, which accepts TLSRoutes from all namespaces,
+// because routes for exported services are created in namespaces of these services.
+// In ambient mode, it returns an east-west gateway terminating HBONE, which does not require any routes.
+func (cf *ConfigFactory) Gateway() *gwv1.Gateway {
+	if cf.cfg.MeshPeers.Local.IsAmbient() {
+		return cf.eastWestGateway()
+	}
+	ingress := cf.cfg.MeshPeers.Local.Gateways.Ingress
+	return &gwv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName(),
+			Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
+			Labels:    cf.cfg.PeerLabels(),
+		},
+		Spec: gwv1.GatewaySpec{
+			GatewayClassName: gwv1.ObjectName(ingress.GetGatewayClassName()),
+			Listeners: []gwv1.Listener{{
+				Name:     gwv1.SectionName(ingress.Port.Name),
+				Port:     gwv1.PortNumber(ingress.Port.Number),
+				Protocol: gwv1.TLSProtocolType,
+				TLS: &gwv1.GatewayTLSConfig{
+					Mode: ptr.To(gwv1.TLSModePassthrough),
+				},
+				AllowedRoutes: &gwv1.AllowedRoutes{
+					Namespaces: &gwv1.RouteNamespaces{
+						From: ptr.To(gwv1.NamespacesFromAll),
+					},
+					Kinds: []gwv1.RouteGroupKind{{
+						Group: ptr.To(gwv1.Group(gwv1.GroupName)),
+						Kind:  "TLSRoute",
+					}},
+				},
+			}},
+		},
+	}
+}
+
+func (cf *ConfigFactory) eastWestGateway() *gwv1.Gateway {
+	labels := cf.cfg.PeerLabels()
+	if cf.cfg.MeshPeers.Local.Network != "" {
+		labels["topology.istio.io/network"] = cf.cfg.MeshPeers.Local.Network
+	}
+	return &gwv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName(),
+			Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
+			Labels:    labels,
+		},
+		Spec: gwv1.GatewaySpec{
+			GatewayClassName: eastWestGatewayClassName,
+			Listeners: []gwv1.Listener{{
+				Name:     "mesh",
+				Port:     hbonePort,
+				Protocol: "HBONE",
+				TLS: &gwv1.GatewayTLSConfig{
+					Mode: ptr.To(gwv1.TLSModeTerminate),
+					Options: map[gwv1.AnnotationKey]gwv1.AnnotationValue{
+						"gateway.istio.io/tls-terminate-mode": "ISTIO_MUTUAL",
+					},
+				},
+			}},
+		},
+	}
+}
+
+// TLSRoutes returns a route for the local discovery service and a route for every port of each exported service.
+// Routes match SNI compatible with https://datatracker.ietf.org/doc/html/rfc952 and are created in the namespace
+// of the backend service, so ReferenceGrants are not needed.
+func (cf *ConfigFactory) TLSRoutes() ([]*gwv1alpha2.TLSRoute, error) {
+	routes := []*gwv1alpha2.TLSRoute{
+		cf.tlsRoute(cf.cfg.DiscoveryServiceName(), cf.cfg.DiscoveryServiceNamespace(), cf.namespace, int32(cf.cfg.DiscoveryServicePort())),
+	}
+	services, err := cf.exportedServiceLister.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services {
+		if common.CheckExportable(svc) != nil {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			routes = append(routes, cf.tlsRoute(svc.Name, svc.Namespace, svc.Namespace, port.Port))
+		}
+	}
+	return routes, nil
+}
+
+// tlsRoute creates a route to the given service in the backend namespace. The hostname namespace may differ
+// from the backend namespace, because the namespace of the discovery service advertised to remote peers
+// may differ from the namespace of the controller.
+func (cf *ConfigFactory) tlsRoute(svcName, hostnameNs, backendNs string, port int32) *gwv1alpha2.TLSRoute {
+	return &gwv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d-to-%s", svcName, port, cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName()),
+			Namespace: backendNs,
+			Labels:    cf.cfg.PeerLabels(),
+		},
+		Spec: gwv1alpha2.TLSRouteSpec{
+			CommonRouteSpec: gwv1.CommonRouteSpec{
+				ParentRefs: []gwv1.ParentReference{{
+					Name:      gwv1.ObjectName(cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName()),
+					Namespace: ptr.To(gwv1.Namespace(cf.cfg.MeshPeers.Local.ControlPlane.Namespace)),
+				}},
+			},
+			Hostnames: []gwv1.Hostname{
+				gwv1.Hostname(common.RouterCompatibleSNI(svcName, hostnameNs, uint32(port))),
+			},
+			Rules: []gwv1alpha2.TLSRouteRule{{
+				BackendRefs: []gwv1.BackendRef{{
+					BackendObjectReference: gwv1.BackendObjectReference{
+						Name: gwv1.ObjectName(svcName),
+						Port: ptr.To(gwv1.PortNumber(port)),
+					},
+				}},
+			}},
+		},
+	}
+}
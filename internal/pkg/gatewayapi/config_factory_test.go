@@ -0,0 +1,271 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gwv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+var (
+	exportLabels = map[string]string{"export": "true"}
+
+	federationConfig = config.Federation{
+		MeshPeers: config.MeshPeers{
+			Local: config.Local{
+				Name: "east",
+				ControlPlane: config.ControlPlane{
+					Namespace: "istio-system",
+				},
+				Gateways: config.Gateways{
+					Ingress: config.LocalGateway{
+						Port: &config.GatewayPort{
+							Name:   "tls-passthrough",
+							Number: 15443,
+						},
+					},
+				},
+				IngressType: config.GatewayAPI,
+			},
+		},
+		ExportedServiceSet: config.ExportedServiceSet{
+			Rules: []config.Rules{{
+				Type:           config.LabelSelectorRuleType,
+				LabelSelectors: []config.LabelSelectors{{MatchLabels: exportLabels}},
+			}},
+		},
+	}
+
+	svcA = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "a",
+			Namespace: "ns1",
+			Labels:    exportLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}, {Name: "http-alt", Port: 8080}},
+		},
+	}
+	svcB = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "b",
+			Namespace: "ns1",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	}
+	svcExternal = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "external",
+			Namespace: "ns1",
+			Labels:    exportLabels,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "example.com",
+			Ports:        []corev1.ServicePort{{Name: "https", Port: 443}},
+		},
+	}
+)
+
+func TestGateway(t *testing.T) {
+	customClassConfig := federationConfig
+	customClassConfig.MeshPeers.Local.Gateways.Ingress.GatewayClassName = "custom"
+	customNameConfig := federationConfig
+	customNameConfig.MeshPeers.Local.Gateways.Ingress.Name = "partner-gateway"
+
+	testCases := []struct {
+		name              string
+		cfg               config.Federation
+		expectedName      string
+		expectedClassName gwv1.ObjectName
+	}{{
+		name:              "gateway class should default to istio",
+		cfg:               federationConfig,
+		expectedName:      "federation-ingress-gateway",
+		expectedClassName: "istio",
+	}, {
+		name:              "gateway class should be taken from the config",
+		cfg:               customClassConfig,
+		expectedName:      "federation-ingress-gateway",
+		expectedClassName: "custom",
+	}, {
+		name:              "gateway name should be taken from the config",
+		cfg:               customNameConfig,
+		expectedName:      "partner-gateway",
+		expectedClassName: "istio",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := &gwv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tc.expectedName,
+					Namespace: "istio-system",
+					Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
+				},
+				Spec: gwv1.GatewaySpec{
+					GatewayClassName: tc.expectedClassName,
+					Listeners: []gwv1.Listener{{
+						Name:     "tls-passthrough",
+						Port:     15443,
+						Protocol: gwv1.TLSProtocolType,
+						TLS: &gwv1.GatewayTLSConfig{
+							Mode: ptr.To(gwv1.TLSModePassthrough),
+						},
+						AllowedRoutes: &gwv1.AllowedRoutes{
+							Namespaces: &gwv1.RouteNamespaces{
+								From: ptr.To(gwv1.NamespacesFromAll),
+							},
+							Kinds: []gwv1.RouteGroupKind{{
+								Group: ptr.To(gwv1.Group("gateway.networking.k8s.io")),
+								Kind:  "TLSRoute",
+							}},
+						},
+					}},
+				},
+			}
+
+			actual := NewConfigFactory(tc.cfg, nil, "istio-system").Gateway()
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("got unexpected result:\nexpected:\n%s\ngot:\n%s", toJSON(expected), toJSON(actual))
+			}
+		})
+	}
+}
+
+func TestEastWestGateway(t *testing.T) {
+	ambientConfig := federationConfig
+	ambientConfig.MeshPeers.Local.DataplaneMode = config.Ambient
+	ambientConfig.MeshPeers.Local.Network = "east-network"
+
+	expected := &gwv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "federation-ingress-gateway",
+			Namespace: "istio-system",
+			Labels: map[string]string{
+				"federation.openshift-service-mesh.io/peer": "east",
+				"topology.istio.io/network":                 "east-network",
+			},
+		},
+		Spec: gwv1.GatewaySpec{
+			GatewayClassName: "istio-east-west",
+			Listeners: []gwv1.Listener{{
+				Name:     "mesh",
+				Port:     15008,
+				Protocol: "HBONE",
+				TLS: &gwv1.GatewayTLSConfig{
+					Mode: ptr.To(gwv1.TLSModeTerminate),
+					Options: map[gwv1.AnnotationKey]gwv1.AnnotationValue{
+						"gateway.istio.io/tls-terminate-mode": "ISTIO_MUTUAL",
+					},
+				},
+			}},
+		},
+	}
+
+	actual := NewConfigFactory(ambientConfig, nil, "istio-system").Gateway()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got unexpected result:\nexpected:\n%s\ngot:\n%s", toJSON(expected), toJSON(actual))
+	}
+}
+
+func TestTLSRoutes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		localServices  []*corev1.Service
+		expectedRoutes []string
+	}{{
+		name:           "only the discovery service route should be created when no services are exported",
+		localServices:  []*corev1.Service{svcB},
+		expectedRoutes: []string{"federation-discovery-service-east.yaml"},
+	}, {
+		name:          "routes should be created for each port of exported services, except ExternalName services",
+		localServices: []*corev1.Service{svcA, svcB, svcExternal},
+		expectedRoutes: []string{
+			"federation-discovery-service-east.yaml",
+			"svc-a-80-ns-1.yaml",
+			"svc-a-8080-ns-1.yaml",
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			for _, svc := range tc.localServices {
+				if _, err := client.CoreV1().Services(svc.Namespace).Create(context.Background(), svc, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create service %s/%s: %v", svc.Name, svc.Namespace, err)
+				}
+			}
+
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			serviceInformer := informerFactory.Core().V1().Services().Informer()
+			serviceLister := informerFactory.Core().V1().Services().Lister()
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			cache.WaitForCacheSync(stopCh, serviceInformer.HasSynced)
+
+			cf := NewConfigFactory(federationConfig, common.NewExportedServiceLister(federationConfig, serviceLister, nil, nil), "istio-system")
+			actual, err := cf.TLSRoutes()
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+
+			var expected []*gwv1alpha2.TLSRoute
+			for _, f := range tc.expectedRoutes {
+				data, err := os.ReadFile(filepath.Join("testdata", "tls-routes", f))
+				if err != nil {
+					t.Fatalf("failed to read file: %v", err)
+				}
+				route := &gwv1alpha2.TLSRoute{}
+				if err := yaml.Unmarshal(data, route); err != nil {
+					t.Fatalf("failed to unmarshal data from %s: %v", f, err)
+				}
+				// TypeMeta is not set on generated objects
+				route.TypeMeta = metav1.TypeMeta{}
+				expected = append(expected, route)
+			}
+
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("got unexpected result:\nexpected:\n%s\ngot:\n%s", toJSON(expected), toJSON(actual))
+			}
+		})
+	}
+}
+
+func toJSON(input any) string {
+	str, err := json.Marshal(input)
+	if err != nil {
+		panic(err)
+	}
+	return string(str)
+}
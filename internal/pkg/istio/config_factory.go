@@ -20,153 +20,259 @@ import (
 	"strings"
 
 	"google.golang.org/protobuf/types/known/structpb"
+	istionetv1 "istio.io/api/networking/v1"
 	istionetv1alpha3 "istio.io/api/networking/v1alpha3"
+	istiosecurityv1 "istio.io/api/security/v1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	networkingv1 "istio.io/client-go/pkg/apis/networking/v1"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	securityv1 "istio.io/client-go/pkg/apis/security/v1"
+	istioprotocol "istio.io/istio/pkg/config/protocol"
 	istiolog "istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/maps"
 	"istio.io/istio/pkg/slices"
 	"istio.io/istio/pkg/util/protomarshal"
+	"istio.io/istio/pkg/util/sets"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	v1 "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
 
 	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/config"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/fds"
 	"github.com/openshift-service-mesh/federation/internal/pkg/networking"
 )
 
-const (
-	federationIngressGatewayName = "federation-ingress-gateway"
-)
-
 type ConfigFactory struct {
-	cfg                  config.Federation
-	serviceLister        v1.ServiceLister
-	importedServiceStore *fds.ImportedServiceStore
-	namespace            string
-	log                  *istiolog.Scope
+	cfg                   config.Federation
+	serviceLister         v1.ServiceLister
+	exportedServiceLister *common.ExportedServiceLister
+	endpointSliceLister   discoverylisters.EndpointSliceLister
+	importedServiceStore  *fds.ImportedServiceStore
+	addressCache          *networking.AddressCache
+	gatewayProber         *networking.GatewayProber
+	namespace             string
+	log                   *istiolog.Scope
 }
 
 func NewConfigFactory(
 	cfg config.Federation,
 	serviceLister v1.ServiceLister,
+	exportedServiceLister *common.ExportedServiceLister,
+	endpointSliceLister discoverylisters.EndpointSliceLister,
 	importedServiceStore *fds.ImportedServiceStore,
+	addressCache *networking.AddressCache,
+	gatewayProber *networking.GatewayProber,
 	namespace string,
 ) *ConfigFactory {
 	return &ConfigFactory{
-		cfg:                  cfg,
-		serviceLister:        serviceLister,
-		importedServiceStore: importedServiceStore,
-		namespace:            namespace,
-		log:                  istiolog.RegisterScope("istio-cfg-factory", "Istio Resources Config Factory").WithLabels("namespace", namespace),
+		cfg:                   cfg,
+		serviceLister:         serviceLister,
+		exportedServiceLister: exportedServiceLister,
+		endpointSliceLister:   endpointSliceLister,
+		importedServiceStore:  importedServiceStore,
+		addressCache:          addressCache,
+		gatewayProber:         gatewayProber,
+		namespace:             namespace,
+		log:                   istiolog.RegisterScope("istio-cfg-factory", "Istio Resources Config Factory").WithLabels("namespace", namespace),
 	}
 }
 
-// DestinationRules customize SNI in the client mTLS connection when the remote ingress is openshift-router,
-// because that ingress requires hosts compatible with https://datatracker.ietf.org/doc/html/rfc952.
-func (cf *ConfigFactory) DestinationRules() []*v1alpha3.DestinationRule {
-	var destinationRules []*v1alpha3.DestinationRule
-	destinationRulesAlreadyCreated := make(map[string]bool, len(cf.cfg.MeshPeers.Remotes))
-
-	for _, remote := range cf.cfg.MeshPeers.Remotes {
-		if remote.IngressType != config.OpenShiftRouter {
-			// Skipping peers which are not using openshift-router
-			continue
-		}
-
-		createObjectMeta := func(hostname string) metav1.ObjectMeta {
-			return metav1.ObjectMeta{
-				Name:      fmt.Sprintf("mtls-sni-%s", separateWithDash(hostname)),
-				Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
-				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-			}
+// DestinationRules configures client mTLS for imported services. Imported services from remotes routing by hostname
+// require SNI compatible with OpenShift Router and Gateway API, and services exporting their service accounts
+// are verified using SPIFFE identities derived from the trust domain of the remote mesh.
+func (cf *ConfigFactory) DestinationRules() []*networkingv1.DestinationRule {
+	var destinationRules []*networkingv1.DestinationRule
+	destinationRulesByHost := make(map[string]*networkingv1.DestinationRule)
+	// Hosts imported from any remote that does not export service accounts can't be verified by identities
+	unverifiedHosts := sets.New[string]()
+
+	createObjectMeta := func(prefix, hostname string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", prefix, separateWithDash(hostname)),
+			Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
+			Labels:    cf.cfg.PeerLabels(),
 		}
+	}
 
-		destinationRules = append(destinationRules, &v1alpha3.DestinationRule{
-			ObjectMeta: createObjectMeta(fmt.Sprintf("%s.%s.svc.cluster.local", remote.ServiceName(), "istio-system")),
-			Spec: istionetv1alpha3.DestinationRule{
-				Host: remote.ServiceFQDN(),
-				TrafficPolicy: &istionetv1alpha3.TrafficPolicy{
-					Tls: &istionetv1alpha3.ClientTLSSettings{
-						Mode: istionetv1alpha3.ClientTLSSettings_ISTIO_MUTUAL,
-						Sni:  routerCompatibleSNI(remote.ServiceName(), "istio-system", remote.ServicePort()),
+	// Remotes requiring router compatible SNI are processed first, so that their services are always created with SNIs
+	remotes := append(
+		slices.Filter(cf.cfg.MeshPeers.Remotes, func(r config.Remote) bool { return r.RequiresRouterCompatibleSNI() }),
+		slices.Filter(cf.cfg.MeshPeers.Remotes, func(r config.Remote) bool { return !r.RequiresRouterCompatibleSNI() })...,
+	)
+
+	for _, remote := range remotes {
+		if remote.RequiresRouterCompatibleSNI() {
+			destinationRules = append(destinationRules, &networkingv1.DestinationRule{
+				ObjectMeta: createObjectMeta("mtls-sni", remote.ServiceFQDN()),
+				Spec: istionetv1.DestinationRule{
+					Host: remote.ServiceFQDN(),
+					TrafficPolicy: &istionetv1.TrafficPolicy{
+						Tls: &istionetv1.ClientTLSSettings{
+							Mode: istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+							Sni:  common.RouterCompatibleSNI(remote.ServiceName(), remote.ServiceNamespace(), remote.ServicePort()),
+						},
 					},
 				},
-			},
-		})
+			})
+		}
 
 		for _, svc := range cf.importedServiceStore.From(remote) {
+			subjectAltNames := subjectAltNames(remote, svc)
+			if len(subjectAltNames) == 0 {
+				unverifiedHosts.Insert(svc.GetHostname())
+			}
 			// Currently it's assumed that the same service (name+ns) exported by multiple remotes
-			// is configured exactly the same, therefore we create DestinationRule only once.
-			drMeta := createObjectMeta(svc.GetHostname())
-			if !destinationRulesAlreadyCreated[drMeta.Name] {
-				dr := &v1alpha3.DestinationRule{
-					ObjectMeta: drMeta,
-					Spec: istionetv1alpha3.DestinationRule{
+			// is configured exactly the same, therefore we create DestinationRule only once
+			// and only merge identities accepted for the service.
+			if dr, found := destinationRulesByHost[svc.GetHostname()]; found {
+				if remote.RequiresRouterCompatibleSNI() {
+					cf.log.Warnf("Destination rule %s already created (requesting peer %v)", dr.Name, remote)
+				}
+				mergeSubjectAltNames(dr, subjectAltNames)
+				continue
+			}
+
+			var dr *networkingv1.DestinationRule
+			switch {
+			case remote.RequiresRouterCompatibleSNI():
+				dr = &networkingv1.DestinationRule{
+					ObjectMeta: createObjectMeta("mtls-sni", svc.GetHostname()),
+					Spec: istionetv1.DestinationRule{
 						Host: svc.GetHostname(),
-						TrafficPolicy: &istionetv1alpha3.TrafficPolicy{
-							PortLevelSettings: []*istionetv1alpha3.TrafficPolicy_PortTrafficPolicy{},
+						TrafficPolicy: &istionetv1.TrafficPolicy{
+							PortLevelSettings: []*istionetv1.TrafficPolicy_PortTrafficPolicy{},
 						},
 					},
 				}
+				svcName, svcNs := getServiceNameAndNs(svc.GetHostname())
 				for _, port := range svc.Ports {
-					svcName, svcNs := getServiceNameAndNs(svc.GetHostname())
-					dr.Spec.TrafficPolicy.PortLevelSettings = append(dr.Spec.TrafficPolicy.PortLevelSettings, &istionetv1alpha3.TrafficPolicy_PortTrafficPolicy{
-						Port: &istionetv1alpha3.PortSelector{Number: port.Number},
-						Tls: &istionetv1alpha3.ClientTLSSettings{
-							Mode: istionetv1alpha3.ClientTLSSettings_ISTIO_MUTUAL,
-							Sni:  routerCompatibleSNI(svcName, svcNs, port.Number),
+					dr.Spec.TrafficPolicy.PortLevelSettings = append(dr.Spec.TrafficPolicy.PortLevelSettings, &istionetv1.TrafficPolicy_PortTrafficPolicy{
+						Port: &istionetv1.PortSelector{Number: port.Number},
+						Tls: &istionetv1.ClientTLSSettings{
+							Mode:            istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+							Sni:             common.RouterCompatibleSNI(svcName, svcNs, port.Number),
+							SubjectAltNames: subjectAltNames,
 						},
 					})
 				}
-				destinationRules = append(destinationRules, dr)
-			} else {
-				cf.log.Warnf("Destination rule %s already created (requesting peer %v)", drMeta.Name, remote)
+			case len(subjectAltNames) > 0:
+				dr = &networkingv1.DestinationRule{
+					ObjectMeta: createObjectMeta("mtls", svc.GetHostname()),
+					Spec: istionetv1.DestinationRule{
+						Host: svc.GetHostname(),
+						TrafficPolicy: &istionetv1.TrafficPolicy{
+							Tls: &istionetv1.ClientTLSSettings{
+								Mode:            istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+								SubjectAltNames: subjectAltNames,
+							},
+						},
+					},
+				}
+			default:
+				// Default Istio mTLS settings are sufficient
+				continue
 			}
+			destinationRulesByHost[svc.GetHostname()] = dr
+			destinationRules = append(destinationRules, dr)
 		}
 	}
 
+	for host := range unverifiedHosts {
+		if dr, found := destinationRulesByHost[host]; found {
+			clearSubjectAltNames(dr)
+		}
+	}
 	return destinationRules
 }
 
-func (cf *ConfigFactory) IngressGateway() (*v1alpha3.Gateway, error) {
-	gateway := &v1alpha3.Gateway{
+// subjectAltNames returns SPIFFE identities of workloads backing the imported service in the trust domain configured
+// for the remote, or nil if the remote did not export service accounts of the service.
+func subjectAltNames(remote config.Remote, svc *v1alpha1.FederatedService) []string {
+	if len(svc.GetServiceAccounts()) == 0 {
+		return nil
+	}
+	_, svcNs := getServiceNameAndNs(svc.GetHostname())
+	var identities []string
+	for _, sa := range svc.GetServiceAccounts() {
+		identities = append(identities, common.SpiffeIdentity(remote.GetTrustDomain(), svcNs, sa))
+	}
+	return identities
+}
+
+// mergeSubjectAltNames extends identities accepted for a service imported from multiple remotes.
+func mergeSubjectAltNames(dr *networkingv1.DestinationRule, subjectAltNames []string) {
+	merge := func(tls *istionetv1.ClientTLSSettings) {
+		if tls == nil || len(subjectAltNames) == 0 {
+			return
+		}
+		tls.SubjectAltNames = sets.SortedList(sets.New(append(tls.SubjectAltNames, subjectAltNames...)...))
+	}
+	merge(dr.Spec.TrafficPolicy.Tls)
+	for _, portSettings := range dr.Spec.TrafficPolicy.PortLevelSettings {
+		merge(portSettings.Tls)
+	}
+}
+
+// clearSubjectAltNames removes identities accepted for a service imported from a remote that does not export
+// service accounts, because its workloads could not be verified.
+func clearSubjectAltNames(dr *networkingv1.DestinationRule) {
+	if dr.Spec.TrafficPolicy.Tls != nil {
+		dr.Spec.TrafficPolicy.Tls.SubjectAltNames = nil
+	}
+	for _, portSettings := range dr.Spec.TrafficPolicy.PortLevelSettings {
+		if portSettings.Tls != nil {
+			portSettings.Tls.SubjectAltNames = nil
+		}
+	}
+}
+
+func (cf *ConfigFactory) IngressGateway() (*networkingv1.Gateway, error) {
+	gateway := &networkingv1.Gateway{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      federationIngressGatewayName,
+			Name:      cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName(),
 			Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
-			Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+			Labels:    cf.cfg.PeerLabels(),
 		},
-		Spec: istionetv1alpha3.Gateway{
+		Spec: istionetv1.Gateway{
 			Selector: cf.cfg.MeshPeers.Local.Gateways.Ingress.Selector,
-			Servers: []*istionetv1alpha3.Server{{
+			Servers: []*istionetv1.Server{{
 				Hosts: []string{},
-				Port: &istionetv1alpha3.Port{
+				Port: &istionetv1.Port{
 					Number:   cf.cfg.MeshPeers.Local.Gateways.Ingress.Port.Number,
 					Name:     cf.cfg.MeshPeers.Local.Gateways.Ingress.Port.Name,
 					Protocol: "TLS",
 				},
-				Tls: &istionetv1alpha3.ServerTLSSettings{
-					Mode: istionetv1alpha3.ServerTLSSettings_AUTO_PASSTHROUGH,
+				Tls: &istionetv1.ServerTLSSettings{
+					Mode: istionetv1.ServerTLSSettings_AUTO_PASSTHROUGH,
 				},
 			}},
 		},
 	}
 
-	hosts := []string{fmt.Sprintf("federation-discovery-service-%s.%s.svc.cluster.local", cf.cfg.MeshPeers.Local.Name, cf.namespace)}
-	for _, exportLabelSelector := range cf.cfg.ExportedServiceSet.GetLabelSelectors() {
-		matchLabels := labels.SelectorFromSet(exportLabelSelector.MatchLabels)
-		services, err := cf.serviceLister.List(matchLabels)
-		if err != nil {
-			return nil, fmt.Errorf("error listing services (selector=%s): %w", matchLabels, err)
+	hosts := []string{fmt.Sprintf("%s.%s.svc.cluster.local", cf.cfg.DiscoveryServiceName(), cf.cfg.DiscoveryServiceNamespace())}
+	services, err := cf.exportedServiceLister.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services {
+		if common.CheckExportable(svc) != nil {
+			continue
 		}
-		for _, svc := range services {
-			hosts = append(hosts, fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace))
+		hosts = append(hosts, fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace))
+		// Instances of headless services are exposed individually to allow per-instance SNI routing.
+		instanceHostnames, err := cf.instanceHostnames(svc)
+		if err != nil {
+			return nil, err
 		}
+		hosts = append(hosts, instanceHostnames...)
 	}
-	// ServiceLister.List is not idempotent, so to avoid redundant XDS push from Istio to proxies,
-	// we must return hostnames in the same order.
+	// To avoid redundant XDS push from Istio to proxies, we must return hostnames in the same order.
 	sort.Strings(hosts)
 	gateway.Spec.Servers[0].Hosts = hosts
 
@@ -191,9 +297,9 @@ func (cf *ConfigFactory) EnvoyFilters() []*v1alpha3.EnvoyFilter {
 		}
 		return &v1alpha3.EnvoyFilter{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("sni-%s-%s-%d", svcName, svcNamespace, port),
+				Name:      fmt.Sprintf("sni-%s-%s-%d-to-%s", svcName, svcNamespace, port, cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName()),
 				Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
-				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+				Labels:    cf.cfg.PeerLabels(),
 			},
 			Spec: istionetv1alpha3.EnvoyFilter{
 				WorkloadSelector: &istionetv1alpha3.WorkloadSelector{
@@ -213,7 +319,7 @@ func (cf *ConfigFactory) EnvoyFilters() []*v1alpha3.EnvoyFilter {
 					},
 					Patch: &istionetv1alpha3.EnvoyFilter_Patch{
 						Operation: istionetv1alpha3.EnvoyFilter_Patch_MERGE,
-						Value:     buildPatchStruct(fmt.Sprintf(`{"filter_chain_match":{"server_names":["%s"]}}`, routerCompatibleSNI(svcName, svcNamespace, uint32(port)))),
+						Value:     buildPatchStruct(fmt.Sprintf(`{"filter_chain_match":{"server_names":["%s"]}}`, common.RouterCompatibleSNI(svcName, svcNamespace, uint32(port)))),
 					},
 				}},
 			},
@@ -221,27 +327,140 @@ func (cf *ConfigFactory) EnvoyFilters() []*v1alpha3.EnvoyFilter {
 	}
 
 	envoyFilters := []*v1alpha3.EnvoyFilter{
-		createEnvoyFilter(fmt.Sprintf("federation-discovery-service-%s", cf.cfg.MeshPeers.Local.Name), "istio-system", 15080),
+		createEnvoyFilter(cf.cfg.DiscoveryServiceName(), cf.cfg.DiscoveryServiceNamespace(), int32(cf.cfg.DiscoveryServicePort())),
 	}
-	for _, exportLabelSelector := range cf.cfg.ExportedServiceSet.GetLabelSelectors() {
-		matchLabels := labels.SelectorFromSet(exportLabelSelector.MatchLabels)
-		services, err := cf.serviceLister.List(matchLabels)
-		if err != nil {
-			fmt.Printf("error listing services (selector=%s): %v", matchLabels, err)
+	services, err := cf.exportedServiceLister.List()
+	if err != nil {
+		cf.log.Errorf("error listing exported services: %v", err)
+	}
+	for _, svc := range services {
+		if common.CheckExportable(svc) != nil {
+			continue
 		}
-		for _, svc := range services {
-			for _, port := range svc.Spec.Ports {
-				envoyFilters = append(envoyFilters, createEnvoyFilter(svc.Name, svc.Namespace, port.Port))
-			}
+		for _, port := range svc.Spec.Ports {
+			envoyFilters = append(envoyFilters, createEnvoyFilter(svc.Name, svc.Namespace, port.Port))
 		}
 	}
 	return envoyFilters
 }
 
-func (cf *ConfigFactory) ServiceEntries() ([]*v1alpha3.ServiceEntry, error) {
+// AuthorizationPolicies returns a policy allowing requests to the discovery service only from federation controllers
+// of the configured remotes, and policies rendered from templates for matching exported services.
+func (cf *ConfigFactory) AuthorizationPolicies() ([]*securityv1.AuthorizationPolicy, error) {
+	policies := []*securityv1.AuthorizationPolicy{cf.discoveryAuthorizationPolicy()}
+	templates := cf.cfg.ExportedServiceSet.AuthorizationPolicies
+	if len(templates) == 0 {
+		return policies, nil
+	}
 
-	var serviceEntries []*v1alpha3.ServiceEntry
-	serviceEntriesByName := make(map[string]*v1alpha3.ServiceEntry, len(cf.cfg.MeshPeers.Remotes))
+	services, err := cf.exportedServiceLister.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services {
+		if common.CheckExportable(svc) != nil || len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		principals := sets.New[string]()
+		for _, template := range templates {
+			if !common.MatchExportRules(svc, template.LabelSelectors) {
+				continue
+			}
+			principals.InsertAll(template.Principals...)
+			for _, trustDomain := range template.TrustDomains {
+				principals.Insert(trustDomain + "/*")
+			}
+			for _, name := range template.Remotes {
+				remote := slices.FindFunc(cf.cfg.MeshPeers.Remotes, func(r config.Remote) bool {
+					return r.Name == name
+				})
+				if remote == nil {
+					cf.log.Warnf("Skipping unknown remote %s in authorization policy template for %s/%s", name, svc.Namespace, svc.Name)
+					continue
+				}
+				principals.Insert(remote.GetTrustDomain() + "/*")
+			}
+		}
+		if principals.IsEmpty() {
+			continue
+		}
+		// Local clients are always allowed, so that the policy only restricts traffic crossing the mesh boundary
+		principals.Insert(cf.cfg.MeshPeers.Local.GetTrustDomain() + "/*")
+
+		policies = append(policies, &securityv1.AuthorizationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("federation-boundary-%s-%s", cf.cfg.MeshPeers.Local.Name, svc.Name),
+				Namespace: svc.Namespace,
+				Labels:    cf.cfg.PeerLabels(),
+			},
+			Spec: istiosecurityv1.AuthorizationPolicy{
+				Selector: &istiotypev1beta1.WorkloadSelector{
+					MatchLabels: svc.Spec.Selector,
+				},
+				Action: istiosecurityv1.AuthorizationPolicy_ALLOW,
+				Rules: []*istiosecurityv1.Rule{{
+					From: []*istiosecurityv1.Rule_From{{
+						Source: &istiosecurityv1.Source{Principals: sets.SortedList(principals)},
+					}},
+				}},
+			},
+		})
+	}
+	return policies, nil
+}
+
+// discoveryAuthorizationPolicy allows requests to the discovery service only from federation controllers of the configured remotes.
+// Other ports of the controller, e.g. metrics, are not restricted.
+func (cf *ConfigFactory) discoveryAuthorizationPolicy() *securityv1.AuthorizationPolicy {
+	principalSet := sets.New[string]()
+	for _, remote := range cf.cfg.MeshPeers.Remotes {
+		principalSet.Insert(remote.Principal())
+	}
+	principals := sets.SortedList(principalSet)
+
+	discoveryPort := fmt.Sprintf("%d", cf.cfg.DiscoveryServicePort())
+	var rules []*istiosecurityv1.Rule
+	if len(principals) > 0 {
+		rules = append(rules, &istiosecurityv1.Rule{
+			From: []*istiosecurityv1.Rule_From{{
+				Source: &istiosecurityv1.Source{Principals: principals},
+			}},
+			To: []*istiosecurityv1.Rule_To{{
+				Operation: &istiosecurityv1.Operation{Ports: []string{discoveryPort}},
+			}},
+		})
+	}
+	rules = append(rules, &istiosecurityv1.Rule{
+		To: []*istiosecurityv1.Rule_To{{
+			Operation: &istiosecurityv1.Operation{NotPorts: []string{discoveryPort}},
+		}},
+	})
+
+	return &securityv1.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fds-allow-remote-peers",
+			Namespace: cf.namespace,
+			Labels:    cf.cfg.PeerLabels(),
+		},
+		Spec: istiosecurityv1.AuthorizationPolicy{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: map[string]string{
+					"app.kubernetes.io/name": "federation-controller",
+				},
+			},
+			Action: istiosecurityv1.AuthorizationPolicy_ALLOW,
+			Rules:  rules,
+		},
+	}
+}
+
+func (cf *ConfigFactory) ServiceEntries() ([]*networkingv1.ServiceEntry, error) {
+
+	serviceEntries, err := cf.serviceEntriesForExportedInstances()
+	if err != nil {
+		return nil, err
+	}
+	serviceEntriesByName := make(map[string]*networkingv1.ServiceEntry, len(cf.cfg.MeshPeers.Remotes))
 
 	for _, remote := range cf.cfg.MeshPeers.Remotes {
 		if len(remote.Addresses) == 0 {
@@ -250,12 +469,7 @@ func (cf *ConfigFactory) ServiceEntries() ([]*v1alpha3.ServiceEntry, error) {
 
 		serviceEntries = append(serviceEntries, cf.serviceEntryForRemoteFederationController(remote))
 
-		var resolution istionetv1alpha3.ServiceEntry_Resolution
-		if networking.IsIP(remote.Addresses[0]) {
-			resolution = istionetv1alpha3.ServiceEntry_STATIC
-		} else {
-			resolution = istionetv1alpha3.ServiceEntry_DNS
-		}
+		addresses, resolution := cf.remoteAddresses(remote)
 
 		for _, importedSvc := range cf.importedServiceStore.From(remote) {
 			svcName, svcNs := getServiceNameAndNs(importedSvc.GetHostname())
@@ -268,9 +482,9 @@ func (cf *ConfigFactory) ServiceEntries() ([]*v1alpha3.ServiceEntry, error) {
 
 				// TODO(multi-peer) handle naming clash & different resolution strategy
 				// https://github.com/openshift-service-mesh/federation/issues/123
-				var ports []*istionetv1alpha3.ServicePort
+				var ports []*istionetv1.ServicePort
 				for _, port := range importedSvc.Ports {
-					ports = append(ports, &istionetv1alpha3.ServicePort{
+					ports = append(ports, &istionetv1.ServicePort{
 						Name:       port.Name,
 						Number:     port.Number,
 						Protocol:   port.Protocol,
@@ -278,39 +492,48 @@ func (cf *ConfigFactory) ServiceEntries() ([]*v1alpha3.ServiceEntry, error) {
 					})
 				}
 
-				endpoints := slices.Map(remote.Addresses, func(addr string) *istionetv1alpha3.WorkloadEntry {
-					return &istionetv1alpha3.WorkloadEntry{
-						Address: addr,
-						Labels:  maps.MergeCopy(importedSvc.Labels, map[string]string{"security.istio.io/tlsMode": "istio"}),
-						Ports:   makePortsMap(importedSvc.Ports, remote.GetPort()),
-						Network: remote.Network,
-					}
-				})
-
-				svcEntryName := fmt.Sprintf("import-%s-%s", separateWithDash(importedSvc.GetHostname()), remote.Name)
-				serviceEntry, exists := serviceEntriesByName[svcEntryName]
-				if exists {
-					// If the ServiceEntry already exists due to multiple remotes exporting the same service,
-					// append endpoints to ensure all remotes are reachable under the shared host.
-					serviceEntry.Spec.Endpoints = append(serviceEntry.Spec.Endpoints, endpoints...)
+				var endpoints []*istionetv1.WorkloadEntry
+				if isHealthy(importedSvc) {
+					endpoints = slices.Map(addresses, func(addr string) *istionetv1.WorkloadEntry {
+						return &istionetv1.WorkloadEntry{
+							Address: addr,
+							Labels:  endpointLabels(remote, importedSvc.Labels),
+							Ports:   endpointPorts(remote, importedSvc.Ports),
+							Network: remote.Network,
+						}
+					})
 				} else {
-					serviceEntry = &v1alpha3.ServiceEntry{
-						ObjectMeta: metav1.ObjectMeta{
-							Name:      svcEntryName,
-							Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
-							Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-						},
-						Spec: istionetv1alpha3.ServiceEntry{
-							Hosts:      []string{importedSvc.GetHostname()},
-							Ports:      ports,
-							Endpoints:  endpoints,
-							Location:   istionetv1alpha3.ServiceEntry_MESH_INTERNAL,
-							Resolution: resolution,
-						},
-					}
+					cf.log.Infof("Skipping endpoints of %s from remote %s, because it has no ready endpoints", importedSvc.GetHostname(), remote.Name)
 				}
 
-				serviceEntriesByName[svcEntryName] = serviceEntry
+				// Instances of headless services get their own ServiceEntries, so that clients can address them individually,
+				// e.g. kafka-0.kafka.ns.svc.cluster.local, and the remote ingress gateway can route them by SNI.
+				for _, hostname := range append([]string{importedSvc.GetHostname()}, importedSvc.GetInstanceHostnames()...) {
+					svcEntryName := fmt.Sprintf("import-%s-%s", separateWithDash(hostname), remote.Name)
+					serviceEntry, exists := serviceEntriesByName[svcEntryName]
+					if exists {
+						// If the ServiceEntry already exists due to multiple remotes exporting the same service,
+						// append endpoints to ensure all remotes are reachable under the shared host.
+						serviceEntry.Spec.Endpoints = append(serviceEntry.Spec.Endpoints, endpoints...)
+					} else {
+						serviceEntry = &networkingv1.ServiceEntry{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      svcEntryName,
+								Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
+								Labels:    cf.cfg.PeerLabels(),
+							},
+							Spec: istionetv1.ServiceEntry{
+								Hosts:      []string{hostname},
+								Ports:      ports,
+								Endpoints:  endpoints,
+								Location:   istionetv1.ServiceEntry_MESH_INTERNAL,
+								Resolution: resolution,
+							},
+						}
+					}
+
+					serviceEntriesByName[svcEntryName] = serviceEntry
+				}
 			}
 		}
 	}
@@ -320,8 +543,8 @@ func (cf *ConfigFactory) ServiceEntries() ([]*v1alpha3.ServiceEntry, error) {
 	return serviceEntries, nil
 }
 
-func (cf *ConfigFactory) WorkloadEntries() ([]*v1alpha3.WorkloadEntry, error) {
-	var workloadEntries []*v1alpha3.WorkloadEntry
+func (cf *ConfigFactory) WorkloadEntries() ([]*networkingv1.WorkloadEntry, error) {
+	var workloadEntries []*networkingv1.WorkloadEntry
 
 	for _, remote := range cf.cfg.MeshPeers.Remotes {
 		for _, importedSvc := range cf.importedServiceStore.From(remote) {
@@ -332,18 +555,22 @@ func (cf *ConfigFactory) WorkloadEntries() ([]*v1alpha3.WorkloadEntry, error) {
 					return nil, fmt.Errorf("failed to get Service %s/%s: %w", svcNs, svcName, err)
 				}
 			} else {
+				if !isHealthy(importedSvc) {
+					cf.log.Infof("Skipping workload entries for %s from remote %s, because it has no ready endpoints", importedSvc.GetHostname(), remote.Name)
+					continue
+				}
 				// Service already exists - create WorkloadEntries.
-				for idx, ip := range networking.Resolve(remote.Addresses...) {
-					workloadEntries = append(workloadEntries, &v1alpha3.WorkloadEntry{
+				for idx, ip := range cf.healthyAddresses(remote, remote.FilterIPs(cf.addressCache.Resolve(remote.Addresses...))) {
+					workloadEntries = append(workloadEntries, &networkingv1.WorkloadEntry{
 						ObjectMeta: metav1.ObjectMeta{
 							Name:      fmt.Sprintf("import-%s-%s-%d", remote.Name, svcName, idx),
 							Namespace: svcNs,
-							Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+							Labels:    cf.cfg.PeerLabels(),
 						},
-						Spec: istionetv1alpha3.WorkloadEntry{
+						Spec: istionetv1.WorkloadEntry{
 							Address: ip,
-							Labels:  maps.MergeCopy(importedSvc.Labels, map[string]string{"security.istio.io/tlsMode": "istio"}),
-							Ports:   makePortsMap(importedSvc.Ports, remote.GetPort()),
+							Labels:  endpointLabels(remote, importedSvc.Labels),
+							Ports:   endpointPorts(remote, importedSvc.Ports),
 							Network: remote.Network,
 						},
 					})
@@ -354,42 +581,154 @@ func (cf *ConfigFactory) WorkloadEntries() ([]*v1alpha3.WorkloadEntry, error) {
 	return workloadEntries, nil
 }
 
-func (cf *ConfigFactory) serviceEntryForRemoteFederationController(remote config.Remote) *v1alpha3.ServiceEntry {
-	se := &v1alpha3.ServiceEntry{
+// serviceEntriesForExportedInstances returns ServiceEntries for instances of exported headless services.
+// Istio does not create clusters for hostnames of individual pods, so these ServiceEntries are necessary
+// to let the auto-passthrough ingress gateway route SNIs like outbound_.9092_._.kafka-0.kafka.ns.svc.cluster.local.
+// Endpoints are resolved by DNS, because pod hostnames are resolvable within the cluster.
+func (cf *ConfigFactory) serviceEntriesForExportedInstances() ([]*networkingv1.ServiceEntry, error) {
+	services, err := cf.exportedServiceLister.List()
+	if err != nil {
+		return nil, err
+	}
+	var serviceEntries []*networkingv1.ServiceEntry
+	for _, svc := range services {
+		if common.CheckExportable(svc) != nil {
+			continue
+		}
+		instanceHostnames, err := cf.instanceHostnames(svc)
+		if err != nil {
+			return nil, err
+		}
+		var ports []*istionetv1.ServicePort
+		for _, port := range svc.Spec.Ports {
+			protocol := common.DetectProtocol(port)
+			if protocol == istioprotocol.UDP {
+				continue
+			}
+			ports = append(ports, &istionetv1.ServicePort{
+				Name:     port.Name,
+				Number:   uint32(port.Port),
+				Protocol: strings.ToUpper(string(protocol)),
+			})
+		}
+		for _, hostname := range instanceHostnames {
+			serviceEntries = append(serviceEntries, &networkingv1.ServiceEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("export-%s-%s", cf.cfg.MeshPeers.Local.Name, separateWithDash(hostname)),
+					Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
+					Labels:    cf.cfg.PeerLabels(),
+				},
+				Spec: istionetv1.ServiceEntry{
+					Hosts:      []string{hostname},
+					Ports:      ports,
+					Location:   istionetv1.ServiceEntry_MESH_INTERNAL,
+					Resolution: istionetv1.ServiceEntry_DNS,
+				},
+			})
+		}
+	}
+	return serviceEntries, nil
+}
+
+func (cf *ConfigFactory) instanceHostnames(svc *corev1.Service) ([]string, error) {
+	if !common.IsHeadless(svc) {
+		return nil, nil
+	}
+	endpointSlices, err := cf.endpointSliceLister.EndpointSlices(svc.Namespace).List(
+		labels.SelectorFromSet(map[string]string{discoveryv1.LabelServiceName: svc.Name}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices for service %s/%s: %w", svc.Namespace, svc.Name, err)
+	}
+	return common.InstanceHostnames(svc, endpointSlices), nil
+}
+
+func (cf *ConfigFactory) serviceEntryForRemoteFederationController(remote config.Remote) *networkingv1.ServiceEntry {
+	addresses, resolution := cf.remoteAddresses(remote)
+	ports := map[string]uint32{"grpc": remote.GetPort()}
+	if remote.IsAmbient() {
+		// HBONE tunnels always terminate on port 15008, so the target port of the discovery service must not be overridden
+		ports = nil
+	}
+	return &networkingv1.ServiceEntry{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      remote.ServiceName(),
 			Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
-			Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+			Labels:    cf.cfg.PeerLabels(),
 		},
-		Spec: istionetv1alpha3.ServiceEntry{
+		Spec: istionetv1.ServiceEntry{
 			Hosts: []string{remote.ServiceFQDN()},
-			Ports: []*istionetv1alpha3.ServicePort{{
+			Ports: []*istionetv1.ServicePort{{
 				Name:     "grpc",
 				Number:   remote.ServicePort(),
 				Protocol: "GRPC",
 			}},
-			Endpoints: slices.Map(remote.Addresses, func(addr string) *istionetv1alpha3.WorkloadEntry {
-				return &istionetv1alpha3.WorkloadEntry{
+			Endpoints: slices.Map(addresses, func(addr string) *istionetv1.WorkloadEntry {
+				return &istionetv1.WorkloadEntry{
 					Address: addr,
-					Labels:  map[string]string{"security.istio.io/tlsMode": "istio"},
-					Ports:   map[string]uint32{"grpc": remote.GetPort()},
+					Labels:  endpointLabels(remote, nil),
+					Ports:   ports,
 					Network: remote.Network,
 				}
 			}),
-			Location: istionetv1alpha3.ServiceEntry_MESH_INTERNAL,
+			Location:   istionetv1.ServiceEntry_MESH_INTERNAL,
+			Resolution: resolution,
 		},
 	}
+}
+
+// remoteAddresses returns addresses of the remote ingress and their resolution type. IP addresses are ordered
+// by the IP family preference of the remote, and every address becomes a separate endpoint.
+// Hostnames are resolved by the controller when ztunnel cannot resolve them, or when IP families of the remote
+// are configured explicitly, because proxies resolve hostnames of DNS endpoints regardless of the remote preference.
+// Resolved addresses are served from the address cache, which follows TTLs of the DNS records.
+// Addresses reported as unreachable by the gateway prober are omitted.
+func (cf *ConfigFactory) remoteAddresses(remote config.Remote) ([]string, istionetv1.ServiceEntry_Resolution) {
 	if networking.IsIP(remote.Addresses[0]) {
-		se.Spec.Resolution = istionetv1alpha3.ServiceEntry_STATIC
-	} else {
-		se.Spec.Resolution = istionetv1alpha3.ServiceEntry_DNS
+		return cf.healthyAddresses(remote, remote.FilterIPs(remote.Addresses)), istionetv1.ServiceEntry_STATIC
+	}
+	if cf.cfg.MeshPeers.Local.IsAmbient() || remote.HasIPFamilyPreference() {
+		return cf.healthyAddresses(remote, remote.FilterIPs(cf.addressCache.Resolve(remote.Addresses...))), istionetv1.ServiceEntry_STATIC
 	}
-	return se
+	return cf.healthyAddresses(remote, remote.Addresses), istionetv1.ServiceEntry_DNS
 }
 
-// routerCompatibleSNI returns SNI compatible with https://datatracker.ietf.org/doc/html/rfc952 required by OpenShift Router.
-func routerCompatibleSNI(svcName, svcNs string, port uint32) string {
-	return fmt.Sprintf("%s-%d.%s.svc.cluster.local", svcName, port, svcNs)
+// healthyAddresses removes addresses of the remote ingress that failed the gateway probe. If no address is healthy,
+// all addresses are returned, because the probes may be blocked by the network while the gateway is still reachable
+// by proxies, and removing all endpoints would break traffic to the remote anyway.
+func (cf *ConfigFactory) healthyAddresses(remote config.Remote, addrs []string) []string {
+	healthy := slices.Filter(addrs, func(addr string) bool {
+		return cf.gatewayProber.IsHealthy(remote.Name, addr)
+	})
+	if len(healthy) == 0 {
+		return addrs
+	}
+	return healthy
+}
+
+// isHealthy returns false only if the exporting mesh reported that the service has no ready endpoints.
+// Services imported from controllers that do not report health are considered healthy.
+func isHealthy(svc *v1alpha1.FederatedService) bool {
+	return svc.GetHealth() != v1alpha1.HealthStatus_UNHEALTHY
+}
+
+// endpointLabels returns labels of endpoints representing the remote ingress.
+// Endpoints of ambient meshes are labeled with the tunnel label, so that sidecars and ztunnel reach them using HBONE.
+func endpointLabels(remote config.Remote, labels map[string]string) map[string]string {
+	out := maps.MergeCopy(labels, map[string]string{"security.istio.io/tlsMode": "istio"})
+	if remote.IsAmbient() {
+		out["networking.istio.io/tunnel"] = "http"
+	}
+	return out
+}
+
+// endpointPorts returns target ports of endpoints representing the remote ingress. Sidecar meshes route all ports
+// through the TLS passthrough port of the ingress gateway, while ambient meshes receive all traffic on the HBONE port,
+// and service ports are carried in the tunnel, so they must not be overridden.
+func endpointPorts(remote config.Remote, ports []*v1alpha1.ServicePort) map[string]uint32 {
+	if remote.IsAmbient() {
+		return nil
+	}
+	return makePortsMap(ports, remote.GetPort())
 }
 
 func makePortsMap(ports []*v1alpha1.ServicePort, remotePort uint32) map[string]uint32 {
@@ -21,20 +21,28 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
-	istionetv1alpha3 "istio.io/api/networking/v1alpha3"
-	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	istionetv1 "istio.io/api/networking/v1"
+	istiosecurityv1 "istio.io/api/security/v1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	networkingv1 "istio.io/client-go/pkg/apis/networking/v1"
+	securityv1 "istio.io/client-go/pkg/apis/security/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
 
 	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/config"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/fds"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/informer"
+	"github.com/openshift-service-mesh/federation/internal/pkg/networking"
 )
 
 var (
@@ -112,6 +120,42 @@ var (
 		},
 	}
 
+	svcKafka_ns1 = &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "kafka",
+			Namespace: "ns1",
+			Labels:    map[string]string{"app": "kafka"},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports:     []corev1.ServicePort{{Name: "tcp-kafka", Port: 9092}},
+		},
+	}
+	kafkaEndpointSlice = &discoveryv1.EndpointSlice{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "kafka-abcde",
+			Namespace: "ns1",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "kafka"},
+		},
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses: []string{"10.0.0.1"},
+			Hostname:  ptr.To("kafka-0"),
+		}, {
+			Addresses: []string{"10.0.0.2"},
+			Hostname:  ptr.To("kafka-1"),
+		}},
+	}
+	svcExternal_ns1 = &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "external",
+			Namespace: "ns1",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "db.example.com",
+		},
+	}
+
 	importedHttpPort = &v1alpha1.ServicePort{
 		Name:       "http",
 		Number:     80,
@@ -140,37 +184,78 @@ var (
 		Labels:   map[string]string{"app": "a"},
 		Ports:    []*v1alpha1.ServicePort{importedHttpPort},
 	}
+	importedSvcKafka_ns1 = &v1alpha1.FederatedService{
+		Hostname: "kafka.ns1.svc.cluster.local",
+		Labels:   map[string]string{"app": "kafka"},
+		Ports: []*v1alpha1.ServicePort{{
+			Name:     "tcp-kafka",
+			Number:   9092,
+			Protocol: "TCP",
+		}},
+		InstanceHostnames: []string{"kafka-0.kafka.ns1.svc.cluster.local", "kafka-1.kafka.ns1.svc.cluster.local"},
+	}
 )
 
 func TestIngressGateway(t *testing.T) {
 	testCases := []struct {
-		name            string
-		localServices   []*corev1.Service
-		expectedGateway *v1alpha3.Gateway
+		name                string
+		localServices       []*corev1.Service
+		localEndpointSlices []*discoveryv1.EndpointSlice
+		expectedGateway     *networkingv1.Gateway
 	}{{
 		name:          "federation-ingress-gateway should expose FDS and exported services",
 		localServices: []*corev1.Service{svcA_ns1, export(svcB_ns1), export(svcA_ns2)},
-		expectedGateway: &v1alpha3.Gateway{
+		expectedGateway: &networkingv1.Gateway{
 			ObjectMeta: v1.ObjectMeta{
 				Name:      "federation-ingress-gateway",
 				Namespace: "istio-system",
-				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
 			},
-			Spec: istionetv1alpha3.Gateway{
+			Spec: istionetv1.Gateway{
 				Selector: map[string]string{"app": "federation-ingress-gateway"},
-				Servers: []*istionetv1alpha3.Server{{
+				Servers: []*istionetv1.Server{{
 					Hosts: []string{
 						"a.ns2.svc.cluster.local",
 						"b.ns1.svc.cluster.local",
 						"federation-discovery-service-east.istio-system.svc.cluster.local",
 					},
-					Port: &istionetv1alpha3.Port{
+					Port: &istionetv1.Port{
+						Number:   443,
+						Name:     "tls",
+						Protocol: "TLS",
+					},
+					Tls: &istionetv1.ServerTLSSettings{
+						Mode: istionetv1.ServerTLSSettings_AUTO_PASSTHROUGH,
+					},
+				}},
+			},
+		},
+	}, {
+		name:                "federation-ingress-gateway should expose instances of headless services and skip ExternalName services",
+		localServices:       []*corev1.Service{export(svcKafka_ns1), export(svcExternal_ns1)},
+		localEndpointSlices: []*discoveryv1.EndpointSlice{kafkaEndpointSlice},
+		expectedGateway: &networkingv1.Gateway{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "federation-ingress-gateway",
+				Namespace: "istio-system",
+				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
+			},
+			Spec: istionetv1.Gateway{
+				Selector: map[string]string{"app": "federation-ingress-gateway"},
+				Servers: []*istionetv1.Server{{
+					Hosts: []string{
+						"federation-discovery-service-east.istio-system.svc.cluster.local",
+						"kafka-0.kafka.ns1.svc.cluster.local",
+						"kafka-1.kafka.ns1.svc.cluster.local",
+						"kafka.ns1.svc.cluster.local",
+					},
+					Port: &istionetv1.Port{
 						Number:   443,
 						Name:     "tls",
 						Protocol: "TLS",
 					},
-					Tls: &istionetv1alpha3.ServerTLSSettings{
-						Mode: istionetv1alpha3.ServerTLSSettings_AUTO_PASSTHROUGH,
+					Tls: &istionetv1.ServerTLSSettings{
+						Mode: istionetv1.ServerTLSSettings_AUTO_PASSTHROUGH,
 					},
 				}},
 			},
@@ -178,25 +263,25 @@ func TestIngressGateway(t *testing.T) {
 	}, {
 		name:          "federation-ingress-gateway should always expose FDS",
 		localServices: []*corev1.Service{},
-		expectedGateway: &v1alpha3.Gateway{
+		expectedGateway: &networkingv1.Gateway{
 			ObjectMeta: v1.ObjectMeta{
 				Name:      "federation-ingress-gateway",
 				Namespace: "istio-system",
-				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
 			},
-			Spec: istionetv1alpha3.Gateway{
+			Spec: istionetv1.Gateway{
 				Selector: map[string]string{"app": "federation-ingress-gateway"},
-				Servers: []*istionetv1alpha3.Server{{
+				Servers: []*istionetv1.Server{{
 					Hosts: []string{
 						"federation-discovery-service-east.istio-system.svc.cluster.local",
 					},
-					Port: &istionetv1alpha3.Port{
+					Port: &istionetv1.Port{
 						Number:   443,
 						Name:     "tls",
 						Protocol: "TLS",
 					},
-					Tls: &istionetv1alpha3.ServerTLSSettings{
-						Mode: istionetv1alpha3.ServerTLSSettings_AUTO_PASSTHROUGH,
+					Tls: &istionetv1.ServerTLSSettings{
+						Mode: istionetv1.ServerTLSSettings_AUTO_PASSTHROUGH,
 					},
 				}},
 			},
@@ -208,6 +293,8 @@ func TestIngressGateway(t *testing.T) {
 			informerFactory := informers.NewSharedInformerFactory(client, 0)
 			serviceInformer := informerFactory.Core().V1().Services().Informer()
 			serviceLister := informerFactory.Core().V1().Services().Lister()
+			endpointSliceInformer := informerFactory.Discovery().V1().EndpointSlices().Informer()
+			endpointSliceLister := informerFactory.Discovery().V1().EndpointSlices().Lister()
 			stopCh := make(chan struct{})
 			informerFactory.Start(stopCh)
 
@@ -217,13 +304,25 @@ func TestIngressGateway(t *testing.T) {
 				}
 			}
 
+			for _, slice := range tc.localEndpointSlices {
+				if _, err := client.DiscoveryV1().EndpointSlices(slice.Namespace).Create(context.Background(), slice, v1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create endpoint slice %s/%s: %v", slice.Name, slice.Namespace, err)
+				}
+			}
+
 			serviceController, err := informer.NewResourceController(serviceInformer, corev1.Service{})
 			if err != nil {
 				t.Fatalf("error creating serviceController: %v", err)
 			}
 			serviceController.RunAndWait(stopCh)
 
-			factory := NewConfigFactory(exportConfig, serviceLister, fds.NewImportedServiceStore(), "istio-system")
+			endpointSliceController, err := informer.NewResourceController(endpointSliceInformer, discoveryv1.EndpointSlice{})
+			if err != nil {
+				t.Fatalf("error creating endpointSliceController: %v", err)
+			}
+			endpointSliceController.RunAndWait(stopCh)
+
+			factory := NewConfigFactory(exportConfig, serviceLister, common.NewExportedServiceLister(exportConfig, serviceLister, nil, nil), endpointSliceLister, fds.NewImportedServiceStore(), nil, nil, "istio-system")
 			actual, err := factory.IngressGateway()
 			if err != nil {
 				t.Errorf("got unexpected error: %s", err)
@@ -263,6 +362,8 @@ func TestEnvoyFilters(t *testing.T) {
 			informerFactory := informers.NewSharedInformerFactory(client, 0)
 			serviceInformer := informerFactory.Core().V1().Services().Informer()
 			serviceLister := informerFactory.Core().V1().Services().Lister()
+			endpointSliceInformer := informerFactory.Discovery().V1().EndpointSlices().Informer()
+			endpointSliceLister := informerFactory.Discovery().V1().EndpointSlices().Lister()
 			stopCh := make(chan struct{})
 			informerFactory.Start(stopCh)
 
@@ -278,16 +379,368 @@ func TestEnvoyFilters(t *testing.T) {
 			}
 			serviceController.RunAndWait(stopCh)
 
+			endpointSliceController, err := informer.NewResourceController(endpointSliceInformer, discoveryv1.EndpointSlice{})
+			if err != nil {
+				t.Fatalf("error creating endpointSliceController: %v", err)
+			}
+			endpointSliceController.RunAndWait(stopCh)
+
 			cfg := copyConfig(&exportConfig)
 			cfg.MeshPeers.Local.IngressType = tc.localIngressType
 
-			factory := NewConfigFactory(*cfg, serviceLister, fds.NewImportedServiceStore(), "istio-system")
+			factory := NewConfigFactory(*cfg, serviceLister, common.NewExportedServiceLister(*cfg, serviceLister, nil, nil), endpointSliceLister, fds.NewImportedServiceStore(), nil, nil, "istio-system")
 			envoyFilters := factory.EnvoyFilters()
 			compareResources(t, "envoy-filters", tc.expectedEnvoyFilterFiles, envoyFilters)
 		})
 	}
 }
 
+func TestAuthorizationPolicy(t *testing.T) {
+	cfgWithRemotes := copyConfig(&exportConfig)
+	cfgWithRemotes.MeshPeers.Remotes = []config.Remote{{
+		Name:      "west",
+		Addresses: []string{"1.1.1.1"},
+	}, {
+		Name:           "central",
+		Addresses:      []string{"2.2.2.2"},
+		TrustDomain:    "central.local",
+		ServiceAccount: "federation",
+	}, {
+		Name:      "north",
+		Addresses: []string{"3.3.3.3"},
+	}, {
+		Name:             "south",
+		Addresses:        []string{"4.4.4.4"},
+		DiscoveryService: config.DiscoveryService{Namespace: "federation-system"},
+	}}
+
+	allowOtherPorts := &istiosecurityv1.Rule{
+		To: []*istiosecurityv1.Rule_To{{
+			Operation: &istiosecurityv1.Operation{NotPorts: []string{"15080"}},
+		}},
+	}
+
+	testCases := []struct {
+		name          string
+		cfg           config.Federation
+		expectedRules []*istiosecurityv1.Rule
+	}{{
+		name:          "discovery service should not be accessible when no remotes are configured",
+		cfg:           exportConfig,
+		expectedRules: []*istiosecurityv1.Rule{allowOtherPorts},
+	}, {
+		name: "discovery service should be accessible only by unique principals of remote controllers",
+		cfg:  *cfgWithRemotes,
+		expectedRules: []*istiosecurityv1.Rule{{
+			From: []*istiosecurityv1.Rule_From{{
+				Source: &istiosecurityv1.Source{
+					Principals: []string{
+						"central.local/ns/istio-system/sa/federation",
+						"cluster.local/ns/federation-system/sa/federation-controller",
+						"cluster.local/ns/istio-system/sa/federation-controller",
+					},
+				},
+			}},
+			To: []*istiosecurityv1.Rule_To{{
+				Operation: &istiosecurityv1.Operation{Ports: []string{"15080"}},
+			}},
+		}, allowOtherPorts},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := NewConfigFactory(tc.cfg, nil, nil, nil, fds.NewImportedServiceStore(), nil, nil, "istio-system")
+			actual, err := factory.AuthorizationPolicies()
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+
+			expected := []*securityv1.AuthorizationPolicy{{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "fds-allow-remote-peers",
+					Namespace: "istio-system",
+					Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
+				},
+				Spec: istiosecurityv1.AuthorizationPolicy{
+					Selector: &istiotypev1beta1.WorkloadSelector{
+						MatchLabels: map[string]string{"app.kubernetes.io/name": "federation-controller"},
+					},
+					Action: istiosecurityv1.AuthorizationPolicy_ALLOW,
+					Rules:  tc.expectedRules,
+				},
+			}}
+			if toJSON(actual) != toJSON(expected) {
+				t.Errorf("got unexpected result:\nexpected:\n%s\ngot:\n%s", toJSON(expected), toJSON(actual))
+			}
+		})
+	}
+}
+
+func TestBoundaryAuthorizationPolicies(t *testing.T) {
+	withSelector := func(svc *corev1.Service) *corev1.Service {
+		out := svc.DeepCopy()
+		out.Spec.Selector = map[string]string{"app": svc.Name}
+		return out
+	}
+	exportedSvcA := withSelector(export(svcA_ns1))
+	exportedSvcB := withSelector(export(svcB_ns1))
+	exportedSvcB.Labels["tier"] = "backend"
+
+	cfg := copyConfig(&exportConfig)
+	cfg.MeshPeers.Local.TrustDomain = "east.local"
+	cfg.MeshPeers.Remotes = []config.Remote{{
+		Name:        "west",
+		Addresses:   []string{"1.1.1.1"},
+		TrustDomain: "west.local",
+	}}
+	cfg.ExportedServiceSet.AuthorizationPolicies = []config.AuthorizationPolicyTemplate{{
+		LabelSelectors: []config.LabelSelectors{{MatchLabels: map[string]string{"export": "true"}}},
+		Remotes:        []string{"west", "unknown"},
+	}, {
+		LabelSelectors: []config.LabelSelectors{{MatchLabels: map[string]string{"tier": "backend"}}},
+		TrustDomains:   []string{"central.local"},
+		Principals:     []string{"north.local/ns/ns1/sa/client"},
+	}}
+
+	testCases := []struct {
+		name             string
+		localServices    []*corev1.Service
+		expectedPolicies map[string][]string
+	}{{
+		name:             "no policies should be generated when no services are exported",
+		localServices:    []*corev1.Service{withSelector(svcA_ns1)},
+		expectedPolicies: map[string][]string{},
+	}, {
+		name:             "services without selector should be skipped",
+		localServices:    []*corev1.Service{export(svcA_ns2)},
+		expectedPolicies: map[string][]string{},
+	}, {
+		name:          "policies should allow local mesh and principals merged from all matching templates",
+		localServices: []*corev1.Service{exportedSvcA, exportedSvcB},
+		expectedPolicies: map[string][]string{
+			"ns1/federation-boundary-east-a": {"east.local/*", "west.local/*"},
+			"ns1/federation-boundary-east-b": {"central.local/*", "east.local/*", "north.local/ns/ns1/sa/client", "west.local/*"},
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			serviceInformer := informerFactory.Core().V1().Services().Informer()
+			serviceLister := informerFactory.Core().V1().Services().Lister()
+			stopCh := make(chan struct{})
+			informerFactory.Start(stopCh)
+
+			for _, svc := range tc.localServices {
+				if _, err := client.CoreV1().Services(svc.Namespace).Create(context.Background(), svc, v1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create service %s/%s: %v", svc.Name, svc.Namespace, err)
+				}
+			}
+
+			serviceController, err := informer.NewResourceController(serviceInformer, corev1.Service{})
+			if err != nil {
+				t.Fatalf("error creating serviceController: %v", err)
+			}
+			serviceController.RunAndWait(stopCh)
+
+			factory := NewConfigFactory(*cfg, serviceLister, common.NewExportedServiceLister(*cfg, serviceLister, nil, nil), nil, fds.NewImportedServiceStore(), nil, nil, "istio-system")
+			policies, err := factory.AuthorizationPolicies()
+			if err != nil {
+				t.Fatalf("got unexpected error: %s", err)
+			}
+
+			// The first policy protects the discovery service and is covered by TestAuthorizationPolicy
+			actual := map[string][]string{}
+			for _, policy := range policies[1:] {
+				if policy.Spec.Action != istiosecurityv1.AuthorizationPolicy_ALLOW {
+					t.Errorf("expected ALLOW action in %s/%s, got %s", policy.Namespace, policy.Name, policy.Spec.Action)
+				}
+				if policy.Spec.Selector.MatchLabels["app"] != policy.Name[len("federation-boundary-east-"):] {
+					t.Errorf("unexpected selector in %s/%s: %v", policy.Namespace, policy.Name, policy.Spec.Selector.MatchLabels)
+				}
+				actual[policy.Namespace+"/"+policy.Name] = policy.Spec.Rules[0].From[0].Source.Principals
+			}
+			if !reflect.DeepEqual(actual, tc.expectedPolicies) {
+				t.Errorf("got unexpected result:\nexpected:\n%v\ngot:\n%v", tc.expectedPolicies, actual)
+			}
+		})
+	}
+}
+
+func TestDestinationRules(t *testing.T) {
+	reviews := &v1alpha1.FederatedService{
+		Hostname:        "reviews.bookinfo.svc.cluster.local",
+		Ports:           []*v1alpha1.ServicePort{{Name: "http", Number: 9080, Protocol: "HTTP"}},
+		ServiceAccounts: []string{"reviews"},
+	}
+	reviewsWithoutServiceAccounts := &v1alpha1.FederatedService{
+		Hostname: "reviews.bookinfo.svc.cluster.local",
+		Ports:    []*v1alpha1.ServicePort{{Name: "http", Number: 9080, Protocol: "HTTP"}},
+	}
+	objectMeta := func(name string) v1.ObjectMeta {
+		return v1.ObjectMeta{
+			Name:      name,
+			Namespace: "istio-system",
+			Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
+		}
+	}
+	verifiedReviews := func(subjectAltNames ...string) *networkingv1.DestinationRule {
+		return &networkingv1.DestinationRule{
+			ObjectMeta: objectMeta("mtls-reviews-bookinfo-svc-cluster-local"),
+			Spec: istionetv1.DestinationRule{
+				Host: "reviews.bookinfo.svc.cluster.local",
+				TrafficPolicy: &istionetv1.TrafficPolicy{
+					Tls: &istionetv1.ClientTLSSettings{
+						Mode:            istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+						SubjectAltNames: subjectAltNames,
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                     string
+		remotes                  []config.Remote
+		importedServices         map[string][]*v1alpha1.FederatedService
+		expectedDestinationRules []*networkingv1.DestinationRule
+	}{{
+		name:    "destination rules should not be created for services without service accounts",
+		remotes: []config.Remote{{Name: "west", Addresses: []string{"1.1.1.1"}}},
+		importedServices: map[string][]*v1alpha1.FederatedService{
+			"west": {reviewsWithoutServiceAccounts},
+		},
+	}, {
+		name:    "subject alt names should be derived from the trust domain of the remote",
+		remotes: []config.Remote{{Name: "west", Addresses: []string{"1.1.1.1"}, TrustDomain: "west.local"}},
+		importedServices: map[string][]*v1alpha1.FederatedService{
+			"west": {reviews},
+		},
+		expectedDestinationRules: []*networkingv1.DestinationRule{
+			verifiedReviews("spiffe://west.local/ns/bookinfo/sa/reviews"),
+		},
+	}, {
+		name: "subject alt names should be merged for services imported from multiple remotes",
+		remotes: []config.Remote{
+			{Name: "west", Addresses: []string{"1.1.1.1"}, TrustDomain: "west.local"},
+			{Name: "central", Addresses: []string{"2.2.2.2"}, TrustDomain: "central.local"},
+		},
+		importedServices: map[string][]*v1alpha1.FederatedService{
+			"west":    {reviews},
+			"central": {reviews},
+		},
+		expectedDestinationRules: []*networkingv1.DestinationRule{
+			verifiedReviews("spiffe://central.local/ns/bookinfo/sa/reviews", "spiffe://west.local/ns/bookinfo/sa/reviews"),
+		},
+	}, {
+		name: "subject alt names should be removed if a later remote does not export service accounts",
+		remotes: []config.Remote{
+			{Name: "west", Addresses: []string{"1.1.1.1"}, TrustDomain: "west.local"},
+			{Name: "central", Addresses: []string{"2.2.2.2"}, TrustDomain: "central.local"},
+		},
+		importedServices: map[string][]*v1alpha1.FederatedService{
+			"west":    {reviews},
+			"central": {reviewsWithoutServiceAccounts},
+		},
+		expectedDestinationRules: []*networkingv1.DestinationRule{
+			verifiedReviews(),
+		},
+	}, {
+		name: "subject alt names should be removed if an earlier remote does not export service accounts",
+		remotes: []config.Remote{
+			{Name: "central", Addresses: []string{"2.2.2.2"}, TrustDomain: "central.local"},
+			{Name: "west", Addresses: []string{"1.1.1.1"}, TrustDomain: "west.local"},
+		},
+		importedServices: map[string][]*v1alpha1.FederatedService{
+			"central": {reviewsWithoutServiceAccounts},
+			"west":    {reviews},
+		},
+		expectedDestinationRules: []*networkingv1.DestinationRule{
+			verifiedReviews(),
+		},
+	}, {
+		name: "router compatible SNI should be configured together with subject alt names",
+		remotes: []config.Remote{
+			{Name: "west", Addresses: []string{"1.1.1.1"}, TrustDomain: "west.local"},
+			{Name: "central", Addresses: []string{"central.example.com"}, IngressType: config.GatewayAPI},
+		},
+		importedServices: map[string][]*v1alpha1.FederatedService{
+			"west":    {reviews},
+			"central": {reviews},
+		},
+		expectedDestinationRules: []*networkingv1.DestinationRule{{
+			ObjectMeta: objectMeta("mtls-sni-federation-discovery-service-central-istio-system-svc-cluster-local"),
+			Spec: istionetv1.DestinationRule{
+				Host: "federation-discovery-service-central.istio-system.svc.cluster.local",
+				TrafficPolicy: &istionetv1.TrafficPolicy{
+					Tls: &istionetv1.ClientTLSSettings{
+						Mode: istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+						Sni:  "federation-discovery-service-central-15080.istio-system.svc.cluster.local",
+					},
+				},
+			},
+		}, {
+			ObjectMeta: objectMeta("mtls-sni-reviews-bookinfo-svc-cluster-local"),
+			Spec: istionetv1.DestinationRule{
+				Host: "reviews.bookinfo.svc.cluster.local",
+				TrafficPolicy: &istionetv1.TrafficPolicy{
+					PortLevelSettings: []*istionetv1.TrafficPolicy_PortTrafficPolicy{{
+						Port: &istionetv1.PortSelector{Number: 9080},
+						Tls: &istionetv1.ClientTLSSettings{
+							Mode: istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+							Sni:  "reviews-9080.bookinfo.svc.cluster.local",
+							SubjectAltNames: []string{
+								"spiffe://cluster.local/ns/bookinfo/sa/reviews",
+								"spiffe://west.local/ns/bookinfo/sa/reviews",
+							},
+						},
+					}},
+				},
+			},
+		}},
+	}, {
+		name: "router compatible SNI should match the configured discovery service of the remote",
+		remotes: []config.Remote{{
+			Name:        "central",
+			Addresses:   []string{"central.example.com"},
+			IngressType: config.OpenShiftRouter,
+			DiscoveryService: config.DiscoveryService{
+				Name:      "fds",
+				Namespace: "federation-system",
+				Port:      ptr.To(uint32(9443)),
+			},
+		}},
+		expectedDestinationRules: []*networkingv1.DestinationRule{{
+			ObjectMeta: objectMeta("mtls-sni-fds-federation-system-svc-cluster-local"),
+			Spec: istionetv1.DestinationRule{
+				Host: "fds.federation-system.svc.cluster.local",
+				TrafficPolicy: &istionetv1.TrafficPolicy{
+					Tls: &istionetv1.ClientTLSSettings{
+						Mode: istionetv1.ClientTLSSettings_ISTIO_MUTUAL,
+						Sni:  "fds-9443.federation-system.svc.cluster.local",
+					},
+				},
+			},
+		}},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := copyConfig(&exportConfig)
+			cfg.MeshPeers.Remotes = tc.remotes
+			importedServiceStore := fds.NewImportedServiceStore()
+			for remote, services := range tc.importedServices {
+				importedServiceStore.Update(remote, services)
+			}
+
+			factory := NewConfigFactory(*cfg, nil, nil, nil, importedServiceStore, nil, nil, "istio-system")
+			actual := factory.DestinationRules()
+
+			if toJSON(actual) != toJSON(tc.expectedDestinationRules) {
+				t.Errorf("got unexpected result:\nexpected:\n%s\ngot:\n%s", toJSON(tc.expectedDestinationRules), toJSON(actual))
+			}
+		})
+	}
+}
+
 func TestServiceEntries(t *testing.T) {
 	importConfigRemoteIP := copyConfig(&exportConfig)
 	importConfigRemoteIP.MeshPeers.Remotes = []config.Remote{{
@@ -303,11 +756,35 @@ func TestServiceEntries(t *testing.T) {
 		Network:   "west-network",
 	}}
 
+	importConfigRemoteIPv6 := copyConfig(&exportConfig)
+	importConfigRemoteIPv6.MeshPeers.Remotes = []config.Remote{{
+		Name:      "west",
+		Addresses: []string{"2001:db8::1", "2001:db8::2"},
+		Network:   "west-network",
+	}}
+
+	importConfigDualStack := copyConfig(&exportConfig)
+	importConfigDualStack.MeshPeers.Remotes = []config.Remote{{
+		Name:       "west",
+		Addresses:  []string{"1.1.1.1", "2001:db8::1"},
+		Network:    "west-network",
+		IPFamilies: []config.IPFamily{config.IPv6, config.IPv4},
+	}}
+
+	importConfigDualStackDNS := copyConfig(importConfigDualStack)
+	importConfigDualStackDNS.MeshPeers.Remotes[0].Addresses = []string{"remote-ingress.net"}
+
+	importConfigAmbient := copyConfig(importConfigRemoteIP)
+	importConfigAmbient.MeshPeers.Local.DataplaneMode = config.Ambient
+	importConfigAmbient.MeshPeers.Remotes[0].DataplaneMode = config.Ambient
+
 	testCases := []struct {
 		name                      string
 		cfg                       config.Federation
 		localServices             []*corev1.Service
+		localEndpointSlices       []*discoveryv1.EndpointSlice
 		importedServices          []*v1alpha1.FederatedService
+		resolvedAddresses         staticResolver
 		expectedServiceEntryFiles []string
 	}{{
 		name:                      "no ServiceEntry is created if remote addresses are empty",
@@ -327,6 +804,45 @@ func TestServiceEntries(t *testing.T) {
 		localServices:             []*corev1.Service{svcA_ns1},
 		importedServices:          []*v1alpha1.FederatedService{importedSvcA_ns1, importedSvcB_ns1, importedSvcA_ns2},
 		expectedServiceEntryFiles: []string{"dns/fds.yaml", "dns/svc-b-ns-1.yaml", "dns/svc-a-ns-2.yaml"},
+	}, {
+		name:                      "ServiceEntries should have STATIC endpoints when remote addresses are IPv6",
+		cfg:                       *importConfigRemoteIPv6,
+		localServices:             []*corev1.Service{svcA_ns1},
+		importedServices:          []*v1alpha1.FederatedService{importedSvcA_ns1, importedSvcB_ns1},
+		expectedServiceEntryFiles: []string{"ipv6/fds.yaml", "ipv6/svc-b-ns-1.yaml"},
+	}, {
+		name:                      "ServiceEntries should have endpoints ordered by the IP family preference of the remote",
+		cfg:                       *importConfigDualStack,
+		expectedServiceEntryFiles: []string{"dual-stack/fds.yaml"},
+	}, {
+		name:                      "ServiceEntries should have STATIC endpoints resolved by the address cache when the remote prefers IP families",
+		cfg:                       *importConfigDualStackDNS,
+		resolvedAddresses:         staticResolver{"remote-ingress.net": {"1.1.1.1", "2001:db8::1"}},
+		expectedServiceEntryFiles: []string{"dual-stack/fds.yaml"},
+	}, {
+		name:                      "ServiceEntries should not have endpoints for services reported as unhealthy by the remote",
+		cfg:                       *importConfigRemoteIP,
+		importedServices:          []*v1alpha1.FederatedService{unhealthy(importedSvcB_ns1)},
+		expectedServiceEntryFiles: []string{"ip/fds.yaml", "ip/svc-b-ns-1-unhealthy.yaml"},
+	}, {
+		name:                      "ServiceEntries should have HBONE endpoints without port overrides when the remote runs in ambient mode",
+		cfg:                       *importConfigAmbient,
+		localServices:             []*corev1.Service{svcA_ns1},
+		importedServices:          []*v1alpha1.FederatedService{importedSvcA_ns1, importedSvcB_ns1},
+		expectedServiceEntryFiles: []string{"ambient/fds.yaml", "ambient/svc-b-ns-1.yaml"},
+	}, {
+		name:             "ServiceEntries should be created for instances of imported headless services",
+		cfg:              *importConfigRemoteIP,
+		importedServices: []*v1alpha1.FederatedService{importedSvcKafka_ns1},
+		expectedServiceEntryFiles: []string{
+			"ip/fds.yaml", "ip/svc-kafka-ns-1.yaml", "ip/svc-kafka-0-ns-1.yaml", "ip/svc-kafka-1-ns-1.yaml",
+		},
+	}, {
+		name:                      "ServiceEntries should be created for instances of exported headless services",
+		cfg:                       exportConfig,
+		localServices:             []*corev1.Service{export(svcKafka_ns1)},
+		localEndpointSlices:       []*discoveryv1.EndpointSlice{kafkaEndpointSlice},
+		expectedServiceEntryFiles: []string{"export/svc-kafka-0-ns-1.yaml", "export/svc-kafka-1-ns-1.yaml"},
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -334,6 +850,8 @@ func TestServiceEntries(t *testing.T) {
 			informerFactory := informers.NewSharedInformerFactory(client, 0)
 			serviceInformer := informerFactory.Core().V1().Services().Informer()
 			serviceLister := informerFactory.Core().V1().Services().Lister()
+			endpointSliceInformer := informerFactory.Discovery().V1().EndpointSlices().Informer()
+			endpointSliceLister := informerFactory.Discovery().V1().EndpointSlices().Lister()
 			stopCh := make(chan struct{})
 			informerFactory.Start(stopCh)
 
@@ -343,16 +861,37 @@ func TestServiceEntries(t *testing.T) {
 				}
 			}
 
+			for _, slice := range tc.localEndpointSlices {
+				if _, err := client.DiscoveryV1().EndpointSlices(slice.Namespace).Create(context.Background(), slice, v1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create endpoint slice %s/%s: %v", slice.Name, slice.Namespace, err)
+				}
+			}
+
 			serviceController, err := informer.NewResourceController(serviceInformer, corev1.Service{})
 			if err != nil {
 				t.Fatalf("error creating serviceController: %v", err)
 			}
 			serviceController.RunAndWait(stopCh)
 
+			endpointSliceController, err := informer.NewResourceController(endpointSliceInformer, discoveryv1.EndpointSlice{})
+			if err != nil {
+				t.Fatalf("error creating endpointSliceController: %v", err)
+			}
+			endpointSliceController.RunAndWait(stopCh)
+
 			importedServiceStore := fds.NewImportedServiceStore()
 			importedServiceStore.Update("west", tc.importedServices)
 
-			factory := NewConfigFactory(tc.cfg, serviceLister, importedServiceStore, "istio-system")
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var remoteAddresses []string
+			for _, remote := range tc.cfg.MeshPeers.Remotes {
+				remoteAddresses = append(remoteAddresses, remote.Addresses...)
+			}
+			addressCache := networking.NewAddressCache(tc.resolvedAddresses, remoteAddresses, nil)
+			addressCache.Start(ctx)
+
+			factory := NewConfigFactory(tc.cfg, serviceLister, common.NewExportedServiceLister(tc.cfg, serviceLister, nil, nil), endpointSliceLister, importedServiceStore, addressCache, nil, "istio-system")
 			serviceEntries, err := factory.ServiceEntries()
 			if err != nil {
 				t.Fatalf("error getting ServiceEntries: %v", err)
@@ -362,6 +901,59 @@ func TestServiceEntries(t *testing.T) {
 	}
 }
 
+func TestWorkloadEntries(t *testing.T) {
+	testCases := []struct {
+		name              string
+		remote            config.Remote
+		expectedAddresses map[string]string
+	}{{
+		name:              "WorkloadEntries should be created for each IPv6 address of the remote",
+		remote:            config.Remote{Name: "west", Addresses: []string{"2001:db8::1", "2001:db8::2"}},
+		expectedAddresses: map[string]string{"import-west-b-0": "2001:db8::1", "import-west-b-1": "2001:db8::2"},
+	}, {
+		name: "WorkloadEntries should be ordered by the IP family preference of the remote",
+		remote: config.Remote{
+			Name:       "west",
+			Addresses:  []string{"1.1.1.1", "2001:db8::1"},
+			IPFamilies: []config.IPFamily{config.IPv6, config.IPv4},
+		},
+		expectedAddresses: map[string]string{"import-west-b-0": "2001:db8::1", "import-west-b-1": "1.1.1.1"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(svcB_ns1)
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			serviceLister := informerFactory.Core().V1().Services().Lister()
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			informerFactory.WaitForCacheSync(stopCh)
+
+			cfg := copyConfig(&exportConfig)
+			cfg.MeshPeers.Remotes = []config.Remote{tc.remote}
+			importedServiceStore := fds.NewImportedServiceStore()
+			importedServiceStore.Update("west", []*v1alpha1.FederatedService{importedSvcB_ns1})
+
+			factory := NewConfigFactory(*cfg, serviceLister, nil, nil, importedServiceStore, nil, nil, "istio-system")
+			workloadEntries, err := factory.WorkloadEntries()
+			if err != nil {
+				t.Fatalf("error getting WorkloadEntries: %v", err)
+			}
+
+			actualAddresses := make(map[string]string, len(workloadEntries))
+			for _, we := range workloadEntries {
+				if we.Namespace != "ns1" {
+					t.Errorf("expected WorkloadEntry %s in the namespace of the local service, got %s", we.Name, we.Namespace)
+				}
+				actualAddresses[we.Name] = we.Spec.Address
+			}
+			if !reflect.DeepEqual(actualAddresses, tc.expectedAddresses) {
+				t.Errorf("expected addresses %v, got %v", tc.expectedAddresses, actualAddresses)
+			}
+		})
+	}
+}
+
 func export(svc *corev1.Service) *corev1.Service {
 	exported := svc.DeepCopy()
 	if exported.Labels == nil {
@@ -371,6 +963,19 @@ func export(svc *corev1.Service) *corev1.Service {
 	return exported
 }
 
+func unhealthy(svc *v1alpha1.FederatedService) *v1alpha1.FederatedService {
+	out := svc.DeepCopy()
+	out.Health = v1alpha1.HealthStatus_UNHEALTHY
+	return out
+}
+
+// staticResolver resolves hostnames to fixed addresses.
+type staticResolver map[string][]string
+
+func (r staticResolver) Lookup(_ context.Context, host string) ([]string, time.Duration, error) {
+	return r[host], time.Minute, nil
+}
+
 func copyConfig(original *config.Federation) *config.Federation {
 	originalJSON, err := json.Marshal(original)
 	if err != nil {
@@ -20,26 +20,38 @@ import (
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	istioprotocol "istio.io/istio/pkg/config/protocol"
+	istiolog "istio.io/istio/pkg/log"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	v1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
 
 	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
-	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds/adss"
 )
 
-var _ adss.RequestHandler = (*ExportedServicesGenerator)(nil)
+var (
+	_ adss.RequestHandler = (*ExportedServicesGenerator)(nil)
+
+	log = istiolog.RegisterScope("fds", "Federation Discovery Service")
+)
 
 type ExportedServicesGenerator struct {
-	cfg           config.Federation
-	serviceLister v1.ServiceLister
+	exportedServiceLister *common.ExportedServiceLister
+	endpointSliceLister   discoverylisters.EndpointSliceLister
 }
 
-func NewExportedServicesGenerator(cfg config.Federation, serviceLister v1.ServiceLister) *ExportedServicesGenerator {
+func NewExportedServicesGenerator(
+	exportedServiceLister *common.ExportedServiceLister,
+	endpointSliceLister discoverylisters.EndpointSliceLister,
+) *ExportedServicesGenerator {
 	return &ExportedServicesGenerator{
-		cfg:           cfg,
-		serviceLister: serviceLister,
+		exportedServiceLister: exportedServiceLister,
+		endpointSliceLister:   endpointSliceLister,
 	}
 }
 
@@ -48,53 +60,81 @@ func (g *ExportedServicesGenerator) GetTypeUrl() string {
 }
 
 func (g *ExportedServicesGenerator) GenerateResponse() ([]*anypb.Any, error) {
+	services, err := g.exportedServiceLister.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exported services: %w", err)
+	}
 	var exportedServices []*v1alpha1.FederatedService
-	for _, exportLabelSelector := range g.cfg.ExportedServiceSet.GetLabelSelectors() {
-		matchExported := labels.SelectorFromSet(exportLabelSelector.MatchLabels)
-		services, err := g.serviceLister.List(matchExported)
+	for _, svc := range services {
+		if err := common.CheckExportable(svc); err != nil {
+			log.Warnf("skipping export: %v", err)
+			continue
+		}
+		endpointSlices, err := g.listEndpointSlices(svc)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list services: %w", err)
+			return nil, err
 		}
-		for _, svc := range services {
-			var ports []*v1alpha1.ServicePort
-			for _, port := range svc.Spec.Ports {
-				servicePort := &v1alpha1.ServicePort{
-					Name:   port.Name,
-					Number: uint32(port.Port),
-				}
-				if port.TargetPort.IntVal != 0 {
-					servicePort.TargetPort = uint32(port.TargetPort.IntVal)
-				}
-				servicePort.Protocol = detectProtocol(port.Name)
-				ports = append(ports, servicePort)
+		var ports []*v1alpha1.ServicePort
+		for _, port := range svc.Spec.Ports {
+			protocol := common.DetectProtocol(port)
+			if protocol == istioprotocol.UDP {
+				log.Warnf("skipping port %s/%d of service %s/%s: UDP cannot be exposed through the federation ingress gateway",
+					port.Name, port.Port, svc.Namespace, svc.Name)
+				continue
 			}
-			exportedService := &v1alpha1.FederatedService{
-				Hostname: fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace),
-				Ports:    ports,
-				Labels:   svc.Labels,
-			}
-			exportedServices = append(exportedServices, exportedService)
+			ports = append(ports, &v1alpha1.ServicePort{
+				Name:       port.Name,
+				Number:     uint32(port.Port),
+				TargetPort: resolveTargetPort(port, endpointSlices),
+				Protocol:   strings.ToUpper(string(protocol)),
+			})
+		}
+		readyEndpoints := common.CountReadyEndpoints(endpointSlices)
+		exportedService := &v1alpha1.FederatedService{
+			Hostname:          fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace),
+			Ports:             ports,
+			Labels:            svc.Labels,
+			ReadyEndpoints:    readyEndpoints,
+			Health:            healthStatus(readyEndpoints),
+			InstanceHostnames: common.InstanceHostnames(svc, endpointSlices),
+			ServiceAccounts:   common.ServiceAccounts(svc),
 		}
+		exportedServices = append(exportedServices, exportedService)
 	}
 	return serialize(exportedServices)
 }
 
-// TODO: check appProtocol and reject UDP
-func detectProtocol(portName string) string {
-	if portName == "https" || strings.HasPrefix(portName, "https-") {
-		return "HTTPS"
-	} else if portName == "http" || strings.HasPrefix(portName, "http-") {
-		return "HTTP"
-	} else if portName == "http2" || strings.HasPrefix(portName, "http2-") {
-		return "HTTP2"
-	} else if portName == "grpc" || strings.HasPrefix(portName, "grpc-") {
-		return "GRPC"
-	} else if portName == "tls" || strings.HasPrefix(portName, "tls-") {
-		return "TLS"
-	} else if portName == "mongo" || strings.HasPrefix(portName, "mongo-") {
-		return "MONGO"
+func (g *ExportedServicesGenerator) listEndpointSlices(svc *corev1.Service) ([]*discoveryv1.EndpointSlice, error) {
+	endpointSlices, err := g.endpointSliceLister.EndpointSlices(svc.Namespace).List(
+		labels.SelectorFromSet(map[string]string{discoveryv1.LabelServiceName: svc.Name}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices for service %s/%s: %w", svc.Namespace, svc.Name, err)
+	}
+	return endpointSlices, nil
+}
+
+func healthStatus(readyEndpoints uint32) v1alpha1.HealthStatus {
+	if readyEndpoints == 0 {
+		return v1alpha1.HealthStatus_UNHEALTHY
+	}
+	return v1alpha1.HealthStatus_HEALTHY
+}
+
+// resolveTargetPort returns the numeric target port of the given service port.
+// Named target ports are resolved using EndpointSlices, which contain port numbers resolved from pod specs.
+// Returns 0 if the target port can't be resolved, e.g. when the service has no endpoints.
+func resolveTargetPort(port corev1.ServicePort, endpointSlices []*discoveryv1.EndpointSlice) uint32 {
+	if port.TargetPort.Type == intstr.Int {
+		return uint32(port.TargetPort.IntVal)
+	}
+	for _, slice := range endpointSlices {
+		for _, slicePort := range slice.Ports {
+			if slicePort.Name != nil && *slicePort.Name == port.Name && slicePort.Port != nil {
+				return uint32(*slicePort.Port)
+			}
+		}
 	}
-	return "TCP"
+	return 0
 }
 
 func serialize(exportedServices []*v1alpha1.FederatedService) ([]*anypb.Any, error) {
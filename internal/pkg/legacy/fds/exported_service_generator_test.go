@@ -21,11 +21,15 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/protobuf/types/known/anypb"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
 
 	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/config"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/informer"
 )
@@ -64,6 +68,13 @@ var (
 		{Name: "tcp-prefix", Port: 23, Protocol: "TCP"},
 		{Name: "mongo", Port: 27017, Protocol: "MONGO"},
 		{Name: "mongo-prefix", Port: 37017, Protocol: "MONGO"},
+		{Name: "grpc-web", Port: 8081, Protocol: "TCP"},
+		{Name: "redis", Port: 6379, Protocol: "TCP"},
+		{Name: "mysql", Port: 3306, Protocol: "TCP"},
+		{Name: "dns", Port: 53, Protocol: "UDP"},
+		{Name: "http-app-protocol", Port: 9090, Protocol: "TCP", AppProtocol: ptr.To("grpc")},
+		{Name: "h2c", Port: 9091, Protocol: "TCP", AppProtocol: ptr.To("kubernetes.io/h2c")},
+		{Name: "named-target-port", Port: 9092, Protocol: "TCP", TargetPort: intstr.FromString("metrics")},
 		{Name: "unknown", Port: 1, Protocol: "TCP"},
 	}
 	allExportedPorts = []*v1alpha1.ServicePort{
@@ -81,6 +92,12 @@ var (
 		{Name: "tcp-prefix", Number: 23, Protocol: "TCP"},
 		{Name: "mongo", Number: 27017, Protocol: "MONGO"},
 		{Name: "mongo-prefix", Number: 37017, Protocol: "MONGO"},
+		{Name: "grpc-web", Number: 8081, Protocol: "GRPC-WEB"},
+		{Name: "redis", Number: 6379, Protocol: "REDIS"},
+		{Name: "mysql", Number: 3306, Protocol: "MYSQL"},
+		{Name: "http-app-protocol", Number: 9090, Protocol: "GRPC"},
+		{Name: "h2c", Number: 9091, Protocol: "HTTP2"},
+		{Name: "named-target-port", Number: 9092, TargetPort: 15090, Protocol: "TCP"},
 		{Name: "unknown", Number: 1, Protocol: "TCP"},
 	}
 )
@@ -89,6 +106,7 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 	testCases := []struct {
 		name                     string
 		existingServices         []*corev1.Service
+		existingEndpointSlices   []*discoveryv1.EndpointSlice
 		expectedExportedServices []*v1alpha1.FederatedService
 	}{{
 		name: "found 2 services matching configured label selector",
@@ -121,6 +139,12 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 			},
 			Spec: corev1.ServiceSpec{Ports: allPorts},
 		}},
+		existingEndpointSlices: []*discoveryv1.EndpointSlice{
+			withPort(endpointSlice("b-ipv4", "ns1", "b", endpoint("b-1", true), endpoint("b-2", true), endpoint("b-3", false)), "named-target-port", 15090),
+			// Endpoints in the IPv6 slice refer to the same pods and must not be counted twice
+			endpointSlice("b-ipv6", "ns1", "b", endpoint("b-1", true), endpoint("b-2", true)),
+			withPort(endpointSlice("a", "ns2", "a", endpoint("a-1", false)), "named-target-port", 15090),
+		},
 		expectedExportedServices: []*v1alpha1.FederatedService{{
 			Hostname: "b.ns1.svc.cluster.local",
 			Ports:    allExportedPorts,
@@ -128,6 +152,8 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 				"app":    "b",
 				"export": "true",
 			},
+			ReadyEndpoints: 2,
+			Health:         v1alpha1.HealthStatus_HEALTHY,
 		}, {
 			Hostname: "a.ns2.svc.cluster.local",
 			Ports:    allExportedPorts,
@@ -135,6 +161,60 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 				"app":    "a",
 				"export": "true",
 			},
+			Health: v1alpha1.HealthStatus_UNHEALTHY,
+		}},
+	}, {
+		name: "headless services should be exported with instance hostnames and ExternalName services should be skipped",
+		existingServices: []*corev1.Service{{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "kafka",
+				Namespace: "ns1",
+				Labels:    map[string]string{"export": "true"},
+			},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: corev1.ClusterIPNone,
+				Ports:     []corev1.ServicePort{{Name: "tcp", Port: 9092}},
+			},
+		}, {
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "db",
+				Namespace: "ns1",
+				Labels:    map[string]string{"export": "true"},
+			},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: "db.example.com",
+				Ports:        []corev1.ServicePort{{Name: "tcp", Port: 5432}},
+			},
+		}},
+		existingEndpointSlices: []*discoveryv1.EndpointSlice{
+			endpointSlice("kafka", "ns1", "kafka", withHostname(endpoint("kafka-1", true)), withHostname(endpoint("kafka-0", false))),
+		},
+		expectedExportedServices: []*v1alpha1.FederatedService{{
+			Hostname:          "kafka.ns1.svc.cluster.local",
+			Ports:             []*v1alpha1.ServicePort{{Name: "tcp", Number: 9092, Protocol: "TCP"}},
+			Labels:            map[string]string{"export": "true"},
+			ReadyEndpoints:    1,
+			Health:            v1alpha1.HealthStatus_HEALTHY,
+			InstanceHostnames: []string{"kafka-0.kafka.ns1.svc.cluster.local", "kafka-1.kafka.ns1.svc.cluster.local"},
+		}},
+	}, {
+		name: "service accounts declared in the Istio annotation should be exported",
+		existingServices: []*corev1.Service{{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "reviews",
+				Namespace:   "bookinfo",
+				Labels:      map[string]string{"export": "true"},
+				Annotations: map[string]string{"alpha.istio.io/kubernetes-serviceaccounts": "reviews-v2,reviews-v1"},
+			},
+			Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 9080}}},
+		}},
+		expectedExportedServices: []*v1alpha1.FederatedService{{
+			Hostname:        "reviews.bookinfo.svc.cluster.local",
+			Ports:           []*v1alpha1.ServicePort{{Name: "http", Number: 9080, Protocol: "HTTP"}},
+			Labels:          map[string]string{"export": "true"},
+			Health:          v1alpha1.HealthStatus_UNHEALTHY,
+			ServiceAccounts: []string{"reviews-v1", "reviews-v2"},
 		}},
 	}}
 	for _, tc := range testCases {
@@ -143,6 +223,8 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 			informerFactory := informers.NewSharedInformerFactory(client, 0)
 			serviceInformer := informerFactory.Core().V1().Services().Informer()
 			serviceLister := informerFactory.Core().V1().Services().Lister()
+			endpointSliceInformer := informerFactory.Discovery().V1().EndpointSlices().Informer()
+			endpointSliceLister := informerFactory.Discovery().V1().EndpointSlices().Lister()
 			stopCh := make(chan struct{})
 			informerFactory.Start(stopCh)
 
@@ -152,13 +234,25 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 				}
 			}
 
+			for _, slice := range tc.existingEndpointSlices {
+				if _, err := client.DiscoveryV1().EndpointSlices(slice.Namespace).Create(context.Background(), slice, v1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create endpoint slice %s/%s: %v", slice.Name, slice.Namespace, err)
+				}
+			}
+
 			serviceController, err := informer.NewResourceController(serviceInformer, corev1.Service{})
 			if err != nil {
 				t.Fatalf("error creating serviceController: %v", err)
 			}
 			serviceController.RunAndWait(stopCh)
 
-			generator := NewExportedServicesGenerator(federationConfig, serviceLister)
+			endpointSliceController, err := informer.NewResourceController(endpointSliceInformer, discoveryv1.EndpointSlice{})
+			if err != nil {
+				t.Fatalf("error creating endpointSliceController: %v", err)
+			}
+			endpointSliceController.RunAndWait(stopCh)
+
+			generator := NewExportedServicesGenerator(common.NewExportedServiceLister(federationConfig, serviceLister, nil, nil), endpointSliceLister)
 
 			resources, err := generator.GenerateResponse()
 			if err != nil {
@@ -186,6 +280,35 @@ func TestNewExportedServicesGenerator(t *testing.T) {
 	}
 }
 
+func endpointSlice(name, namespace, svcName string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: svcName},
+		},
+		Endpoints: endpoints,
+	}
+}
+
+func withPort(slice *discoveryv1.EndpointSlice, name string, port int32) *discoveryv1.EndpointSlice {
+	slice.Ports = append(slice.Ports, discoveryv1.EndpointPort{Name: ptr.To(name), Port: ptr.To(port)})
+	return slice
+}
+
+func withHostname(endpoint discoveryv1.Endpoint) discoveryv1.Endpoint {
+	endpoint.Hostname = ptr.To(endpoint.TargetRef.Name)
+	return endpoint
+}
+
+func endpoint(podName string, ready bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses:  []string{podName},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+		TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: podName},
+	}
+}
+
 func deserializeExportedServices(t *testing.T, resources []*anypb.Any) []*v1alpha1.FederatedService {
 	t.Helper()
 	var out []*v1alpha1.FederatedService
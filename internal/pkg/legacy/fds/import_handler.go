@@ -16,11 +16,18 @@ package fds
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/client-go/listers/core/v1"
 
 	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds/adsc"
 )
@@ -28,14 +35,28 @@ import (
 var _ adsc.ResponseHandler = (*ImportedServiceHandler)(nil)
 
 type ImportedServiceHandler struct {
-	store        *ImportedServiceStore
-	pushRequests chan<- xds.PushRequest
+	store         *ImportedServiceStore
+	pushRequests  chan<- xds.PushRequest
+	serviceLister v1.ServiceLister
+	recorder      *events.Recorder
+
+	mu sync.Mutex
+	// imported holds hostnames of services received from each remote peer to detect imported and withdrawn services.
+	imported map[string]map[string]struct{}
 }
 
-func NewImportedServiceHandler(store *ImportedServiceStore, pushRequests chan<- xds.PushRequest) *ImportedServiceHandler {
+func NewImportedServiceHandler(
+	store *ImportedServiceStore,
+	pushRequests chan<- xds.PushRequest,
+	serviceLister v1.ServiceLister,
+	recorder *events.Recorder,
+) *ImportedServiceHandler {
 	return &ImportedServiceHandler{
-		store:        store,
-		pushRequests: pushRequests,
+		store:         store,
+		pushRequests:  pushRequests,
+		serviceLister: serviceLister,
+		recorder:      recorder,
+		imported:      make(map[string]map[string]struct{}),
 	}
 }
 
@@ -50,9 +71,69 @@ func (h *ImportedServiceHandler) Handle(source string, resources []*anypb.Any) e
 	}
 
 	h.store.Update(source, importedServices)
+	h.recordChanges(source, importedServices)
 	// TODO: push only if current state != received imported services (this can happen on reconnection)
 	h.pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
 	h.pushRequests <- xds.PushRequest{TypeUrl: xds.WorkloadEntryTypeUrl}
 	h.pushRequests <- xds.PushRequest{TypeUrl: xds.DestinationRuleTypeUrl}
+	h.pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceImportTypeUrl}
+	// Conflicts between local and imported services are reported in ServiceExport status.
+	h.pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceExportTypeUrl}
 	return nil
 }
+
+// recordChanges records events on local services with the same name and namespace as services imported
+// or withdrawn by the remote peer, and warns when ports of the local and the imported service differ.
+func (h *ImportedServiceHandler) recordChanges(source string, importedServices []*v1alpha1.FederatedService) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.imported[source]
+	curr := make(map[string]struct{}, len(importedServices))
+	for _, importedSvc := range importedServices {
+		hostname := importedSvc.GetHostname()
+		curr[hostname] = struct{}{}
+		if _, found := prev[hostname]; found {
+			continue
+		}
+		name, namespace := serviceNameAndNs(hostname)
+		localSvc := h.localService(namespace, name)
+		h.recorder.Service(localSvc, namespace, name, corev1.EventTypeNormal, events.ReasonServiceImported,
+			"Service %s imported from remote peer %s", hostname, source)
+		if localSvc != nil && common.PortsConflict(localSvc, importedSvc) {
+			h.recorder.Service(localSvc, namespace, name, corev1.EventTypeWarning, events.ReasonNamingConflict,
+				"Ports of the local service differ from ports of the service %s imported from remote peer %s", hostname, source)
+		}
+	}
+	for hostname := range prev {
+		if _, found := curr[hostname]; found {
+			continue
+		}
+		name, namespace := serviceNameAndNs(hostname)
+		h.recorder.Service(h.localService(namespace, name), namespace, name, corev1.EventTypeNormal, events.ReasonServiceWithdrawn,
+			"Service %s withdrawn by remote peer %s", hostname, source)
+	}
+	h.imported[source] = curr
+}
+
+func (h *ImportedServiceHandler) localService(namespace, name string) *corev1.Service {
+	if h.serviceLister == nil {
+		return nil
+	}
+	svc, err := h.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Errorf("failed to get service %s/%s: %v", namespace, name, err)
+		}
+		return nil
+	}
+	return svc
+}
+
+func serviceNameAndNs(hostname string) (string, string) {
+	domainLabels := strings.SplitN(hostname, ".", 3)
+	if len(domainLabels) < 2 {
+		return hostname, ""
+	}
+	return domainLabels[0], domainLabels[1]
+}
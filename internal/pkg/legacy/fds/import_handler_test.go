@@ -0,0 +1,127 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fds
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+func TestImportedServiceHandlerEvents(t *testing.T) {
+	httpbin := &v1alpha1.FederatedService{
+		Hostname: "httpbin.ns1.svc.cluster.local",
+		Ports:    []*v1alpha1.ServicePort{{Name: "http", Number: 8000, Protocol: "HTTP"}},
+	}
+	httpbinWithOtherPort := &v1alpha1.FederatedService{
+		Hostname: "httpbin.ns1.svc.cluster.local",
+		Ports:    []*v1alpha1.ServicePort{{Name: "http", Number: 9000, Protocol: "HTTP"}},
+	}
+	reviews := &v1alpha1.FederatedService{
+		Hostname: "reviews.ns2.svc.cluster.local",
+		Ports:    []*v1alpha1.ServicePort{{Name: "http", Number: 9080, Protocol: "HTTP"}},
+	}
+	localHttpbin := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: "httpbin", Namespace: "ns1"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 8000, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		updates        [][]*v1alpha1.FederatedService
+		expectedEvents []string
+	}{{
+		name:    "new services should be reported as imported",
+		updates: [][]*v1alpha1.FederatedService{{httpbin, reviews}},
+		expectedEvents: []string{
+			"Normal ServiceImported Service httpbin.ns1.svc.cluster.local imported from remote peer west",
+			"Normal ServiceImported ns2/reviews: Service reviews.ns2.svc.cluster.local imported from remote peer west",
+		},
+	}, {
+		name:    "services received again should not be reported",
+		updates: [][]*v1alpha1.FederatedService{{httpbin}, {httpbin}},
+		expectedEvents: []string{
+			"Normal ServiceImported Service httpbin.ns1.svc.cluster.local imported from remote peer west",
+		},
+	}, {
+		name:    "services not received anymore should be reported as withdrawn",
+		updates: [][]*v1alpha1.FederatedService{{httpbin, reviews}, {reviews}},
+		expectedEvents: []string{
+			"Normal ServiceImported Service httpbin.ns1.svc.cluster.local imported from remote peer west",
+			"Normal ServiceImported ns2/reviews: Service reviews.ns2.svc.cluster.local imported from remote peer west",
+			"Normal ServiceWithdrawn Service httpbin.ns1.svc.cluster.local withdrawn by remote peer west",
+		},
+	}, {
+		name:    "imported service with ports different from the local service should be reported as a naming conflict",
+		updates: [][]*v1alpha1.FederatedService{{httpbinWithOtherPort}},
+		expectedEvents: []string{
+			"Normal ServiceImported Service httpbin.ns1.svc.cluster.local imported from remote peer west",
+			"Warning NamingConflict Ports of the local service differ from ports of the service httpbin.ns1.svc.cluster.local imported from remote peer west",
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(localHttpbin)
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			serviceLister := informerFactory.Core().V1().Services().Lister()
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			informerFactory.WaitForCacheSync(stopCh)
+
+			fakeRecorder := record.NewFakeRecorder(len(tc.expectedEvents) + 1)
+			recorder := events.NewRecorder(fakeRecorder, events.PodReference("istio-system", "federation-controller"))
+			handler := NewImportedServiceHandler(NewImportedServiceStore(), make(chan xds.PushRequest, 100), serviceLister, recorder)
+
+			for _, update := range tc.updates {
+				var resources []*anypb.Any
+				for _, svc := range update {
+					res, err := anypb.New(svc)
+					if err != nil {
+						t.Fatalf("failed to serialize service: %v", err)
+					}
+					resources = append(resources, res)
+				}
+				if err := handler.Handle("west", resources); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			var recorded []string
+			for len(fakeRecorder.Events) > 0 {
+				recorded = append(recorded, <-fakeRecorder.Events)
+			}
+			if len(recorded) != len(tc.expectedEvents) {
+				t.Fatalf("expected events %v but got %v", tc.expectedEvents, recorded)
+			}
+			for i := range recorded {
+				if recorded[i] != tc.expectedEvents[i] {
+					t.Errorf("expected event %q but got %q", tc.expectedEvents[i], recorded[i])
+				}
+			}
+		})
+	}
+}
@@ -45,6 +45,19 @@ func (s *ImportedServiceStore) Update(source string, importedServices []*v1alpha
 	s.importedServices[source] = newImportedServices
 }
 
+// HasSynced returns true if services were received from all given remote peers at least once.
+func (s *ImportedServiceStore) HasSynced(remotes []config.Remote) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, remote := range remotes {
+		if _, found := s.importedServices[remote.Name]; !found {
+			return false
+		}
+	}
+	return true
+}
+
 // From returns copy of all services exported from given remote peer.
 func (s *ImportedServiceStore) From(remote config.Remote) []*v1alpha1.FederatedService {
 	s.mu.RLock()
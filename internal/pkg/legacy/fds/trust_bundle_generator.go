@@ -0,0 +1,112 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fds
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds/adss"
+)
+
+const (
+	// RootCertConfigMapName is the name of the ConfigMap distributed by istiod to every namespace.
+	RootCertConfigMapName = "istio-ca-root-cert"
+	rootCertConfigMapKey  = "root-cert.pem"
+)
+
+var _ adss.RequestHandler = (*TrustBundleGenerator)(nil)
+
+// TrustBundleGenerator serves the trust domain and root certificates of the local mesh, so that remote peers
+// can verify identities of the local workloads, even if they use different root CAs.
+type TrustBundleGenerator struct {
+	cfg             config.Federation
+	configMapLister corev1listers.ConfigMapLister
+	secretLister    corev1listers.SecretLister
+	namespace       string
+}
+
+// NewTrustBundleGenerator creates a generator reading root certificates from the secret configured
+// in meshPeers.local.caCertificates, or from the istio-ca-root-cert ConfigMap in the controller namespace.
+// Only the lister matching the configured source is used, so the other one may be nil.
+func NewTrustBundleGenerator(
+	cfg config.Federation,
+	configMapLister corev1listers.ConfigMapLister,
+	secretLister corev1listers.SecretLister,
+	namespace string,
+) *TrustBundleGenerator {
+	return &TrustBundleGenerator{
+		cfg:             cfg,
+		configMapLister: configMapLister,
+		secretLister:    secretLister,
+		namespace:       namespace,
+	}
+}
+
+func (g *TrustBundleGenerator) GetTypeUrl() string {
+	return xds.TrustBundleTypeUrl
+}
+
+func (g *TrustBundleGenerator) GenerateResponse() ([]*anypb.Any, error) {
+	rootCerts, err := g.rootCertificates()
+	if err != nil {
+		return nil, err
+	}
+	if rootCerts == "" {
+		// The trust domain is still sent, so that remote peers can verify their configuration
+		log.Warnf("root certificates of the local mesh not found, only the trust domain will be sent to remote peers")
+	}
+
+	trustBundle := &v1alpha1.TrustBundle{
+		TrustDomain:      g.cfg.MeshPeers.Local.GetTrustDomain(),
+		RootCertificates: rootCerts,
+	}
+	serialized := &anypb.Any{}
+	if err := anypb.MarshalFrom(serialized, trustBundle, proto.MarshalOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to serialize TrustBundle to protobuf message: %w", err)
+	}
+	return []*anypb.Any{serialized}, nil
+}
+
+// rootCertificates returns PEM-encoded root certificates or an empty string if the source does not exist yet.
+func (g *TrustBundleGenerator) rootCertificates() (string, error) {
+	if caCerts := g.cfg.MeshPeers.Local.CACertificates; caCerts != nil {
+		namespace := g.cfg.MeshPeers.Local.ControlPlane.Namespace
+		secret, err := g.secretLister.Secrets(namespace).Get(caCerts.SecretName)
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, caCerts.SecretName, err)
+		}
+		return string(secret.Data[caCerts.GetKey()]), nil
+	}
+
+	configMap, err := g.configMapLister.ConfigMaps(g.namespace).Get(RootCertConfigMapName)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get config map %s/%s: %w", g.namespace, RootCertConfigMapName, err)
+	}
+	return configMap.Data[rootCertConfigMapKey], nil
+}
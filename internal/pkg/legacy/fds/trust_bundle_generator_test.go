@@ -0,0 +1,115 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fds
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+func TestTrustBundleGenerator(t *testing.T) {
+	rootCertConfigMap := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{Name: "istio-ca-root-cert", Namespace: "istio-system"},
+		Data:       map[string]string{"root-cert.pem": "config-map-root-cert"},
+	}
+	caCertsSecret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "cacerts", Namespace: "istio-system"},
+		Data: map[string][]byte{
+			"root-cert.pem": []byte("secret-root-cert"),
+			"ca-cert.pem":   []byte("secret-ca-cert"),
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		local               config.Local
+		existingObjects     []runtime.Object
+		expectedTrustBundle *v1alpha1.TrustBundle
+	}{{
+		name:                "root certificates should be read from istio-ca-root-cert by default",
+		local:               config.Local{Name: "east"},
+		existingObjects:     []runtime.Object{rootCertConfigMap, caCertsSecret},
+		expectedTrustBundle: &v1alpha1.TrustBundle{TrustDomain: "cluster.local", RootCertificates: "config-map-root-cert"},
+	}, {
+		name: "root certificates should be read from the configured secret and key",
+		local: config.Local{
+			Name:           "east",
+			ControlPlane:   config.ControlPlane{Namespace: "istio-system"},
+			TrustDomain:    "east.local",
+			CACertificates: &config.CACertificates{SecretName: "cacerts", Key: "ca-cert.pem"},
+		},
+		existingObjects:     []runtime.Object{rootCertConfigMap, caCertsSecret},
+		expectedTrustBundle: &v1alpha1.TrustBundle{TrustDomain: "east.local", RootCertificates: "secret-ca-cert"},
+	}, {
+		name: "root certificates should be read from root-cert.pem when key is not configured",
+		local: config.Local{
+			Name:           "east",
+			ControlPlane:   config.ControlPlane{Namespace: "istio-system"},
+			CACertificates: &config.CACertificates{SecretName: "cacerts"},
+		},
+		existingObjects:     []runtime.Object{caCertsSecret},
+		expectedTrustBundle: &v1alpha1.TrustBundle{TrustDomain: "cluster.local", RootCertificates: "secret-root-cert"},
+	}, {
+		name:                "only trust domain should be sent when root certificates do not exist",
+		local:               config.Local{Name: "east", TrustDomain: "east.local"},
+		existingObjects:     []runtime.Object{caCertsSecret},
+		expectedTrustBundle: &v1alpha1.TrustBundle{TrustDomain: "east.local"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tc.existingObjects...)
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			// only the lister of the configured source is created, like in the controller
+			var configMapLister corev1listers.ConfigMapLister
+			var secretLister corev1listers.SecretLister
+			if tc.local.CACertificates != nil {
+				secretLister = informerFactory.Core().V1().Secrets().Lister()
+			} else {
+				configMapLister = informerFactory.Core().V1().ConfigMaps().Lister()
+			}
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informerFactory.Start(stopCh)
+			informerFactory.WaitForCacheSync(stopCh)
+
+			cfg := config.Federation{MeshPeers: config.MeshPeers{Local: tc.local}}
+			generator := NewTrustBundleGenerator(cfg, configMapLister, secretLister, "istio-system")
+
+			resources, err := generator.GenerateResponse()
+			if err != nil {
+				t.Fatalf("error generating response: %v", err)
+			}
+			if len(resources) != 1 {
+				t.Fatalf("expected 1 resource but got %d", len(resources))
+			}
+			var trustBundle v1alpha1.TrustBundle
+			if err := resources[0].UnmarshalTo(&trustBundle); err != nil {
+				t.Fatalf("failed to deserialize XDS resource: %v", err)
+			}
+			if trustBundle.TrustDomain != tc.expectedTrustBundle.TrustDomain || trustBundle.RootCertificates != tc.expectedTrustBundle.RootCertificates {
+				t.Errorf("expected trust bundle %v but got %v", tc.expectedTrustBundle, &trustBundle)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fds
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds/adsc"
+)
+
+var _ adsc.ResponseHandler = (*TrustBundleHandler)(nil)
+
+type TrustBundleHandler struct {
+	remote       config.Remote
+	store        *TrustBundleStore
+	pushRequests chan<- xds.PushRequest
+	recorder     *events.Recorder
+}
+
+func NewTrustBundleHandler(
+	remote config.Remote,
+	store *TrustBundleStore,
+	pushRequests chan<- xds.PushRequest,
+	recorder *events.Recorder,
+) *TrustBundleHandler {
+	return &TrustBundleHandler{
+		remote:       remote,
+		store:        store,
+		pushRequests: pushRequests,
+		recorder:     recorder,
+	}
+}
+
+// Handle stores the trust bundle received from the remote peer. Peers running older controllers do not serve
+// trust bundles and respond with no resources, which removes the previously imported bundle.
+// A trust domain different from the configured one is reported by a federation event whenever it changes,
+// because identities of services imported from the remote can't be verified.
+func (h *TrustBundleHandler) Handle(source string, resources []*anypb.Any) error {
+	var trustBundle *v1alpha1.TrustBundle
+	if len(resources) > 0 {
+		trustBundle = &v1alpha1.TrustBundle{}
+		if err := proto.Unmarshal(resources[0].Value, trustBundle); err != nil {
+			return fmt.Errorf("unable to unmarshal trust bundle: %w", err)
+		}
+	}
+
+	previous := h.store.From(h.remote)
+	h.store.Update(source, trustBundle)
+	if trustDomain := trustBundle.GetTrustDomain(); trustDomain != "" && trustDomain != h.remote.GetTrustDomain() &&
+		trustDomain != previous.GetTrustDomain() {
+		h.recorder.Federation(corev1.EventTypeWarning, events.ReasonTrustDomainMismatch,
+			"Remote peer %s advertises trust domain %s, but %s is configured", h.remote.Name, trustDomain, h.remote.GetTrustDomain())
+	}
+	h.pushRequests <- xds.PushRequest{TypeUrl: xds.ClusterTrustBundleTypeUrl}
+	// Trust domain mismatches are reported in ServiceExport status.
+	h.pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceExportTypeUrl}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fds
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+func TestTrustBundleHandlerEvents(t *testing.T) {
+	testCases := []struct {
+		name               string
+		trustDomains       []string
+		expectedMismatches int
+	}{{
+		name:         "matching trust domain",
+		trustDomains: []string{"west.local"},
+	}, {
+		name:         "trust domain not advertised by older controllers",
+		trustDomains: []string{""},
+	}, {
+		name:               "mismatch is reported once",
+		trustDomains:       []string{"cluster.local", "cluster.local"},
+		expectedMismatches: 1,
+	}, {
+		name:               "mismatch is reported again after it changes",
+		trustDomains:       []string{"cluster.local", "east.local", "west.local", "cluster.local"},
+		expectedMismatches: 3,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remote := config.Remote{Name: "west", TrustDomain: "west.local"}
+			fakeRecorder := record.NewFakeRecorder(len(tc.trustDomains))
+			recorder := events.NewRecorder(fakeRecorder, events.PodReference("istio-system", "federation-controller"))
+			handler := NewTrustBundleHandler(remote, NewTrustBundleStore(), make(chan xds.PushRequest, 2*len(tc.trustDomains)), recorder)
+
+			for _, trustDomain := range tc.trustDomains {
+				resource, err := anypb.New(&v1alpha1.TrustBundle{TrustDomain: trustDomain})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := handler.Handle(remote.Name, []*anypb.Any{resource}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if len(fakeRecorder.Events) != tc.expectedMismatches {
+				t.Errorf("expected %d %s events, got %d", tc.expectedMismatches, events.ReasonTrustDomainMismatch, len(fakeRecorder.Events))
+			}
+		})
+	}
+}
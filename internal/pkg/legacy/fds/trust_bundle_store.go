@@ -0,0 +1,54 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fds
+
+import (
+	"sync"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+// TrustBundleStore is a thread-safe wrapper for current trust bundles received from remote peers
+type TrustBundleStore struct {
+	mu           sync.RWMutex
+	trustBundles map[string]*v1alpha1.TrustBundle
+}
+
+func NewTrustBundleStore() *TrustBundleStore {
+	return &TrustBundleStore{
+		trustBundles: make(map[string]*v1alpha1.TrustBundle),
+	}
+}
+
+// Update replaces the trust bundle of the given source. Nil removes the trust bundle.
+func (s *TrustBundleStore) Update(source string, trustBundle *v1alpha1.TrustBundle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if trustBundle == nil {
+		delete(s.trustBundles, source)
+		return
+	}
+	s.trustBundles[source] = trustBundle.DeepCopy()
+}
+
+// From returns copy of the trust bundle received from given remote peer or nil if the peer did not send any.
+func (s *TrustBundleStore) From(remote config.Remote) *v1alpha1.TrustBundle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.trustBundles[remote.Name].DeepCopy()
+}
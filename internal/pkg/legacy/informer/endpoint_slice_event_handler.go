@@ -0,0 +1,111 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"slices"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+var _ Handler = (*EndpointSliceEventHandler)(nil)
+
+// EndpointSliceEventHandler triggers FDS pushes when readiness of exported services changes,
+// so that importing meshes can stop routing to services without healthy backends.
+// It also triggers FDS/MCP pushes when instances of exported headless services change,
+// because these instances are exported and exposed individually.
+type EndpointSliceEventHandler struct {
+	serviceLister         v1.ServiceLister
+	exportedServiceLister *common.ExportedServiceLister
+	fdsPushRequests       chan<- xds.PushRequest
+	mcpPushRequests       chan<- xds.PushRequest
+}
+
+func NewEndpointSliceEventHandler(
+	serviceLister v1.ServiceLister,
+	exportedServiceLister *common.ExportedServiceLister,
+	fdsPushRequests,
+	mcpPushRequests chan<- xds.PushRequest,
+) *EndpointSliceEventHandler {
+	return &EndpointSliceEventHandler{
+		serviceLister:         serviceLister,
+		exportedServiceLister: exportedServiceLister,
+		fdsPushRequests:       fdsPushRequests,
+		mcpPushRequests:       mcpPushRequests,
+	}
+}
+
+func (h *EndpointSliceEventHandler) Init() error {
+	return nil
+}
+
+func (h *EndpointSliceEventHandler) ObjectCreated(obj runtime.Object) {
+	slice := obj.(*discoveryv1.EndpointSlice)
+	log.Debugf("Created endpoint slice %s, namespace %s", slice.Name, slice.Namespace)
+	h.triggerXDSPushIfExported(slice, true)
+}
+
+func (h *EndpointSliceEventHandler) ObjectDeleted(obj runtime.Object) {
+	slice := obj.(*discoveryv1.EndpointSlice)
+	log.Debugf("Deleted endpoint slice %s, namespace %s", slice.Name, slice.Namespace)
+	h.triggerXDSPushIfExported(slice, true)
+}
+
+func (h *EndpointSliceEventHandler) ObjectUpdated(oldObj, newObj runtime.Object) {
+	oldSlice := oldObj.(*discoveryv1.EndpointSlice)
+	newSlice := newObj.(*discoveryv1.EndpointSlice)
+	log.Debugf("Updated endpoint slice %s, namespace %s", newSlice.Name, newSlice.Namespace)
+	readinessChanged := common.CountReadyEndpoints([]*discoveryv1.EndpointSlice{oldSlice}) != common.CountReadyEndpoints([]*discoveryv1.EndpointSlice{newSlice})
+	hostnamesChanged := !slices.Equal(endpointHostnames(oldSlice), endpointHostnames(newSlice))
+	if !readinessChanged && !hostnamesChanged {
+		return
+	}
+	h.triggerXDSPushIfExported(newSlice, hostnamesChanged)
+}
+
+func (h *EndpointSliceEventHandler) triggerXDSPushIfExported(slice *discoveryv1.EndpointSlice, hostnamesChanged bool) {
+	svcName, found := slice.Labels[discoveryv1.LabelServiceName]
+	if !found {
+		return
+	}
+	svc, err := h.serviceLister.Services(slice.Namespace).Get(svcName)
+	if err != nil {
+		log.Debugf("failed to get service %s/%s of endpoint slice %s: %v", slice.Namespace, svcName, slice.Name, err)
+		return
+	}
+	if !h.exportedServiceLister.IsExported(svc) {
+		return
+	}
+	h.fdsPushRequests <- xds.PushRequest{TypeUrl: xds.ExportedServiceTypeUrl}
+	if common.IsHeadless(svc) && hostnamesChanged {
+		h.mcpPushRequests <- xds.PushRequest{TypeUrl: xds.GatewayTypeUrl}
+		h.mcpPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+	}
+}
+
+func endpointHostnames(slice *discoveryv1.EndpointSlice) []string {
+	var hostnames []string
+	for _, endpoint := range slice.Endpoints {
+		if endpoint.Hostname != nil {
+			hostnames = append(hostnames, *endpoint.Hostname)
+		}
+	}
+	return hostnames
+}
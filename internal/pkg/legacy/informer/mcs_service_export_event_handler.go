@@ -0,0 +1,86 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/client-go/listers/core/v1"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+var _ Handler = (*MCSServiceExportEventHandler)(nil)
+
+// MCSServiceExportEventHandler processes events of ServiceExports (multicluster.x-k8s.io) and triggers FDS/MCP pushes,
+// because creating or deleting a ServiceExport changes the set of exported services.
+type MCSServiceExportEventHandler struct {
+	fdsPushRequests chan<- xds.PushRequest
+	mcpPushRequests chan<- xds.PushRequest
+	serviceLister   v1.ServiceLister
+	recorder        *events.Recorder
+}
+
+func NewMCSServiceExportEventHandler(
+	fdsPushRequests,
+	mcpPushRequests chan<- xds.PushRequest,
+	serviceLister v1.ServiceLister,
+	recorder *events.Recorder,
+) *MCSServiceExportEventHandler {
+	return &MCSServiceExportEventHandler{
+		fdsPushRequests: fdsPushRequests,
+		mcpPushRequests: mcpPushRequests,
+		serviceLister:   serviceLister,
+		recorder:        recorder,
+	}
+}
+
+func (h *MCSServiceExportEventHandler) Init() error {
+	return nil
+}
+
+func (h *MCSServiceExportEventHandler) ObjectCreated(obj runtime.Object) {
+	se := obj.(*mcsv1alpha1.ServiceExport)
+	log.Debugf("Created service export %s, namespace %s", se.Name, se.Namespace)
+	h.recorder.Service(h.service(se), se.Namespace, se.Name, corev1.EventTypeNormal, events.ReasonExportStarted,
+		"ServiceExport was created, service is exported to remote peers")
+	pushExportedServiceConfigs(h.fdsPushRequests, h.mcpPushRequests)
+}
+
+func (h *MCSServiceExportEventHandler) ObjectDeleted(obj runtime.Object) {
+	se := obj.(*mcsv1alpha1.ServiceExport)
+	log.Debugf("Deleted service export %s, namespace %s", se.Name, se.Namespace)
+	h.recorder.Service(h.service(se), se.Namespace, se.Name, corev1.EventTypeNormal, events.ReasonExportStopped,
+		"ServiceExport was deleted, service is no longer exported to remote peers")
+	pushExportedServiceConfigs(h.fdsPushRequests, h.mcpPushRequests)
+}
+
+// ObjectUpdated does not trigger pushes, because ServiceExport does not have a spec,
+// and status updates are made by this controller.
+func (h *MCSServiceExportEventHandler) ObjectUpdated(_, newObj runtime.Object) {
+	se := newObj.(*mcsv1alpha1.ServiceExport)
+	log.Debugf("Updated service export %s, namespace %s", se.Name, se.Namespace)
+}
+
+// service returns the Service exported by the given ServiceExport or nil if it does not exist.
+func (h *MCSServiceExportEventHandler) service(se *mcsv1alpha1.ServiceExport) *corev1.Service {
+	svc, err := h.serviceLister.Services(se.Namespace).Get(se.Name)
+	if err != nil {
+		return nil
+	}
+	return svc
+}
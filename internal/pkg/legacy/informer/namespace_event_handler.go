@@ -0,0 +1,80 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+var _ Handler = (*NamespaceEventHandler)(nil)
+
+// NamespaceEventHandler processes Namespace events and triggers FDS/MCP pushes if a namespace matching
+// namespace selectors of export rules is created or deleted, or if labels of a namespace change, so that services
+// in the whole namespace can be opted in or out of export.
+type NamespaceEventHandler struct {
+	exportedServiceLister *common.ExportedServiceLister
+	fdsPushRequests       chan<- xds.PushRequest
+	mcpPushRequests       chan<- xds.PushRequest
+}
+
+func NewNamespaceEventHandler(
+	exportedServiceLister *common.ExportedServiceLister,
+	fdsPushRequests,
+	mcpPushRequests chan<- xds.PushRequest,
+) *NamespaceEventHandler {
+	return &NamespaceEventHandler{
+		exportedServiceLister: exportedServiceLister,
+		fdsPushRequests:       fdsPushRequests,
+		mcpPushRequests:       mcpPushRequests,
+	}
+}
+
+func (h *NamespaceEventHandler) Init() error {
+	return nil
+}
+
+func (h *NamespaceEventHandler) ObjectCreated(obj runtime.Object) {
+	ns := obj.(*corev1.Namespace)
+	log.Debugf("Created namespace %s", ns.Name)
+	if h.exportedServiceLister.MatchesAnyNamespaceSelector(ns.Labels) {
+		pushExportedServiceConfigs(h.fdsPushRequests, h.mcpPushRequests)
+	}
+}
+
+func (h *NamespaceEventHandler) ObjectDeleted(obj runtime.Object) {
+	ns := obj.(*corev1.Namespace)
+	log.Debugf("Deleted namespace %s", ns.Name)
+	if h.exportedServiceLister.MatchesAnyNamespaceSelector(ns.Labels) {
+		pushExportedServiceConfigs(h.fdsPushRequests, h.mcpPushRequests)
+	}
+}
+
+func (h *NamespaceEventHandler) ObjectUpdated(oldObj, newObj runtime.Object) {
+	oldNs := oldObj.(*corev1.Namespace)
+	newNs := newObj.(*corev1.Namespace)
+	log.Debugf("Updated namespace %s", newNs.Name)
+	if maps.Equal(oldNs.Labels, newNs.Labels) {
+		return
+	}
+	if h.exportedServiceLister.MatchesAnyNamespaceSelector(oldNs.Labels) || h.exportedServiceLister.MatchesAnyNamespaceSelector(newNs.Labels) {
+		pushExportedServiceConfigs(h.fdsPushRequests, h.mcpPushRequests)
+	}
+}
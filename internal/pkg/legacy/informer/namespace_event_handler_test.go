@@ -0,0 +1,105 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+func TestNamespaceXDSTriggers(t *testing.T) {
+	cfg := config.Federation{
+		ExportedServiceSet: config.ExportedServiceSet{
+			Rules: []config.Rules{{
+				Type: config.LabelSelectorRuleType,
+				LabelSelectors: []config.LabelSelectors{{
+					MatchLabels: map[string]string{"export": "true"},
+				}},
+				NamespaceSelector: &config.LabelSelectors{
+					MatchLabels: map[string]string{"federation": "approved"},
+				},
+			}},
+		},
+	}
+	approved := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"federation": "approved"}},
+	}
+	notApproved := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"team": "a"}},
+	}
+
+	testCases := []struct {
+		name              string
+		handlerFunc       func(handler Handler)
+		isTimeoutExpected bool
+	}{{
+		name: "namespace created - does not match namespace selector - no XDS push expected",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectCreated(notApproved)
+		},
+		isTimeoutExpected: true,
+	}, {
+		name: "namespace created - matches namespace selector - XDS pushes expected",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectCreated(approved)
+		},
+		isTimeoutExpected: false,
+	}, {
+		name: "namespace updated - labels not changed - no XDS push expected",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(approved, approved.DeepCopy())
+		},
+		isTimeoutExpected: true,
+	}, {
+		name: "namespace updated - opted in - XDS pushes expected",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(notApproved, approved)
+		},
+		isTimeoutExpected: false,
+	}, {
+		name: "namespace updated - opted out - XDS pushes expected",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(approved, notApproved)
+		},
+		isTimeoutExpected: false,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fdsPushRequests := make(chan xds.PushRequest)
+			mcpPushRequests := make(chan xds.PushRequest)
+			handler := NewNamespaceEventHandler(common.NewExportedServiceLister(cfg, nil, nil, nil), fdsPushRequests, mcpPushRequests)
+
+			go func() {
+				tc.handlerFunc(handler)
+			}()
+
+			checkChannel(t, mcpPushRequests, xds.GatewayTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.EnvoyFilterTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.RouteTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.TLSRouteTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.ServiceEntryTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.ServiceExportTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.AuthorizationPolicyTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.ServiceTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, fdsPushRequests, xds.ExportedServiceTypeUrl, tc.isTimeoutExpected)
+		})
+	}
+}
@@ -16,10 +16,11 @@ package informer
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/openshift-service-mesh/federation/internal/pkg/common"
-	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
@@ -27,20 +28,23 @@ var _ Handler = (*ServiceExportEventHandler)(nil)
 
 // ServiceExportEventHandler processes Service events and triggers proper FDS/MCP pushes if an event matches export rules.
 type ServiceExportEventHandler struct {
-	cfg             config.Federation
-	fdsPushRequests chan<- xds.PushRequest
-	mcpPushRequests chan<- xds.PushRequest
+	exportedServiceLister *common.ExportedServiceLister
+	fdsPushRequests       chan<- xds.PushRequest
+	mcpPushRequests       chan<- xds.PushRequest
+	recorder              *events.Recorder
 }
 
 func NewServiceExportEventHandler(
-	cfg config.Federation,
+	exportedServiceLister *common.ExportedServiceLister,
 	fdsPushRequests,
 	mcpPushRequests chan<- xds.PushRequest,
+	recorder *events.Recorder,
 ) *ServiceExportEventHandler {
 	return &ServiceExportEventHandler{
-		cfg:             cfg,
-		fdsPushRequests: fdsPushRequests,
-		mcpPushRequests: mcpPushRequests,
+		exportedServiceLister: exportedServiceLister,
+		fdsPushRequests:       fdsPushRequests,
+		mcpPushRequests:       mcpPushRequests,
+		recorder:              recorder,
 	}
 }
 
@@ -51,12 +55,20 @@ func (w *ServiceExportEventHandler) Init() error {
 func (w *ServiceExportEventHandler) ObjectCreated(obj runtime.Object) {
 	service := obj.(*corev1.Service)
 	log.Debugf("Created service %s, namespace %s", service.Name, service.Namespace)
+	if w.exportedServiceLister.IsExported(service) {
+		w.recordExportStarted(service, "Service is exported to remote peers")
+	}
 	w.triggerXDSPushIfMatchRules(service)
 }
 
 func (w *ServiceExportEventHandler) ObjectDeleted(obj runtime.Object) {
 	service := obj.(*corev1.Service)
 	log.Debugf("Deleted service %s, namespace %s", service.Name, service.Namespace)
+	if w.exportedServiceLister.IsExported(service) {
+		// The service no longer exists, so the event is recorded on the federation object.
+		w.recorder.Service(nil, service.Namespace, service.Name, corev1.EventTypeNormal, events.ReasonExportStopped,
+			"Service was deleted and is no longer exported to remote peers")
+	}
 	w.triggerXDSPushIfMatchRules(service)
 }
 
@@ -64,25 +76,60 @@ func (w *ServiceExportEventHandler) ObjectUpdated(oldObj, newObj runtime.Object)
 	oldService := oldObj.(*corev1.Service)
 	newService := newObj.(*corev1.Service)
 	log.Debugf("Updated service %s, namespace %s", oldService.Name, oldService.Namespace)
+	wasExported, isExported := w.exportedServiceLister.IsExported(oldService), w.exportedServiceLister.IsExported(newService)
+	switch {
+	case !wasExported && isExported:
+		w.recordExportStarted(newService, "Service matches export rules and is exported to remote peers")
+	case wasExported && !isExported:
+		w.recorder.Service(newService, newService.Namespace, newService.Name, corev1.EventTypeNormal, events.ReasonExportStopped,
+			"Service no longer matches export rules and is not exported to remote peers")
+	case wasExported && isExported && (common.CheckExportable(oldService) == nil) != (common.CheckExportable(newService) == nil):
+		w.recordExportStarted(newService, "Service type is supported and the service is exported to remote peers")
+	}
 	w.triggerXDSPushIfMatchRules(oldService, newService)
+	if wasExported && isExported && !equality.Semantic.DeepEqual(oldService.Spec, newService.Spec) {
+		// Export status depends on the type and ports of the service, so it must be updated
+		// even if the service is still exported.
+		w.mcpPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceTypeUrl}
+	}
+}
+
+// recordExportStarted records that the service matching export rules is exported, or a warning if the service
+// is rejected, because its type is not supported for export.
+func (w *ServiceExportEventHandler) recordExportStarted(svc *corev1.Service, message string) {
+	if err := common.CheckExportable(svc); err != nil {
+		w.recorder.Service(svc, svc.Namespace, svc.Name, corev1.EventTypeWarning, events.ReasonExportStopped,
+			"Service matches export rules, but is not exported to remote peers: %v", err)
+		return
+	}
+	w.recorder.Service(svc, svc.Namespace, svc.Name, corev1.EventTypeNormal, events.ReasonExportStarted, message)
 }
 
 func (w *ServiceExportEventHandler) triggerXDSPushIfMatchRules(services ...*corev1.Service) {
-	exportLabels := w.cfg.ExportedServiceSet.GetLabelSelectors()
 	if len(services) == 2 {
-		if common.MatchExportRules(services[0], exportLabels) != common.MatchExportRules(services[1], exportLabels) {
+		if w.exportedServiceLister.IsExported(services[0]) != w.exportedServiceLister.IsExported(services[1]) {
 			w.triggerXDSPush()
 		}
 	} else {
-		if common.MatchExportRules(services[0], exportLabels) {
+		if w.exportedServiceLister.IsExported(services[0]) {
 			w.triggerXDSPush()
 		}
 	}
 }
 
 func (w *ServiceExportEventHandler) triggerXDSPush() {
-	w.mcpPushRequests <- xds.PushRequest{TypeUrl: xds.GatewayTypeUrl}
-	w.mcpPushRequests <- xds.PushRequest{TypeUrl: xds.EnvoyFilterTypeUrl}
-	w.mcpPushRequests <- xds.PushRequest{TypeUrl: xds.RouteTypeUrl}
-	w.fdsPushRequests <- xds.PushRequest{TypeUrl: xds.ExportedServiceTypeUrl}
+	pushExportedServiceConfigs(w.fdsPushRequests, w.mcpPushRequests)
+}
+
+// pushExportedServiceConfigs triggers generating all resources that depend on the set of exported services.
+func pushExportedServiceConfigs(fdsPushRequests, mcpPushRequests chan<- xds.PushRequest) {
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.GatewayTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.EnvoyFilterTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.RouteTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.TLSRouteTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceExportTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.AuthorizationPolicyTypeUrl}
+	mcpPushRequests <- xds.PushRequest{TypeUrl: xds.ServiceTypeUrl}
+	fdsPushRequests <- xds.PushRequest{TypeUrl: xds.ExportedServiceTypeUrl}
 }
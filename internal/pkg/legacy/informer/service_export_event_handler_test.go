@@ -15,13 +15,17 @@
 package informer
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
@@ -153,7 +157,7 @@ func TestXDSTriggers(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			fdsPushRequests := make(chan xds.PushRequest)
 			mcpPushRequests := make(chan xds.PushRequest)
-			handler := NewServiceExportEventHandler(defaultConfig, fdsPushRequests, mcpPushRequests)
+			handler := NewServiceExportEventHandler(common.NewExportedServiceLister(defaultConfig, nil, nil, nil), fdsPushRequests, mcpPushRequests, nil)
 
 			// ObjectCreated must be called in a goroutine, because mcpPushRequests and fdsPushRequests are unbuffered channels,
 			// so they are blocked until another goroutine reads from the channels.
@@ -164,11 +168,94 @@ func TestXDSTriggers(t *testing.T) {
 			checkChannel(t, mcpPushRequests, xds.GatewayTypeUrl, tc.isTimeoutExpected)
 			checkChannel(t, mcpPushRequests, xds.EnvoyFilterTypeUrl, tc.isTimeoutExpected)
 			checkChannel(t, mcpPushRequests, xds.RouteTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.TLSRouteTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.ServiceEntryTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.ServiceExportTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.AuthorizationPolicyTypeUrl, tc.isTimeoutExpected)
+			checkChannel(t, mcpPushRequests, xds.ServiceTypeUrl, tc.isTimeoutExpected)
 			checkChannel(t, fdsPushRequests, xds.ExportedServiceTypeUrl, tc.isTimeoutExpected)
 		})
 	}
 }
 
+func TestExportEvents(t *testing.T) {
+	exportedLabels := map[string]string{"export": "true"}
+	clusterIPService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns1", Labels: exportedLabels},
+	}
+	externalNameService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns1", Labels: exportedLabels},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "db.example.com"},
+	}
+	rejected := "Warning ExportStopped Service matches export rules, but is not exported to remote peers: " +
+		"service ns1/db is of type ExternalName, which is not supported for export"
+
+	testCases := []struct {
+		name           string
+		handlerFunc    func(handler Handler)
+		expectedEvents []string
+	}{{
+		name: "exported service created",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectCreated(clusterIPService)
+		},
+		expectedEvents: []string{"Normal ExportStarted Service is exported to remote peers"},
+	}, {
+		name: "ExternalName service matching export rules created",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectCreated(externalNameService)
+		},
+		expectedEvents: []string{rejected},
+	}, {
+		name: "ExternalName service starts matching export rules",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns1"},
+				Spec:       externalNameService.Spec,
+			}, externalNameService)
+		},
+		expectedEvents: []string{rejected},
+	}, {
+		name: "exported service changed to ExternalName",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(clusterIPService, externalNameService)
+		},
+		expectedEvents: []string{rejected},
+	}, {
+		name: "exported ExternalName service changed to ClusterIP",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(externalNameService, clusterIPService)
+		},
+		expectedEvents: []string{"Normal ExportStarted Service type is supported and the service is exported to remote peers"},
+	}, {
+		name: "exported service updated without changing its type",
+		handlerFunc: func(handler Handler) {
+			handler.ObjectUpdated(clusterIPService, clusterIPService)
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Channels are buffered, so that pushes triggered by the handler do not block
+			fdsPushRequests := make(chan xds.PushRequest, 100)
+			mcpPushRequests := make(chan xds.PushRequest, 100)
+			fakeRecorder := record.NewFakeRecorder(10)
+			handler := NewServiceExportEventHandler(common.NewExportedServiceLister(defaultConfig, nil, nil, nil),
+				fdsPushRequests, mcpPushRequests, events.NewRecorder(fakeRecorder, nil))
+
+			tc.handlerFunc(handler)
+
+			close(fakeRecorder.Events)
+			var actualEvents []string
+			for event := range fakeRecorder.Events {
+				actualEvents = append(actualEvents, event)
+			}
+			if !reflect.DeepEqual(actualEvents, tc.expectedEvents) {
+				t.Errorf("expected events %v, got %v", tc.expectedEvents, actualEvents)
+			}
+		})
+	}
+}
+
 func checkChannel(t *testing.T, requests <-chan xds.PushRequest, expectedType string, isTimeoutExpected bool) {
 	t.Helper()
 	timeout := time.After(10 * time.Millisecond)
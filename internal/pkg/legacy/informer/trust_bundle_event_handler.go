@@ -0,0 +1,68 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+var _ Handler = (*TrustBundleEventHandler)(nil)
+
+// TrustBundleEventHandler triggers FDS pushes when root certificates of the local mesh change.
+// It expects an informer watching only the ConfigMap or Secret containing the root certificates.
+type TrustBundleEventHandler struct {
+	fdsPushRequests chan<- xds.PushRequest
+}
+
+func NewTrustBundleEventHandler(fdsPushRequests chan<- xds.PushRequest) *TrustBundleEventHandler {
+	return &TrustBundleEventHandler{
+		fdsPushRequests: fdsPushRequests,
+	}
+}
+
+func (h *TrustBundleEventHandler) Init() error {
+	return nil
+}
+
+func (h *TrustBundleEventHandler) ObjectCreated(_ runtime.Object) {
+	h.fdsPushRequests <- xds.PushRequest{TypeUrl: xds.TrustBundleTypeUrl}
+}
+
+func (h *TrustBundleEventHandler) ObjectDeleted(_ runtime.Object) {
+	h.fdsPushRequests <- xds.PushRequest{TypeUrl: xds.TrustBundleTypeUrl}
+}
+
+func (h *TrustBundleEventHandler) ObjectUpdated(oldObj, newObj runtime.Object) {
+	if reflect.DeepEqual(rootCertData(oldObj), rootCertData(newObj)) {
+		return
+	}
+	log.Debugf("Root certificates changed")
+	h.fdsPushRequests <- xds.PushRequest{TypeUrl: xds.TrustBundleTypeUrl}
+}
+
+func rootCertData(obj runtime.Object) any {
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		return o.Data
+	case *corev1.Secret:
+		return o.Data
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	NetworkingV1       = "networking.istio.io/v1"
+	NetworkingV1alpha3 = "networking.istio.io/v1alpha3"
+	SecurityV1         = "security.istio.io/v1"
+	SecurityV1beta1    = "security.istio.io/v1beta1"
+)
+
+// APIVersions holds versions of Istio APIs used to apply generated resources.
+// v1 APIs are preferred, so that the controller keeps working with Istio releases that stop serving
+// the old versions, and the old versions are used only for meshes that do not serve v1 APIs yet.
+// Objects created in the old versions are applied in place using the new version, as all versions share the same storage.
+type APIVersions struct {
+	Networking string
+	Security   string
+}
+
+// DiscoverAPIVersions returns the newest versions of Istio APIs served by the cluster.
+func DiscoverAPIVersions(client discovery.DiscoveryInterface) (APIVersions, error) {
+	networking, err := servedVersion(client, NetworkingV1, NetworkingV1alpha3)
+	if err != nil {
+		return APIVersions{}, err
+	}
+	security, err := servedVersion(client, SecurityV1, SecurityV1beta1)
+	if err != nil {
+		return APIVersions{}, err
+	}
+	return APIVersions{
+		Networking: networking,
+		Security:   security,
+	}, nil
+}
+
+func servedVersion(client discovery.DiscoveryInterface, preferred, fallback string) (string, error) {
+	if _, err := client.ServerResourcesForGroupVersion(preferred); err != nil {
+		if errors.IsNotFound(err) {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("failed to discover resources of %s: %w", preferred, err)
+	}
+	return preferred, nil
+}
+
+// convertSpec converts a spec between versions of the same Istio API.
+// Specs of all versions are generated from the same protos, so their wire format is identical.
+func convertSpec(in, out proto.Message) error {
+	b, err := proto.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", in, err)
+	}
+	if err := proto.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to unmarshal %T: %w", out, err)
+	}
+	return nil
+}
+
+// managedInVersion returns false if the object was applied by the given field manager using another API version.
+// Such objects are applied again to migrate managed fields to the current version without recreating them.
+func managedInVersion(obj metav1.Object, manager, apiVersion string) bool {
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Manager == manager && mf.Operation == metav1.ManagedFieldsOperationApply && mf.APIVersion != apiVersion {
+			return false
+		}
+	}
+	return true
+}
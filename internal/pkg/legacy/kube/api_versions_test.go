@@ -0,0 +1,129 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	istionetv1 "istio.io/api/networking/v1"
+	istionetv1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscoverAPIVersions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		groupVersions []string
+		expected      APIVersions
+	}{{
+		name:          "v1 APIs should be preferred when served",
+		groupVersions: []string{NetworkingV1alpha3, NetworkingV1, SecurityV1beta1, SecurityV1},
+		expected:      APIVersions{Networking: NetworkingV1, Security: SecurityV1},
+	}, {
+		name:          "old versions should be used when v1 APIs are not served",
+		groupVersions: []string{NetworkingV1alpha3, SecurityV1beta1},
+		expected:      APIVersions{Networking: NetworkingV1alpha3, Security: SecurityV1beta1},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			discovery := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			for _, gv := range tc.groupVersions {
+				discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{GroupVersion: gv})
+			}
+
+			actual, err := DiscoverAPIVersions(discovery)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConvertSpec(t *testing.T) {
+	in := &istionetv1.ServiceEntry{
+		Hosts: []string{"a.ns1.svc.cluster.local"},
+		Ports: []*istionetv1.ServicePort{{Name: "http", Number: 80, Protocol: "HTTP", TargetPort: 8080}},
+		Endpoints: []*istionetv1.WorkloadEntry{{
+			Address: "1.1.1.1",
+			Labels:  map[string]string{"security.istio.io/tlsMode": "istio"},
+			Ports:   map[string]uint32{"http": 15443},
+			Network: "west-network",
+		}},
+		Location:   istionetv1.ServiceEntry_MESH_INTERNAL,
+		Resolution: istionetv1.ServiceEntry_STATIC,
+	}
+	expected := &istionetv1alpha3.ServiceEntry{
+		Hosts: []string{"a.ns1.svc.cluster.local"},
+		Ports: []*istionetv1alpha3.ServicePort{{Name: "http", Number: 80, Protocol: "HTTP", TargetPort: 8080}},
+		Endpoints: []*istionetv1alpha3.WorkloadEntry{{
+			Address: "1.1.1.1",
+			Labels:  map[string]string{"security.istio.io/tlsMode": "istio"},
+			Ports:   map[string]uint32{"http": 15443},
+			Network: "west-network",
+		}},
+		Location:   istionetv1alpha3.ServiceEntry_MESH_INTERNAL,
+		Resolution: istionetv1alpha3.ServiceEntry_STATIC,
+	}
+
+	actual := &istionetv1alpha3.ServiceEntry{}
+	if err := convertSpec(in, actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !proto.Equal(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestManagedInVersion(t *testing.T) {
+	testCases := []struct {
+		name          string
+		managedFields []metav1.ManagedFieldsEntry
+		expected      bool
+	}{{
+		name: "object applied in the current version should not be migrated",
+		managedFields: []metav1.ManagedFieldsEntry{{
+			Manager: "federation-controller", Operation: metav1.ManagedFieldsOperationApply, APIVersion: NetworkingV1,
+		}},
+		expected: true,
+	}, {
+		name: "object applied in the old version should be migrated",
+		managedFields: []metav1.ManagedFieldsEntry{{
+			Manager: "federation-controller", Operation: metav1.ManagedFieldsOperationApply, APIVersion: NetworkingV1alpha3,
+		}},
+		expected: false,
+	}, {
+		name: "fields managed by other managers in the old version should be ignored",
+		managedFields: []metav1.ManagedFieldsEntry{{
+			Manager: "federation-controller", Operation: metav1.ManagedFieldsOperationApply, APIVersion: NetworkingV1,
+		}, {
+			Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: NetworkingV1alpha3,
+		}},
+		expected: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{ManagedFields: tc.managedFields}
+			if actual := managedInVersion(obj, fieldManager, NetworkingV1); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+	"reflect"
+
+	securityv1 "istio.io/client-go/pkg/apis/security/v1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	applyv1 "istio.io/client-go/pkg/applyconfiguration/security/v1"
+	applyv1beta1 "istio.io/client-go/pkg/applyconfiguration/security/v1beta1"
+	"istio.io/istio/pkg/kube"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+// NewAuthorizationPolicyReconciler creates a reconciler of authorization policy resources in the security API version served by the cluster.
+func NewAuthorizationPolicyReconciler(client kube.Client, cf *istio.ConfigFactory, apiVersions APIVersions, opts ReconcileOptions) Reconciler {
+	if apiVersions.Security == SecurityV1beta1 {
+		return newAuthorizationPolicyReconcilerV1beta1(client, cf, opts)
+	}
+	return NewResourceReconciler(ResourceReconcilerConfig[*securityv1.AuthorizationPolicy, *applyv1.AuthorizationPolicyApplyConfiguration]{
+		TypeUrl: xds.AuthorizationPolicyTypeUrl,
+		Name:    "authorization policy",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*securityv1.AuthorizationPolicy, *applyv1.AuthorizationPolicyApplyConfiguration, *securityv1.AuthorizationPolicyList] {
+				return client.Istio().SecurityV1().AuthorizationPolicies(namespace)
+			},
+			func(list *securityv1.AuthorizationPolicyList) []*securityv1.AuthorizationPolicy {
+				return list.Items
+			},
+		),
+		Desired: cf.AuthorizationPolicies,
+		UpToDate: func(live, desired *securityv1.AuthorizationPolicy) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && managedInVersion(live, opts.fieldManager(), SecurityV1)
+		},
+		ApplyConfiguration: func(ap *securityv1.AuthorizationPolicy) *applyv1.AuthorizationPolicyApplyConfiguration {
+			ac := applyv1.AuthorizationPolicy(ap.Name, ap.Namespace).WithLabels(ap.Labels)
+			ac.Spec = &ap.Spec
+			return ac
+		},
+	}, opts)
+}
+
+// newAuthorizationPolicyReconcilerV1beta1 applies resources in v1beta1 for meshes that do not serve security.istio.io/v1.
+func newAuthorizationPolicyReconcilerV1beta1(client kube.Client, cf *istio.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1beta1.AuthorizationPolicy, *applyv1beta1.AuthorizationPolicyApplyConfiguration]{
+		TypeUrl: xds.AuthorizationPolicyTypeUrl,
+		Name:    "authorization policy",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1beta1.AuthorizationPolicy, *applyv1beta1.AuthorizationPolicyApplyConfiguration, *v1beta1.AuthorizationPolicyList] {
+				return client.Istio().SecurityV1beta1().AuthorizationPolicies(namespace)
+			},
+			func(list *v1beta1.AuthorizationPolicyList) []*v1beta1.AuthorizationPolicy {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1beta1.AuthorizationPolicy, error) {
+			authorizationPolicies, err := cf.AuthorizationPolicies()
+			if err != nil {
+				return nil, err
+			}
+			converted := make([]*v1beta1.AuthorizationPolicy, 0, len(authorizationPolicies))
+			for _, ap := range authorizationPolicies {
+				c := &v1beta1.AuthorizationPolicy{ObjectMeta: ap.ObjectMeta}
+				if err := convertSpec(&ap.Spec, &c.Spec); err != nil {
+					return nil, fmt.Errorf("failed to convert authorization policy %s/%s to v1beta1: %w", ap.Namespace, ap.Name, err)
+				}
+				converted = append(converted, c)
+			}
+			return converted, nil
+		},
+		UpToDate: func(live, desired *v1beta1.AuthorizationPolicy) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: func(ap *v1beta1.AuthorizationPolicy) *applyv1beta1.AuthorizationPolicyApplyConfiguration {
+			ac := applyv1beta1.AuthorizationPolicy(ap.Name, ap.Namespace).WithLabels(ap.Labels)
+			ac.Spec = &ap.Spec
+			return ac
+		},
+	}, opts)
+}
@@ -0,0 +1,98 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+
+	certificatesv1alpha1 "k8s.io/api/certificates/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	applycertificatesv1alpha1 "k8s.io/client-go/applyconfigurations/certificates/v1alpha1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/fds"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+const trustDomainAnnotation = "federation.openshift-service-mesh.io/trust-domain"
+
+// NewClusterTrustBundleReconciler creates a reconciler writing root certificates received from remote peers
+// into ClusterTrustBundles, so that meshes using independent root CAs can validate certificates of each other.
+func NewClusterTrustBundleReconciler(
+	client kubernetes.Interface,
+	remotes []config.Remote,
+	store *fds.TrustBundleStore,
+	opts ReconcileOptions,
+) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*certificatesv1alpha1.ClusterTrustBundle, *applycertificatesv1alpha1.ClusterTrustBundleApplyConfiguration]{
+		TypeUrl: xds.ClusterTrustBundleTypeUrl,
+		Name:    "cluster trust bundle",
+		Client: newNamespacedClient(
+			// ClusterTrustBundles are cluster-scoped
+			func(string) typedClient[*certificatesv1alpha1.ClusterTrustBundle, *applycertificatesv1alpha1.ClusterTrustBundleApplyConfiguration, *certificatesv1alpha1.ClusterTrustBundleList] {
+				return client.CertificatesV1alpha1().ClusterTrustBundles()
+			},
+			func(list *certificatesv1alpha1.ClusterTrustBundleList) []*certificatesv1alpha1.ClusterTrustBundle {
+				trustBundles := make([]*certificatesv1alpha1.ClusterTrustBundle, 0, len(list.Items))
+				for i := range list.Items {
+					trustBundles = append(trustBundles, &list.Items[i])
+				}
+				return trustBundles
+			},
+		),
+		Desired: func() ([]*certificatesv1alpha1.ClusterTrustBundle, error) {
+			return clusterTrustBundles(remotes, store, opts.Peer), nil
+		},
+		UpToDate: func(live, desired *certificatesv1alpha1.ClusterTrustBundle) bool {
+			return live.Spec.TrustBundle == desired.Spec.TrustBundle &&
+				live.Annotations[trustDomainAnnotation] == desired.Annotations[trustDomainAnnotation]
+		},
+		ApplyConfiguration: func(ctb *certificatesv1alpha1.ClusterTrustBundle) *applycertificatesv1alpha1.ClusterTrustBundleApplyConfiguration {
+			return applycertificatesv1alpha1.ClusterTrustBundle(ctb.Name).
+				WithLabels(ctb.Labels).
+				WithAnnotations(ctb.Annotations).
+				WithSpec(applycertificatesv1alpha1.ClusterTrustBundleSpec().WithTrustBundle(ctb.Spec.TrustBundle))
+		},
+	}, opts)
+}
+
+func clusterTrustBundles(remotes []config.Remote, store *fds.TrustBundleStore, peer string) []*certificatesv1alpha1.ClusterTrustBundle {
+	var trustBundles []*certificatesv1alpha1.ClusterTrustBundle
+	for _, remote := range remotes {
+		if !remote.ImportTrustBundle {
+			continue
+		}
+		trustBundle := store.From(remote)
+		if trustBundle == nil || trustBundle.RootCertificates == "" {
+			continue
+		}
+		if trustBundle.TrustDomain != remote.GetTrustDomain() {
+			log.Warnf("Trust domain %s received from remote %s does not match configured trust domain %s",
+				trustBundle.TrustDomain, remote.Name, remote.GetTrustDomain())
+		}
+		trustBundles = append(trustBundles, &certificatesv1alpha1.ClusterTrustBundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("federation-%s", remote.Name),
+				Labels:      config.PeerLabels(peer),
+				Annotations: map[string]string{trustDomainAnnotation: trustBundle.TrustDomain},
+			},
+			Spec: certificatesv1alpha1.ClusterTrustBundleSpec{
+				TrustBundle: trustBundle.RootCertificates,
+			},
+		})
+	}
+	return trustBundles
+}
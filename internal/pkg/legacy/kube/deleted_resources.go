@@ -0,0 +1,70 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeletedResources records generated resources deleted by reconcilers, so that the drift detector can tell these
+// deletions apart from deletions made by anyone else. A nil DeletedResources records nothing.
+type DeletedResources struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func NewDeletedResources() *DeletedResources {
+	return &DeletedResources{keys: make(map[string]struct{})}
+}
+
+// record marks the resource as deleted by a reconciler. It must be called before the delete request is sent,
+// because the drift detector may observe the deletion before the request returns.
+func (d *DeletedResources) record(typeUrl string, obj metav1.Object) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.keys[deletedResourceKey(typeUrl, obj)] = struct{}{}
+}
+
+// forget removes the record of a resource, which was not deleted by the request.
+func (d *DeletedResources) forget(typeUrl string, obj metav1.Object) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.keys, deletedResourceKey(typeUrl, obj))
+}
+
+// consume returns true if the resource was deleted by a reconciler and removes the record.
+func (d *DeletedResources) consume(typeUrl string, obj metav1.Object) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := deletedResourceKey(typeUrl, obj)
+	_, found := d.keys[key]
+	delete(d.keys, key)
+	return found
+}
+
+func deletedResourceKey(typeUrl string, obj metav1.Object) string {
+	return typeUrl + "/" + keyOf(obj).String()
+}
@@ -15,108 +15,81 @@
 package kube
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 
+	networkingv1 "istio.io/client-go/pkg/apis/networking/v1"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
-	applyconfigurationv1 "istio.io/client-go/pkg/applyconfiguration/meta/v1"
+	applyv1 "istio.io/client-go/pkg/applyconfiguration/networking/v1"
 	applyv1alpha3 "istio.io/client-go/pkg/applyconfiguration/networking/v1alpha3"
 	"istio.io/istio/pkg/kube"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
-var _ Reconciler = (*DestinationRuleReconciler)(nil)
-
-type DestinationRuleReconciler struct {
-	client kube.Client
-	cf     *istio.ConfigFactory
-}
-
-func NewDestinationRuleReconciler(client kube.Client, cf *istio.ConfigFactory) *DestinationRuleReconciler {
-	return &DestinationRuleReconciler{
-		client: client,
-		cf:     cf,
+// NewDestinationRuleReconciler creates a reconciler of destination rule resources in the networking API version served by the cluster.
+func NewDestinationRuleReconciler(client kube.Client, cf *istio.ConfigFactory, apiVersions APIVersions, opts ReconcileOptions) Reconciler {
+	if apiVersions.Networking == NetworkingV1alpha3 {
+		return newDestinationRuleReconcilerV1alpha3(client, cf, opts)
 	}
+	return NewResourceReconciler(ResourceReconcilerConfig[*networkingv1.DestinationRule, *applyv1.DestinationRuleApplyConfiguration]{
+		TypeUrl: xds.DestinationRuleTypeUrl,
+		Name:    "destination rule",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*networkingv1.DestinationRule, *applyv1.DestinationRuleApplyConfiguration, *networkingv1.DestinationRuleList] {
+				return client.Istio().NetworkingV1().DestinationRules(namespace)
+			},
+			func(list *networkingv1.DestinationRuleList) []*networkingv1.DestinationRule {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*networkingv1.DestinationRule, error) {
+			return cf.DestinationRules(), nil
+		},
+		UpToDate: func(live, desired *networkingv1.DestinationRule) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && managedInVersion(live, opts.fieldManager(), NetworkingV1)
+		},
+		ApplyConfiguration: func(dr *networkingv1.DestinationRule) *applyv1.DestinationRuleApplyConfiguration {
+			ac := applyv1.DestinationRule(dr.Name, dr.Namespace).WithLabels(dr.Labels)
+			ac.Spec = &dr.Spec
+			return ac
+		},
+	}, opts)
 }
 
-func (r *DestinationRuleReconciler) GetTypeUrl() string {
-	return xds.DestinationRuleTypeUrl
-}
-
-func (r *DestinationRuleReconciler) Reconcile(ctx context.Context) error {
-	destinationRules := r.cf.DestinationRules()
-	if len(destinationRules) == 0 {
-		return nil
-	}
-
-	destinationRulesMap := make(map[types.NamespacedName]*v1alpha3.DestinationRule, len(destinationRules))
-	for _, dr := range destinationRules {
-		destinationRulesMap[types.NamespacedName{Namespace: dr.Namespace, Name: dr.Name}] = dr
-	}
-
-	oldDestinationRules, err := r.client.Istio().NetworkingV1alpha3().DestinationRules(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
-		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{
-			MatchLabels: map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-		}),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list destination rules: %w", err)
-	}
-	oldDestinationRulesMap := make(map[types.NamespacedName]*v1alpha3.DestinationRule, len(oldDestinationRules.Items))
-	for _, dr := range oldDestinationRules.Items {
-		oldDestinationRulesMap[types.NamespacedName{Namespace: dr.Namespace, Name: dr.Name}] = dr
-	}
-
-	kind := "DestinationRule"
-	apiVersion := "networking.istio.io/v1alpha3"
-	for k, dr := range destinationRulesMap {
-		oldDR, ok := oldDestinationRulesMap[k]
-		if !ok || !reflect.DeepEqual(&oldDR.Spec, &dr.Spec) {
-			// Destination rule does not currently exist or requires update
-			newDR, err := r.client.Istio().NetworkingV1alpha3().DestinationRules(dr.GetNamespace()).Apply(ctx,
-				&applyv1alpha3.DestinationRuleApplyConfiguration{
-					TypeMetaApplyConfiguration: applyconfigurationv1.TypeMetaApplyConfiguration{
-						Kind:       &kind,
-						APIVersion: &apiVersion,
-					},
-					ObjectMetaApplyConfiguration: &applyconfigurationv1.ObjectMetaApplyConfiguration{
-						Name:      &dr.Name,
-						Namespace: &dr.Namespace,
-						Labels:    dr.Labels,
-					},
-					Spec: &dr.Spec,
-				},
-				metav1.ApplyOptions{
-					TypeMeta: metav1.TypeMeta{
-						Kind:       kind,
-						APIVersion: apiVersion,
-					},
-					Force:        true,
-					FieldManager: "federation-controller",
-				},
-			)
-			if err != nil {
-				return fmt.Errorf("failed to apply destination rule: %w", err)
-			}
-			log.Infof("Applied destination rule: %v", newDR)
-		}
-	}
-
-	for k, oldDR := range oldDestinationRulesMap {
-		if _, ok := destinationRulesMap[k]; !ok {
-			err := r.client.Istio().NetworkingV1alpha3().DestinationRules(oldDR.GetNamespace()).Delete(ctx, oldDR.GetName(), metav1.DeleteOptions{})
-			if client.IgnoreNotFound(err) != nil {
-				return fmt.Errorf("failed to delete old destination rule: %w", err)
+// newDestinationRuleReconcilerV1alpha3 applies resources in v1alpha3 for meshes that do not serve networking.istio.io/v1.
+func newDestinationRuleReconcilerV1alpha3(client kube.Client, cf *istio.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1alpha3.DestinationRule, *applyv1alpha3.DestinationRuleApplyConfiguration]{
+		TypeUrl: xds.DestinationRuleTypeUrl,
+		Name:    "destination rule",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1alpha3.DestinationRule, *applyv1alpha3.DestinationRuleApplyConfiguration, *v1alpha3.DestinationRuleList] {
+				return client.Istio().NetworkingV1alpha3().DestinationRules(namespace)
+			},
+			func(list *v1alpha3.DestinationRuleList) []*v1alpha3.DestinationRule {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1alpha3.DestinationRule, error) {
+			destinationRules := cf.DestinationRules()
+			converted := make([]*v1alpha3.DestinationRule, 0, len(destinationRules))
+			for _, dr := range destinationRules {
+				c := &v1alpha3.DestinationRule{ObjectMeta: dr.ObjectMeta}
+				if err := convertSpec(&dr.Spec, &c.Spec); err != nil {
+					return nil, fmt.Errorf("failed to convert destination rule %s/%s to v1alpha3: %w", dr.Namespace, dr.Name, err)
+				}
+				converted = append(converted, c)
 			}
-			log.Infof("Deleted destination rule: %v", oldDR)
-		}
-	}
-
-	return nil
+			return converted, nil
+		},
+		UpToDate: func(live, desired *v1alpha3.DestinationRule) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: func(dr *v1alpha3.DestinationRule) *applyv1alpha3.DestinationRuleApplyConfiguration {
+			ac := applyv1alpha3.DestinationRule(dr.Name, dr.Namespace).WithLabels(dr.Labels)
+			ac.Spec = &dr.Spec
+			return ac
+		},
+	}, opts)
 }
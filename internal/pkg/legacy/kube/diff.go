@@ -0,0 +1,83 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// serverManagedMetadata lists metadata fields set by the API server, which are not part of the configuration.
+var serverManagedMetadata = []string{"creationTimestamp", "generation", "managedFields", "resourceVersion", "uid"}
+
+// unifiedDiff returns a unified diff between YAML representations of the live and the applied object.
+// The live object is nil if it does not exist. Status and server-managed metadata are omitted.
+func unifiedDiff(live, applied any) (string, error) {
+	from, err := configurationYAML(live)
+	if err != nil {
+		return "", err
+	}
+	to, err := configurationYAML(applied)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines(from),
+		B:        lines(to),
+		FromFile: "live",
+		ToFile:   "applied",
+		Context:  3,
+	})
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func configurationYAML(obj any) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize %T: %w", obj, err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("failed to deserialize %T: %w", obj, err)
+	}
+	delete(fields, "status")
+	if metadata, ok := fields["metadata"].(map[string]any); ok {
+		for _, f := range serverManagedMetadata {
+			delete(metadata, f)
+		}
+	}
+	out, err := yaml.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize %T to YAML: %w", obj, err)
+	}
+	return string(out), nil
+}
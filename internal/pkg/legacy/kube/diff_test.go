@@ -0,0 +1,89 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"testing"
+
+	istiosecurityv1 "istio.io/api/security/v1"
+	securityv1 "istio.io/client-go/pkg/apis/security/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	peerAuthentication := func(mode istiosecurityv1.PeerAuthentication_MutualTLS_Mode, resourceVersion string) *securityv1.PeerAuthentication {
+		return &securityv1.PeerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "fds-strict-mtls",
+				Namespace:       "istio-system",
+				ResourceVersion: resourceVersion,
+				Generation:      2,
+			},
+			Spec: istiosecurityv1.PeerAuthentication{
+				Mtls: &istiosecurityv1.PeerAuthentication_MutualTLS{Mode: mode},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		live         any
+		applied      any
+		expectedDiff string
+	}{{
+		name:    "resource does not exist",
+		live:    nil,
+		applied: peerAuthentication(istiosecurityv1.PeerAuthentication_MutualTLS_STRICT, "1"),
+		expectedDiff: `--- live
++++ applied
+@@ -0,0 +1,6 @@
++metadata:
++  name: fds-strict-mtls
++  namespace: istio-system
++spec:
++  mtls:
++    mode: STRICT
+`,
+	}, {
+		name:    "spec changed",
+		live:    peerAuthentication(istiosecurityv1.PeerAuthentication_MutualTLS_PERMISSIVE, "1"),
+		applied: peerAuthentication(istiosecurityv1.PeerAuthentication_MutualTLS_STRICT, "2"),
+		expectedDiff: `--- live
++++ applied
+@@ -3,4 +3,4 @@
+   namespace: istio-system
+ spec:
+   mtls:
+-    mode: PERMISSIVE
++    mode: STRICT
+`,
+	}, {
+		name:         "only server-managed metadata changed",
+		live:         peerAuthentication(istiosecurityv1.PeerAuthentication_MutualTLS_STRICT, "1"),
+		applied:      peerAuthentication(istiosecurityv1.PeerAuthentication_MutualTLS_STRICT, "2"),
+		expectedDiff: "",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := unifiedDiff(tc.live, tc.applied)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff != tc.expectedDiff {
+				t.Errorf("expected diff:\n%s\ngot:\n%s", tc.expectedDiff, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+const (
+	fieldManager = "federation-controller"
+
+	driftOperationModified = "modified"
+	driftOperationDeleted  = "deleted"
+
+	// recreateTimeout limits how long a deleted resource is watched for being restored by the owning reconciler.
+	// Resources that are not restored were deleted, because they are not desired anymore.
+	recreateTimeout = 10 * time.Second
+)
+
+var driftedResources = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "federation_generated_resources_drift_total",
+		Help: "Number of generated resources modified or deleted by other managers than the federation controller.",
+	},
+	[]string{"resource", "operation"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftedResources)
+}
+
+// DriftDetector watches generated resources and enqueues the owning reconcilers when the resources
+// are modified or deleted by anyone else than the controller, so that they are restored immediately.
+// Only resources labeled with the federation peer label of the local peer are watched, and resources labeled
+// by previous versions of the controller only in namespaces where they are adopted.
+type DriftDetector struct {
+	client       dynamic.Interface
+	resources    map[string]schema.GroupVersionResource
+	pushRequests chan<- xds.PushRequest
+	recorder     record.EventRecorder
+	isLeading    func() bool
+	opts         ReconcileOptions
+}
+
+// NewDriftDetector creates a detector for resources owned by the given reconcilers.
+// Drift is recorded in metrics and events only if isLeading returns true, as only the leader restores resources.
+func NewDriftDetector(
+	client dynamic.Interface,
+	apiVersions APIVersions,
+	reconcilers []Reconciler,
+	pushRequests chan<- xds.PushRequest,
+	recorder record.EventRecorder,
+	isLeading func() bool,
+	opts ReconcileOptions,
+) *DriftDetector {
+	generatedResources := GeneratedResources(apiVersions)
+	resources := make(map[string]schema.GroupVersionResource, len(reconcilers))
+	for _, r := range reconcilers {
+		if gvr, ok := generatedResources[r.GetTypeUrl()]; ok {
+			resources[r.GetTypeUrl()] = gvr
+		}
+	}
+	return &DriftDetector{
+		client:       client,
+		resources:    resources,
+		pushRequests: pushRequests,
+		recorder:     recorder,
+		isLeading:    isLeading,
+		opts:         opts,
+	}
+}
+
+// Start starts informers for generated resources and waits until their caches are synced.
+func (d *DriftDetector) Start(ctx context.Context) error {
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(d.client, 0, metav1.NamespaceAll,
+		func(opts *metav1.ListOptions) {
+			opts.LabelSelector = config.PeerLabelSelector(d.opts.Peer)
+		})
+	for typeUrl, gvr := range d.resources {
+		informer := informerFactory.ForResource(gvr).Informer()
+		if _, err := informer.AddEventHandler(d.eventHandler(ctx, typeUrl, gvr, informer.GetStore())); err != nil {
+			return fmt.Errorf("failed to add event handler for %s: %w", gvr, err)
+		}
+	}
+	informerFactory.Start(ctx.Done())
+
+	for gvr, synced := range informerFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync cache of %s", gvr)
+		}
+	}
+	return nil
+}
+
+func (d *DriftDetector) eventHandler(ctx context.Context, typeUrl string, gvr schema.GroupVersionResource, store cache.Store) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj any) {
+			oldRes, ok := oldObj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			newRes, ok := newObj.(*unstructured.Unstructured)
+			if !ok {
+				return
+			}
+			// Changes of metadata and status are not drift, as reconcilers apply only labels and specs
+			if oldRes.GetGeneration() == newRes.GetGeneration() || !d.opts.owns(newRes) {
+				return
+			}
+			manager := lastManager(newRes)
+			if manager == d.opts.fieldManager() {
+				return
+			}
+			d.recordDrift(gvr, newRes, driftOperationModified, manager)
+			d.pushRequests <- xds.PushRequest{TypeUrl: typeUrl}
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			res, ok := obj.(*unstructured.Unstructured)
+			if !ok || !d.opts.owns(res) {
+				return
+			}
+			// Resources deleted by reconcilers are not desired anymore.
+			if d.opts.DeletedResources.consume(typeUrl, res) {
+				return
+			}
+			d.pushRequests <- xds.PushRequest{TypeUrl: typeUrl}
+
+			// A deletion is drift only if the owning reconciler restores the resource, as deletions made by the leader
+			// running in another replica are not recorded.
+			key, err := cache.MetaNamespaceKeyFunc(res)
+			if err != nil {
+				return
+			}
+			time.AfterFunc(recreateTimeout, func() {
+				if ctx.Err() != nil {
+					return
+				}
+				item, exists, err := store.GetByKey(key)
+				if err != nil || !exists {
+					return
+				}
+				if restored, ok := item.(*unstructured.Unstructured); ok && restored.GetUID() != res.GetUID() {
+					d.recordDrift(gvr, restored, driftOperationDeleted, "")
+				}
+			})
+		},
+	}
+}
+
+func (d *DriftDetector) recordDrift(gvr schema.GroupVersionResource, obj *unstructured.Unstructured, operation, manager string) {
+	if !d.isLeading() {
+		return
+	}
+	log.Infof("Restoring %s %s/%s %s by %q", gvr.Resource, obj.GetNamespace(), obj.GetName(), operation, manager)
+	driftedResources.WithLabelValues(gvr.GroupResource().String(), operation).Inc()
+
+	message := fmt.Sprintf("Generated resource was %s, configuration restored by %s", operation, d.opts.fieldManager())
+	if manager != "" {
+		message = fmt.Sprintf("Generated resource was %s by %s, configuration restored by %s", operation, manager, d.opts.fieldManager())
+	}
+	d.recorder.Event(obj, corev1.EventTypeWarning, "DriftCorrected", message)
+}
+
+// lastManager returns the manager that most recently changed the object, excluding changes of subresources.
+func lastManager(obj metav1.Object) string {
+	var manager string
+	var lastUpdate time.Time
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Subresource != "" || mf.Time == nil {
+			continue
+		}
+		if !mf.Time.Time.Before(lastUpdate) {
+			manager = mf.Manager
+			lastUpdate = mf.Time.Time
+		}
+	}
+	return manager
+}
@@ -0,0 +1,187 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+// eastFieldManager is the field manager applying resources of the local peer in tests.
+var eastFieldManager = ReconcileOptions{Peer: "east"}.fieldManager()
+
+func TestDriftDetector(t *testing.T) {
+	apiVersions := APIVersions{Networking: NetworkingV1, Security: SecurityV1}
+	gvr := GeneratedResources(apiVersions)[xds.ServiceEntryTypeUrl]
+
+	testCases := []struct {
+		name        string
+		generation  int64
+		manager     string
+		leading     bool
+		expectPush  bool
+		expectEvent bool
+	}{{
+		name:        "spec modified by another manager",
+		generation:  2,
+		manager:     "kubectl-edit",
+		leading:     true,
+		expectPush:  true,
+		expectEvent: true,
+	}, {
+		name:        "spec modified by another manager is not recorded by followers",
+		generation:  2,
+		manager:     "kubectl-edit",
+		leading:     false,
+		expectPush:  true,
+		expectEvent: false,
+	}, {
+		name:       "spec modified by the controller",
+		generation: 2,
+		manager:    eastFieldManager,
+		leading:    true,
+	}, {
+		name:       "metadata modified by another manager",
+		generation: 1,
+		manager:    "kubectl-label",
+		leading:    true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			se := serviceEntry(1, eastFieldManager)
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ServiceEntryList"}, se)
+			pushRequests := make(chan xds.PushRequest, 1)
+			recorder := record.NewFakeRecorder(1)
+			detector := NewDriftDetector(client, apiVersions, []Reconciler{&countingReconciler{}}, pushRequests, recorder,
+				func() bool { return tc.leading }, ReconcileOptions{Peer: "east", LegacyNamespaces: []string{"istio-system"}})
+			if err := detector.Start(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := client.Resource(gvr).Namespace("istio-system").Update(ctx, serviceEntry(tc.generation, tc.manager), metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case req := <-pushRequests:
+				if !tc.expectPush {
+					t.Errorf("unexpected push request: %v", req)
+				}
+				if req.TypeUrl != xds.ServiceEntryTypeUrl {
+					t.Errorf("expected push request for %s, got %s", xds.ServiceEntryTypeUrl, req.TypeUrl)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tc.expectPush {
+					t.Errorf("expected push request for %s", xds.ServiceEntryTypeUrl)
+				}
+			}
+			if gotEvent := len(recorder.Events) > 0; gotEvent != tc.expectEvent {
+				t.Errorf("expected event: %t, got: %t", tc.expectEvent, gotEvent)
+			}
+		})
+	}
+}
+
+func TestDriftDetectorDeletion(t *testing.T) {
+	apiVersions := APIVersions{Networking: NetworkingV1, Security: SecurityV1}
+	gvr := GeneratedResources(apiVersions)[xds.ServiceEntryTypeUrl]
+
+	testCases := []struct {
+		name                string
+		manager             string
+		deletedByReconciler bool
+		expectPush          bool
+	}{{
+		name:       "resource last modified by another manager",
+		manager:    "kubectl-edit",
+		expectPush: true,
+	}, {
+		name:       "untouched resource deleted manually",
+		manager:    eastFieldManager,
+		expectPush: true,
+	}, {
+		name:                "resource deleted by a reconciler",
+		manager:             eastFieldManager,
+		deletedByReconciler: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "ServiceEntryList"}, serviceEntry(1, tc.manager))
+			pushRequests := make(chan xds.PushRequest, 1)
+			opts := ReconcileOptions{Peer: "east", LegacyNamespaces: []string{"istio-system"}, DeletedResources: NewDeletedResources()}
+			detector := NewDriftDetector(client, apiVersions, []Reconciler{&countingReconciler{}}, pushRequests, record.NewFakeRecorder(1),
+				func() bool { return true }, opts)
+			if err := detector.Start(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.deletedByReconciler {
+				opts.DeletedResources.record(xds.ServiceEntryTypeUrl, serviceEntry(1, tc.manager))
+			}
+
+			if err := client.Resource(gvr).Namespace("istio-system").Delete(ctx, "import-a", metav1.DeleteOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case req := <-pushRequests:
+				if !tc.expectPush {
+					t.Errorf("unexpected push request: %v", req)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tc.expectPush {
+					t.Errorf("expected push request for %s", xds.ServiceEntryTypeUrl)
+				}
+			}
+		})
+	}
+}
+
+func serviceEntry(generation int64, manager string) *unstructured.Unstructured {
+	se := &unstructured.Unstructured{}
+	se.SetAPIVersion(NetworkingV1)
+	se.SetKind("ServiceEntry")
+	se.SetNamespace("istio-system")
+	se.SetName("import-a")
+	se.SetLabels(map[string]string{"federation.openshift-service-mesh.io/peer": "todo"})
+	se.SetGeneration(generation)
+	se.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager:   eastFieldManager,
+		Operation: metav1.ManagedFieldsOperationApply,
+		Time:      &metav1.Time{Time: time.Now().Add(-time.Hour)},
+	}, {
+		Manager:   manager,
+		Operation: metav1.ManagedFieldsOperationUpdate,
+		Time:      &metav1.Time{Time: time.Now()},
+	}})
+	return se
+}
@@ -15,108 +15,39 @@
 package kube
 
 import (
-	"context"
-	"fmt"
 	"reflect"
 
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
-	applyconfigurationv1 "istio.io/client-go/pkg/applyconfiguration/meta/v1"
 	applyv1alpha3 "istio.io/client-go/pkg/applyconfiguration/networking/v1alpha3"
 	"istio.io/istio/pkg/kube"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
-var _ Reconciler = (*EnvoyFilterReconciler)(nil)
-
-type EnvoyFilterReconciler struct {
-	client kube.Client
-	cf     *istio.ConfigFactory
-}
-
-func NewEnvoyFilterReconciler(client kube.Client, cf *istio.ConfigFactory) *EnvoyFilterReconciler {
-	return &EnvoyFilterReconciler{
-		client: client,
-		cf:     cf,
-	}
-}
-
-func (r *EnvoyFilterReconciler) GetTypeUrl() string {
-	return xds.EnvoyFilterTypeUrl
-}
-
-func (r *EnvoyFilterReconciler) Reconcile(ctx context.Context) error {
-	envoyFilters := r.cf.EnvoyFilters()
-	if len(envoyFilters) == 0 {
-		return nil
-	}
-
-	envoyFiltersMap := make(map[types.NamespacedName]*v1alpha3.EnvoyFilter, len(envoyFilters))
-	for _, ef := range envoyFilters {
-		envoyFiltersMap[types.NamespacedName{Namespace: ef.Namespace, Name: ef.Name}] = ef
-	}
-
-	oldEnvoyFilters, err := r.client.Istio().NetworkingV1alpha3().EnvoyFilters(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
-		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{
-			MatchLabels: map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-		}),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list envoy filters: %w", err)
-	}
-	oldEnvoyFiltersMap := make(map[types.NamespacedName]*v1alpha3.EnvoyFilter, len(oldEnvoyFilters.Items))
-	for _, ef := range oldEnvoyFilters.Items {
-		oldEnvoyFiltersMap[types.NamespacedName{Namespace: ef.Namespace, Name: ef.Name}] = ef
-	}
-
-	kind := "EnvoyFilter"
-	apiVersion := "networking.istio.io/v1alpha3"
-	for k, ef := range envoyFiltersMap {
-		oldEF, ok := oldEnvoyFiltersMap[k]
-		if !ok || !reflect.DeepEqual(&oldEF.Spec, &ef.Spec) {
-			// Envoy filter does not currently exist or requires update
-			newEF, err := r.client.Istio().NetworkingV1alpha3().EnvoyFilters(ef.GetNamespace()).Apply(ctx,
-				&applyv1alpha3.EnvoyFilterApplyConfiguration{
-					TypeMetaApplyConfiguration: applyconfigurationv1.TypeMetaApplyConfiguration{
-						Kind:       &kind,
-						APIVersion: &apiVersion,
-					},
-					ObjectMetaApplyConfiguration: &applyconfigurationv1.ObjectMetaApplyConfiguration{
-						Name:      &ef.Name,
-						Namespace: &ef.Namespace,
-						Labels:    ef.Labels,
-					},
-					Spec: &ef.Spec,
-				},
-				metav1.ApplyOptions{
-					TypeMeta: metav1.TypeMeta{
-						Kind:       kind,
-						APIVersion: apiVersion,
-					},
-					Force:        true,
-					FieldManager: "federation-controller",
-				},
-			)
-			if err != nil {
-				return fmt.Errorf("failed to apply envoy filter: %w", err)
-			}
-			log.Infof("Applied envoy filter: %v", newEF)
-		}
-	}
-
-	for k, oldEF := range oldEnvoyFiltersMap {
-		if _, ok := envoyFiltersMap[k]; !ok {
-			err := r.client.Istio().NetworkingV1alpha3().EnvoyFilters(oldEF.GetNamespace()).Delete(ctx, oldEF.GetName(), metav1.DeleteOptions{})
-			if client.IgnoreNotFound(err) != nil {
-				return fmt.Errorf("failed to delete old envoy filter: %w", err)
-			}
-			log.Infof("Deleted envoy filter: %v", oldEF)
-		}
-	}
-
-	return nil
+// NewEnvoyFilterReconciler creates a reconciler of envoy filter resources. EnvoyFilter is served only in v1alpha3.
+func NewEnvoyFilterReconciler(client kube.Client, cf *istio.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1alpha3.EnvoyFilter, *applyv1alpha3.EnvoyFilterApplyConfiguration]{
+		TypeUrl: xds.EnvoyFilterTypeUrl,
+		Name:    "envoy filter",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1alpha3.EnvoyFilter, *applyv1alpha3.EnvoyFilterApplyConfiguration, *v1alpha3.EnvoyFilterList] {
+				return client.Istio().NetworkingV1alpha3().EnvoyFilters(namespace)
+			},
+			func(list *v1alpha3.EnvoyFilterList) []*v1alpha3.EnvoyFilter {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1alpha3.EnvoyFilter, error) {
+			return cf.EnvoyFilters(), nil
+		},
+		UpToDate: func(live, desired *v1alpha3.EnvoyFilter) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: func(ef *v1alpha3.EnvoyFilter) *applyv1alpha3.EnvoyFilterApplyConfiguration {
+			ac := applyv1alpha3.EnvoyFilter(ef.Name, ef.Namespace).WithLabels(ef.Labels)
+			ac.Spec = &ef.Spec
+			return ac
+		},
+	}, opts)
 }
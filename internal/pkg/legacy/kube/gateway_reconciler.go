@@ -15,68 +15,92 @@
 package kube
 
 import (
-	"context"
 	"fmt"
+	"reflect"
 
-	applyconfigurationv1 "istio.io/client-go/pkg/applyconfiguration/meta/v1"
-	networkingv1alpha3 "istio.io/client-go/pkg/applyconfiguration/networking/v1alpha3"
+	networkingv1 "istio.io/client-go/pkg/apis/networking/v1"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	applyv1 "istio.io/client-go/pkg/applyconfiguration/networking/v1"
+	applyv1alpha3 "istio.io/client-go/pkg/applyconfiguration/networking/v1alpha3"
 	"istio.io/istio/pkg/kube"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
-var _ Reconciler = (*GatewayResourceReconciler)(nil)
-
-type GatewayResourceReconciler struct {
-	client kube.Client
-	cf     *istio.ConfigFactory
-}
-
-func NewGatewayResourceReconciler(client kube.Client, cf *istio.ConfigFactory) *GatewayResourceReconciler {
-	return &GatewayResourceReconciler{
-		client: client,
-		cf:     cf,
+// NewGatewayResourceReconciler creates a reconciler of the federation ingress gateway in the networking API version served by the cluster.
+func NewGatewayResourceReconciler(client kube.Client, cf *istio.ConfigFactory, apiVersions APIVersions, opts ReconcileOptions) Reconciler {
+	if apiVersions.Networking == NetworkingV1alpha3 {
+		return newGatewayResourceReconcilerV1alpha3(client, cf, opts)
 	}
+	return NewResourceReconciler(ResourceReconcilerConfig[*networkingv1.Gateway, *applyv1.GatewayApplyConfiguration]{
+		TypeUrl: xds.GatewayTypeUrl,
+		Name:    "ingress gateway",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*networkingv1.Gateway, *applyv1.GatewayApplyConfiguration, *networkingv1.GatewayList] {
+				return client.Istio().NetworkingV1().Gateways(namespace)
+			},
+			func(list *networkingv1.GatewayList) []*networkingv1.Gateway {
+				return list.Items
+			},
+		),
+		Desired: ingressGateways(cf),
+		UpToDate: func(live, desired *networkingv1.Gateway) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && managedInVersion(live, opts.fieldManager(), NetworkingV1)
+		},
+		ApplyConfiguration: func(g *networkingv1.Gateway) *applyv1.GatewayApplyConfiguration {
+			ac := applyv1.Gateway(g.Name, g.Namespace).WithLabels(g.Labels)
+			ac.Spec = &g.Spec
+			return ac
+		},
+	}, opts)
 }
 
-func (r *GatewayResourceReconciler) GetTypeUrl() string {
-	return xds.GatewayTypeUrl
-}
-
-func (r *GatewayResourceReconciler) Reconcile(ctx context.Context) error {
-	gw, err := r.cf.IngressGateway()
-	if err != nil {
-		return fmt.Errorf("error generating ingress gateway: %w", err)
-	}
-
-	kind := "Gateway"
-	apiVersion := "networking.istio.io/v1alpha3"
-	newGW, err := r.client.Istio().NetworkingV1alpha3().Gateways(gw.GetNamespace()).Apply(ctx, &networkingv1alpha3.GatewayApplyConfiguration{
-		TypeMetaApplyConfiguration: applyconfigurationv1.TypeMetaApplyConfiguration{
-			Kind:       &kind,
-			APIVersion: &apiVersion,
+// newGatewayResourceReconcilerV1alpha3 applies resources in v1alpha3 for meshes that do not serve networking.istio.io/v1.
+func newGatewayResourceReconcilerV1alpha3(client kube.Client, cf *istio.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1alpha3.Gateway, *applyv1alpha3.GatewayApplyConfiguration]{
+		TypeUrl: xds.GatewayTypeUrl,
+		Name:    "ingress gateway",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1alpha3.Gateway, *applyv1alpha3.GatewayApplyConfiguration, *v1alpha3.GatewayList] {
+				return client.Istio().NetworkingV1alpha3().Gateways(namespace)
+			},
+			func(list *v1alpha3.GatewayList) []*v1alpha3.Gateway {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1alpha3.Gateway, error) {
+			gateways, err := ingressGateways(cf)()
+			if err != nil {
+				return nil, err
+			}
+			converted := make([]*v1alpha3.Gateway, 0, len(gateways))
+			for _, g := range gateways {
+				c := &v1alpha3.Gateway{ObjectMeta: g.ObjectMeta}
+				if err := convertSpec(&g.Spec, &c.Spec); err != nil {
+					return nil, fmt.Errorf("failed to convert ingress gateway %s/%s to v1alpha3: %w", g.Namespace, g.Name, err)
+				}
+				converted = append(converted, c)
+			}
+			return converted, nil
 		},
-		ObjectMetaApplyConfiguration: &applyconfigurationv1.ObjectMetaApplyConfiguration{
-			Name:      &gw.Name,
-			Namespace: &gw.Namespace,
-			Labels:    gw.Labels,
+		UpToDate: func(live, desired *v1alpha3.Gateway) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
 		},
-		Spec:   &gw.Spec,
-		Status: nil,
-	}, metav1.ApplyOptions{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       kind,
-			APIVersion: apiVersion,
+		ApplyConfiguration: func(g *v1alpha3.Gateway) *applyv1alpha3.GatewayApplyConfiguration {
+			ac := applyv1alpha3.Gateway(g.Name, g.Namespace).WithLabels(g.Labels)
+			ac.Spec = &g.Spec
+			return ac
 		},
-		Force:        true,
-		FieldManager: "federation-controller",
-	})
-	if err != nil {
-		return fmt.Errorf("error applying ingress gateway: %w", err)
-	}
-	log.Infof("Applied ingress gateway: %v", newGW)
+	}, opts)
+}
 
-	return nil
+func ingressGateways(cf *istio.ConfigFactory) func() ([]*networkingv1.Gateway, error) {
+	return func() ([]*networkingv1.Gateway, error) {
+		gw, err := cf.IngressGateway()
+		if err != nil {
+			return nil, err
+		}
+		return []*networkingv1.Gateway{gw}, nil
+	}
 }
@@ -0,0 +1,42 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+// GeneratedResources returns resources created by reconcilers, keyed by type URLs of the owning reconcilers.
+// Istio resources are returned in the versions used to apply them.
+func GeneratedResources(apiVersions APIVersions) map[string]schema.GroupVersionResource {
+	networking, _ := schema.ParseGroupVersion(apiVersions.Networking)
+	security, _ := schema.ParseGroupVersion(apiVersions.Security)
+	return map[string]schema.GroupVersionResource{
+		xds.ServiceEntryTypeUrl:        networking.WithResource("serviceentries"),
+		xds.WorkloadEntryTypeUrl:       networking.WithResource("workloadentries"),
+		xds.DestinationRuleTypeUrl:     networking.WithResource("destinationrules"),
+		xds.GatewayTypeUrl:             networking.WithResource("gateways"),
+		xds.EnvoyFilterTypeUrl:         {Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"},
+		xds.PeerAuthenticationTypeUrl:  security.WithResource("peerauthentications"),
+		xds.AuthorizationPolicyTypeUrl: security.WithResource("authorizationpolicies"),
+		xds.RouteTypeUrl:               {Group: "route.openshift.io", Version: "v1", Resource: "routes"},
+		xds.KubernetesGatewayTypeUrl:   {Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"},
+		xds.TLSRouteTypeUrl:            {Group: "gateway.networking.k8s.io", Version: "v1alpha2", Resource: "tlsroutes"},
+		xds.ServiceImportTypeUrl:       {Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "serviceimports"},
+		xds.ClusterTrustBundleTypeUrl:  {Group: "certificates.k8s.io", Version: "v1alpha1", Resource: "clustertrustbundles"},
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"reflect"
+
+	"istio.io/istio/pkg/kube"
+	gwv1apply "sigs.k8s.io/gateway-api/apis/applyconfiguration/apis/v1"
+	gwv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/gatewayapi"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+// NewKubernetesGatewayReconciler creates a reconciler of the Gateway API Gateway used as the federation ingress
+// when the local ingress type is gateway-api, or as the east-west gateway in ambient meshes.
+func NewKubernetesGatewayReconciler(client kube.Client, cf *gatewayapi.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*gwv1.Gateway, *gwv1apply.GatewayApplyConfiguration]{
+		TypeUrl: xds.KubernetesGatewayTypeUrl,
+		Name:    "kubernetes gateway",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*gwv1.Gateway, *gwv1apply.GatewayApplyConfiguration, *gwv1.GatewayList] {
+				return client.GatewayAPI().GatewayV1().Gateways(namespace)
+			},
+			func(list *gwv1.GatewayList) []*gwv1.Gateway {
+				gateways := make([]*gwv1.Gateway, 0, len(list.Items))
+				for i := range list.Items {
+					gateways = append(gateways, &list.Items[i])
+				}
+				return gateways
+			},
+		),
+		Desired: func() ([]*gwv1.Gateway, error) {
+			return []*gwv1.Gateway{cf.Gateway()}, nil
+		},
+		UpToDate: func(live, desired *gwv1.Gateway) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: toGatewayApplyConfiguration,
+	}, opts)
+}
+
+func toGatewayApplyConfiguration(gw *gwv1.Gateway) *gwv1apply.GatewayApplyConfiguration {
+	var listeners []*gwv1apply.ListenerApplyConfiguration
+	for _, l := range gw.Spec.Listeners {
+		listener := gwv1apply.Listener().
+			WithName(l.Name).
+			WithPort(l.Port).
+			WithProtocol(l.Protocol)
+		if l.TLS != nil && l.TLS.Mode != nil {
+			listener.WithTLS(gwv1apply.GatewayTLSConfig().WithMode(*l.TLS.Mode).WithOptions(l.TLS.Options))
+		}
+		if l.AllowedRoutes != nil {
+			allowedRoutes := gwv1apply.AllowedRoutes()
+			if l.AllowedRoutes.Namespaces != nil && l.AllowedRoutes.Namespaces.From != nil {
+				allowedRoutes.WithNamespaces(gwv1apply.RouteNamespaces().WithFrom(*l.AllowedRoutes.Namespaces.From))
+			}
+			for _, k := range l.AllowedRoutes.Kinds {
+				kind := gwv1apply.RouteGroupKind().WithKind(k.Kind)
+				if k.Group != nil {
+					kind.WithGroup(*k.Group)
+				}
+				allowedRoutes.WithKinds(kind)
+			}
+			listener.WithAllowedRoutes(allowedRoutes)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return gwv1apply.Gateway(gw.Name, gw.Namespace).
+		WithLabels(gw.Labels).
+		WithSpec(gwv1apply.GatewaySpec().
+			WithGatewayClassName(gw.Spec.GatewayClassName).
+			WithListeners(listeners...),
+		)
+}
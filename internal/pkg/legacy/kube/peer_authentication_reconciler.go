@@ -15,82 +15,107 @@
 package kube
 
 import (
-	"context"
 	"fmt"
+	"reflect"
 
-	securityv1beta1 "istio.io/api/security/v1beta1"
+	istiosecurityv1 "istio.io/api/security/v1"
 	typev1beta1 "istio.io/api/type/v1beta1"
+	securityv1 "istio.io/client-go/pkg/apis/security/v1"
 	"istio.io/client-go/pkg/apis/security/v1beta1"
-	applyconfigurationv1 "istio.io/client-go/pkg/applyconfiguration/meta/v1"
-	applyv1beta "istio.io/client-go/pkg/applyconfiguration/security/v1beta1"
+	applyv1 "istio.io/client-go/pkg/applyconfiguration/security/v1"
+	applyv1beta1 "istio.io/client-go/pkg/applyconfiguration/security/v1beta1"
 	"istio.io/istio/pkg/kube"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
-var _ Reconciler = (*PeerAuthResourceReconciler)(nil)
-
-type PeerAuthResourceReconciler struct {
-	client    kube.Client
-	namespace string
-}
-
-func NewPeerAuthResourceReconciler(client kube.Client, namespace string) *PeerAuthResourceReconciler {
-	return &PeerAuthResourceReconciler{
-		client:    client,
-		namespace: namespace,
+// NewPeerAuthResourceReconciler creates a reconciler of the peer authentication enforcing mTLS for the discovery service.
+func NewPeerAuthResourceReconciler(client kube.Client, namespace string, apiVersions APIVersions, opts ReconcileOptions) Reconciler {
+	if apiVersions.Security == SecurityV1beta1 {
+		return newPeerAuthResourceReconcilerV1beta1(client, namespace, opts)
 	}
-}
-
-func (r *PeerAuthResourceReconciler) GetTypeUrl() string {
-	return xds.PeerAuthenticationTypeUrl
-}
-
-func (r *PeerAuthResourceReconciler) Reconcile(ctx context.Context) error {
-	pa := &v1beta1.PeerAuthentication{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "fds-strict-mtls",
-			Namespace: r.namespace,
-			Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-		},
-		Spec: securityv1beta1.PeerAuthentication{
-			Selector: &typev1beta1.WorkloadSelector{
-				MatchLabels: map[string]string{
-					"app.kubernetes.io/name": "federation-controller",
-				},
+	return NewResourceReconciler(ResourceReconcilerConfig[*securityv1.PeerAuthentication, *applyv1.PeerAuthenticationApplyConfiguration]{
+		TypeUrl: xds.PeerAuthenticationTypeUrl,
+		Name:    "peer authentication",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*securityv1.PeerAuthentication, *applyv1.PeerAuthenticationApplyConfiguration, *securityv1.PeerAuthenticationList] {
+				return client.Istio().SecurityV1().PeerAuthentications(namespace)
 			},
-			Mtls: &securityv1beta1.PeerAuthentication_MutualTLS{
-				Mode: securityv1beta1.PeerAuthentication_MutualTLS_STRICT,
+			func(list *securityv1.PeerAuthenticationList) []*securityv1.PeerAuthentication {
+				return list.Items
 			},
+		),
+		Desired: peerAuthentications(namespace, opts.Peer),
+		UpToDate: func(live, desired *securityv1.PeerAuthentication) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && managedInVersion(live, opts.fieldManager(), SecurityV1)
 		},
-	}
+		ApplyConfiguration: func(pa *securityv1.PeerAuthentication) *applyv1.PeerAuthenticationApplyConfiguration {
+			ac := applyv1.PeerAuthentication(pa.Name, pa.Namespace).WithLabels(pa.Labels)
+			ac.Spec = &pa.Spec
+			return ac
+		},
+	}, opts)
+}
 
-	kind := "PeerAuthentication"
-	apiVersion := "security.istio.io/v1beta1"
-	newPA, err := r.client.Istio().SecurityV1beta1().PeerAuthentications(pa.GetNamespace()).Apply(ctx, &applyv1beta.PeerAuthenticationApplyConfiguration{
-		TypeMetaApplyConfiguration: applyconfigurationv1.TypeMetaApplyConfiguration{
-			Kind:       &kind,
-			APIVersion: &apiVersion,
+// newPeerAuthResourceReconcilerV1beta1 applies resources in v1beta1 for meshes that do not serve security.istio.io/v1.
+func newPeerAuthResourceReconcilerV1beta1(client kube.Client, namespace string, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1beta1.PeerAuthentication, *applyv1beta1.PeerAuthenticationApplyConfiguration]{
+		TypeUrl: xds.PeerAuthenticationTypeUrl,
+		Name:    "peer authentication",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1beta1.PeerAuthentication, *applyv1beta1.PeerAuthenticationApplyConfiguration, *v1beta1.PeerAuthenticationList] {
+				return client.Istio().SecurityV1beta1().PeerAuthentications(namespace)
+			},
+			func(list *v1beta1.PeerAuthenticationList) []*v1beta1.PeerAuthentication {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1beta1.PeerAuthentication, error) {
+			peerAuthentications, err := peerAuthentications(namespace, opts.Peer)()
+			if err != nil {
+				return nil, err
+			}
+			converted := make([]*v1beta1.PeerAuthentication, 0, len(peerAuthentications))
+			for _, pa := range peerAuthentications {
+				c := &v1beta1.PeerAuthentication{ObjectMeta: pa.ObjectMeta}
+				if err := convertSpec(&pa.Spec, &c.Spec); err != nil {
+					return nil, fmt.Errorf("failed to convert peer authentication %s/%s to v1beta1: %w", pa.Namespace, pa.Name, err)
+				}
+				converted = append(converted, c)
+			}
+			return converted, nil
 		},
-		ObjectMetaApplyConfiguration: &applyconfigurationv1.ObjectMetaApplyConfiguration{
-			Name:      &pa.Name,
-			Namespace: &pa.Namespace,
-			Labels:    pa.Labels,
+		UpToDate: func(live, desired *v1beta1.PeerAuthentication) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
 		},
-		Spec: &pa.Spec,
-	}, metav1.ApplyOptions{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       kind,
-			APIVersion: apiVersion,
+		ApplyConfiguration: func(pa *v1beta1.PeerAuthentication) *applyv1beta1.PeerAuthenticationApplyConfiguration {
+			ac := applyv1beta1.PeerAuthentication(pa.Name, pa.Namespace).WithLabels(pa.Labels)
+			ac.Spec = &pa.Spec
+			return ac
 		},
-		Force:        true,
-		FieldManager: "federation-controller",
-	})
-	if err != nil {
-		return fmt.Errorf("error applying peer authentication: %w", err)
-	}
-	log.Infof("Applied peer authentication: %v", newPA)
+	}, opts)
+}
 
-	return nil
+func peerAuthentications(namespace, peer string) func() ([]*securityv1.PeerAuthentication, error) {
+	return func() ([]*securityv1.PeerAuthentication, error) {
+		return []*securityv1.PeerAuthentication{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fds-strict-mtls",
+				Namespace: namespace,
+				Labels:    config.PeerLabels(peer),
+			},
+			Spec: istiosecurityv1.PeerAuthentication{
+				Selector: &typev1beta1.WorkloadSelector{
+					MatchLabels: map[string]string{
+						"app.kubernetes.io/name": "federation-controller",
+					},
+				},
+				Mtls: &istiosecurityv1.PeerAuthentication_MutualTLS{
+					Mode: istiosecurityv1.PeerAuthentication_MutualTLS_STRICT,
+				},
+			},
+		}}, nil
+	}
 }
@@ -17,20 +17,41 @@ package kube
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	istiolog "istio.io/istio/pkg/log"
+	corev1 "k8s.io/api/core/v1"
 
+	"github.com/openshift-service-mesh/federation/internal/pkg/events"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
 var log = istiolog.RegisterScope("kube", "Kubernetes reconciler")
 
+// ReconcilerManager runs reconcilers for received push requests. Push requests are consumed by all replicas,
+// but only the leader reconciles resources, so that replicas do not race to apply and delete the same objects.
+// The leader also reconciles all resources every resync period, to restore generated resources whose changes were missed.
 type ReconcilerManager struct {
 	pushRequests <-chan xds.PushRequest
 	reconcilers  map[string]Reconciler
+	resyncPeriod time.Duration
+	recorder     *events.Recorder
+
+	// mu serializes reconciliations
+	mu      sync.Mutex
+	leading atomic.Bool
 }
 
-func NewReconcilerManager(pushRequests <-chan xds.PushRequest, reconcilers ...Reconciler) *ReconcilerManager {
+// NewReconcilerManager creates a manager for the given reconcilers. Periodic resync is disabled if resyncPeriod is 0.
+// Failed reconciliations are recorded as events by the given recorder, which may be nil.
+func NewReconcilerManager(
+	pushRequests <-chan xds.PushRequest,
+	resyncPeriod time.Duration,
+	recorder *events.Recorder,
+	reconcilers ...Reconciler,
+) *ReconcilerManager {
 	reconcilerMap := make(map[string]Reconciler, len(reconcilers))
 	for _, r := range reconcilers {
 		reconcilerMap[r.GetTypeUrl()] = r
@@ -39,10 +60,27 @@ func NewReconcilerManager(pushRequests <-chan xds.PushRequest, reconcilers ...Re
 	return &ReconcilerManager{
 		pushRequests: pushRequests,
 		reconcilers:  reconcilerMap,
+		resyncPeriod: resyncPeriod,
+		recorder:     recorder,
 	}
 }
 
-func (rm *ReconcilerManager) ReconcileAll(ctx context.Context) error {
+// Lead marks this instance as the leader and reconciles all resources, which may have been changed
+// since the previous leader stopped. Push requests received before becoming the leader are dropped.
+func (rm *ReconcilerManager) Lead(ctx context.Context) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.leading.Store(true)
+	return rm.reconcileAll(ctx)
+}
+
+// IsLeading returns true if this instance reconciles resources.
+func (rm *ReconcilerManager) IsLeading() bool {
+	return rm.leading.Load()
+}
+
+func (rm *ReconcilerManager) reconcileAll(ctx context.Context) error {
 	reconcileErrs := make([]error, 0, len(rm.reconcilers))
 
 	for _, r := range rm.reconcilers {
@@ -53,6 +91,12 @@ func (rm *ReconcilerManager) ReconcileAll(ctx context.Context) error {
 }
 
 func (rm *ReconcilerManager) Start(ctx context.Context) {
+	var resync <-chan time.Time
+	if rm.resyncPeriod > 0 {
+		ticker := time.NewTicker(rm.resyncPeriod)
+		defer ticker.Stop()
+		resync = ticker.C
+	}
 
 loop:
 	for {
@@ -60,17 +104,45 @@ loop:
 		case <-ctx.Done():
 			break loop
 
+		case <-resync:
+			rm.resync(ctx)
+
 		case pushRequest := <-rm.pushRequests:
 			log.Infof("Received push request: %v", pushRequest)
+			rm.reconcile(ctx, pushRequest)
+		}
+	}
+}
+
+func (rm *ReconcilerManager) reconcile(ctx context.Context, pushRequest xds.PushRequest) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
 
-			if r, ok := rm.reconcilers[pushRequest.TypeUrl]; !ok {
-				log.Infof("No reconciler present for type: %v", pushRequest.TypeUrl)
-			} else {
-				err := r.Reconcile(ctx)
-				if err != nil {
-					log.Errorf("Reconcile failed: %v", err)
-				}
-			}
+	if !rm.leading.Load() {
+		log.Debugf("Skipping push request for type %s, because this instance is not the leader", pushRequest.TypeUrl)
+		return
+	}
+	if r, ok := rm.reconcilers[pushRequest.TypeUrl]; !ok {
+		log.Infof("No reconciler present for type: %v", pushRequest.TypeUrl)
+	} else {
+		err := r.Reconcile(ctx)
+		if err != nil {
+			log.Errorf("Reconcile failed: %v", err)
+			rm.recorder.Federation(corev1.EventTypeWarning, events.ReasonApplyFailed, "Failed to reconcile %s: %v", pushRequest.TypeUrl, err)
 		}
 	}
 }
+
+func (rm *ReconcilerManager) resync(ctx context.Context) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if !rm.leading.Load() {
+		return
+	}
+	log.Debugf("Resyncing all resources")
+	if err := rm.reconcileAll(ctx); err != nil {
+		log.Errorf("Resync failed: %v", err)
+		rm.recorder.Federation(corev1.EventTypeWarning, events.ReasonApplyFailed, "Failed to resync generated resources: %v", err)
+	}
+}
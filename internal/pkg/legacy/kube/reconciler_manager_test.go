@@ -0,0 +1,97 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+type countingReconciler struct {
+	reconciled atomic.Int32
+}
+
+func (r *countingReconciler) GetTypeUrl() string {
+	return xds.ServiceEntryTypeUrl
+}
+
+func (r *countingReconciler) Reconcile(_ context.Context) error {
+	r.reconciled.Add(1)
+	return nil
+}
+
+func TestReconcilerManagerReconcilesOnlyWhenLeading(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pushRequests := make(chan xds.PushRequest)
+	reconciler := &countingReconciler{}
+	rm := NewReconcilerManager(pushRequests, 0, nil, reconciler)
+	go rm.Start(ctx)
+
+	// Push requests are consumed by followers, so that event handlers are not blocked, but they are not reconciled
+	pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+	pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+	// Requests are processed sequentially, so the previous requests are completed once the next one is received
+	pushRequests <- xds.PushRequest{TypeUrl: xds.DestinationRuleTypeUrl}
+	if reconciled := reconciler.reconciled.Load(); reconciled != 0 {
+		t.Fatalf("expected no reconciliation before leading, got %d", reconciled)
+	}
+
+	if err := rm.Lead(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reconciled := reconciler.reconciled.Load(); reconciled != 1 {
+		t.Fatalf("expected all resources to be reconciled when started leading, got %d reconciliations", reconciled)
+	}
+
+	pushRequests <- xds.PushRequest{TypeUrl: xds.ServiceEntryTypeUrl}
+	deadline := time.Now().Add(time.Second)
+	for reconciler.reconciled.Load() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected push request to be reconciled by the leader, got %d reconciliations", reconciler.reconciled.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReconcilerManagerResyncsOnlyWhenLeading(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reconciler := &countingReconciler{}
+	rm := NewReconcilerManager(make(chan xds.PushRequest), 10*time.Millisecond, nil, reconciler)
+	go rm.Start(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	if reconciled := reconciler.reconciled.Load(); reconciled != 0 {
+		t.Fatalf("expected no resync before leading, got %d reconciliations", reconciled)
+	}
+
+	if err := rm.Lead(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for reconciler.reconciled.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected resources to be periodically resynced by the leader, got %d reconciliations", reconciler.reconciled.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+// ReconcileOptions configure all reconcilers built on ResourceReconciler.
+type ReconcileOptions struct {
+	// DryRun makes reconcilers send apply and delete requests in the dry-run mode, so that nothing is persisted.
+	// Diffs of resources that would be applied and names of resources that would be deleted are logged instead.
+	DryRun bool
+	// MaxConcurrency limits the number of parallel apply and delete requests of a reconciler.
+	// Requests are sent sequentially if it is not set.
+	MaxConcurrency int
+	// Peer is the name of the local peer owning generated resources. Only resources labeled with this name,
+	// or with the value set by previous versions of the controller, are reconciled, so that multiple federations
+	// can run in the same cluster. The name is also appended to the field manager applying the resources.
+	Peer string
+	// LegacyNamespaces are namespaces, in which resources labeled by previous versions of the controller are adopted
+	// and deleted if they are not desired. Such resources in other namespaces, and cluster-scoped ones, may still be
+	// owned by other federations that were not upgraded yet, so they are taken over only by applying desired resources.
+	LegacyNamespaces []string
+	// DeletedResources records resources deleted by reconcilers, so that the drift detector does not enqueue
+	// reconcilers for their own deletions. It is optional.
+	DeletedResources *DeletedResources
+}
+
+// fieldManager returns the field manager applying resources of the local peer.
+func (o ReconcileOptions) fieldManager() string {
+	if o.Peer == "" {
+		return fieldManager
+	}
+	return fmt.Sprintf("%s-%s", fieldManager, o.Peer)
+}
+
+// appliedByLegacyManager returns true if the live resource was applied by previous versions of the controller,
+// which used a field manager without the peer name.
+func (o ReconcileOptions) appliedByLegacyManager(obj metav1.Object) bool {
+	if o.fieldManager() == fieldManager {
+		return false
+	}
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Manager == fieldManager && mf.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// owns returns true if the live resource is reconciled by this federation.
+func (o ReconcileOptions) owns(obj metav1.Object) bool {
+	if o.Peer == "" || o.Peer == config.LegacyPeerLabelValue || obj.GetLabels()[config.PeerLabel] != config.LegacyPeerLabelValue {
+		return true
+	}
+	return obj.GetNamespace() != "" && slices.Contains(o.LegacyNamespaces, obj.GetNamespace())
+}
+
+// ResourceClient lists, applies, patches and deletes resources of a single kind.
+type ResourceClient[T metav1.Object, A any] interface {
+	List(ctx context.Context, opts metav1.ListOptions) ([]T, error)
+	Apply(ctx context.Context, namespace string, obj A, opts metav1.ApplyOptions) (T, error)
+	Patch(ctx context.Context, namespace, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions) error
+	Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error
+}
+
+// ResourceReconcilerConfig describes how to reconcile generated resources of a single kind.
+type ResourceReconcilerConfig[T metav1.Object, A any] struct {
+	// TypeUrl identifies push requests handled by the reconciler.
+	TypeUrl string
+	// Name of the resource kind used in logs and errors, e.g. "service entry".
+	Name   string
+	Client ResourceClient[T, A]
+	// Desired returns all resources that should exist.
+	Desired func() ([]T, error)
+	// UpToDate returns true if the live resource does not need to be applied again.
+	UpToDate func(live, desired T) bool
+	// ApplyConfiguration builds the configuration applied for the desired resource.
+	ApplyConfiguration func(desired T) A
+	// Skipped returns true if the apply error means that the desired resource can't be created in this cluster,
+	// e.g. because its namespace does not exist. Such resources are skipped with a warning. It is optional.
+	Skipped func(err error) bool
+}
+
+var _ Reconciler = (*ResourceReconciler[metav1.Object, any])(nil)
+
+// ResourceReconciler applies desired resources using server-side apply if they do not exist or are not up-to-date,
+// and deletes generated resources, which are not desired anymore. Failures of individual resources do not stop
+// the reconciliation of other resources, and are returned together.
+type ResourceReconciler[T metav1.Object, A any] struct {
+	cfg  ResourceReconcilerConfig[T, A]
+	opts ReconcileOptions
+}
+
+func NewResourceReconciler[T metav1.Object, A any](cfg ResourceReconcilerConfig[T, A], opts ReconcileOptions) *ResourceReconciler[T, A] {
+	return &ResourceReconciler[T, A]{
+		cfg:  cfg,
+		opts: opts,
+	}
+}
+
+func (r *ResourceReconciler[T, A]) GetTypeUrl() string {
+	return r.cfg.TypeUrl
+}
+
+func (r *ResourceReconciler[T, A]) Reconcile(ctx context.Context) error {
+	desired, err := r.cfg.Desired()
+	if err != nil {
+		return fmt.Errorf("failed to generate %s resources: %w", r.cfg.Name, err)
+	}
+	desiredMap := make(map[types.NamespacedName]T, len(desired))
+	for _, obj := range desired {
+		desiredMap[keyOf(obj)] = obj
+	}
+
+	live, err := r.cfg.Client.List(ctx, metav1.ListOptions{
+		LabelSelector: config.PeerLabelSelector(r.opts.Peer),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list %s resources: %w", r.cfg.Name, err)
+	}
+	liveMap := make(map[types.NamespacedName]T, len(live))
+	for _, obj := range live {
+		if r.opts.owns(obj) {
+			liveMap[keyOf(obj)] = obj
+		}
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	run := func(g *errgroup.Group, f func() error) {
+		g.Go(func() error {
+			if err := f(); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(max(r.opts.MaxConcurrency, 1))
+	for k, obj := range desiredMap {
+		liveObj, exists := liveMap[k]
+		legacy := exists && r.opts.appliedByLegacyManager(liveObj)
+		// Resources labeled or applied by previous versions of the controller are applied again to take over their ownership
+		if exists && !legacy && r.cfg.UpToDate(liveObj, obj) && liveObj.GetLabels()[config.PeerLabel] == obj.GetLabels()[config.PeerLabel] {
+			continue
+		}
+		var live any
+		if exists {
+			live = liveObj
+		}
+		run(g, func() error {
+			if err := r.apply(ctx, live, obj); err != nil || !legacy {
+				return err
+			}
+			return r.releaseLegacyFields(ctx, obj)
+		})
+	}
+	_ = g.Wait()
+
+	// Stale resources are deleted only after desired resources are applied, so that renamed resources,
+	// e.g. authorization policies, are never missing.
+	g = &errgroup.Group{}
+	g.SetLimit(max(r.opts.MaxConcurrency, 1))
+	for k, liveObj := range liveMap {
+		if _, ok := desiredMap[k]; ok {
+			continue
+		}
+		run(g, func() error {
+			return r.delete(ctx, liveObj)
+		})
+	}
+	_ = g.Wait()
+
+	return errors.Join(errs...)
+}
+
+// apply applies the desired object. In the dry-run mode, nothing is persisted and the diff against the live object,
+// which is nil if it does not exist, is logged instead.
+func (r *ResourceReconciler[T, A]) apply(ctx context.Context, live any, obj T) error {
+	opts := metav1.ApplyOptions{
+		Force:        true,
+		FieldManager: r.opts.fieldManager(),
+	}
+	if r.opts.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := r.cfg.Client.Apply(ctx, obj.GetNamespace(), r.cfg.ApplyConfiguration(obj), opts)
+	if err != nil && r.cfg.Skipped != nil && r.cfg.Skipped(err) {
+		log.Warnf("Skipping %s %s: %v", r.cfg.Name, keyOf(obj), err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %s %s: %w", r.cfg.Name, keyOf(obj), err)
+	}
+	if !r.opts.DryRun {
+		log.Infof("Applied %s: %v", r.cfg.Name, applied)
+		return nil
+	}
+	diff, err := unifiedDiff(live, applied)
+	if err != nil {
+		return fmt.Errorf("failed to compute diff of %s %s: %w", r.cfg.Name, keyOf(obj), err)
+	}
+	if diff == "" {
+		log.Infof("Dry-run: %s %s would not change", r.cfg.Name, keyOf(obj))
+		return nil
+	}
+	log.Infof("Dry-run: %s %s would be applied:\n%s", r.cfg.Name, keyOf(obj), diff)
+	return nil
+}
+
+// releaseLegacyFields applies an empty configuration with the field manager used by previous versions of the controller,
+// which releases all fields it owned. Desired fields are kept, as they are already owned by the current field manager,
+// and fields that are not desired anymore are removed.
+func (r *ResourceReconciler[T, A]) releaseLegacyFields(ctx context.Context, obj T) error {
+	data, err := emptyApplyConfiguration(r.cfg.ApplyConfiguration(obj))
+	if err != nil {
+		return fmt.Errorf("failed to build empty configuration of %s %s: %w", r.cfg.Name, keyOf(obj), err)
+	}
+	opts := metav1.PatchOptions{
+		Force:        ptr.To(true),
+		FieldManager: fieldManager,
+	}
+	if r.opts.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	if err := r.cfg.Client.Patch(ctx, obj.GetNamespace(), obj.GetName(), types.ApplyPatchType, data, opts); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to release fields of %s %s applied by %s: %w", r.cfg.Name, keyOf(obj), fieldManager, err)
+	}
+	log.Infof("Released fields of %s %s applied by %s", r.cfg.Name, keyOf(obj), fieldManager)
+	return nil
+}
+
+// emptyApplyConfiguration returns an apply patch with only the type and the name of the given apply configuration.
+func emptyApplyConfiguration(ac any) ([]byte, error) {
+	data, err := json.Marshal(ac)
+	if err != nil {
+		return nil, err
+	}
+	var empty struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace,omitempty"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &empty); err != nil {
+		return nil, err
+	}
+	return json.Marshal(empty)
+}
+
+func (r *ResourceReconciler[T, A]) delete(ctx context.Context, obj T) error {
+	opts := metav1.DeleteOptions{}
+	if r.opts.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	if !r.opts.DryRun {
+		r.opts.DeletedResources.record(r.cfg.TypeUrl, obj)
+	}
+	err := r.cfg.Client.Delete(ctx, obj.GetNamespace(), obj.GetName(), opts)
+	if err != nil {
+		r.opts.DeletedResources.forget(r.cfg.TypeUrl, obj)
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete old %s %s: %w", r.cfg.Name, keyOf(obj), err)
+	}
+	if r.opts.DryRun {
+		log.Infof("Dry-run: %s %s would be deleted", r.cfg.Name, keyOf(obj))
+	} else {
+		log.Infof("Deleted %s: %v", r.cfg.Name, obj)
+	}
+	return nil
+}
+
+func keyOf(obj metav1.Object) types.NamespacedName {
+	return types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+}
+
+// typedClient is implemented by generated clients of namespaced resources.
+type typedClient[T, A, L any] interface {
+	List(ctx context.Context, opts metav1.ListOptions) (L, error)
+	Apply(ctx context.Context, obj A, opts metav1.ApplyOptions) (T, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// namespacedClient adapts generated clients of namespaced resources to ResourceClient.
+type namespacedClient[T metav1.Object, A, L any] struct {
+	forNamespace func(namespace string) typedClient[T, A, L]
+	items        func(list L) []T
+}
+
+func newNamespacedClient[T metav1.Object, A, L any](
+	forNamespace func(namespace string) typedClient[T, A, L],
+	items func(list L) []T,
+) ResourceClient[T, A] {
+	return &namespacedClient[T, A, L]{
+		forNamespace: forNamespace,
+		items:        items,
+	}
+}
+
+func (c *namespacedClient[T, A, L]) List(ctx context.Context, opts metav1.ListOptions) ([]T, error) {
+	list, err := c.forNamespace(metav1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.items(list), nil
+}
+
+func (c *namespacedClient[T, A, L]) Apply(ctx context.Context, namespace string, obj A, opts metav1.ApplyOptions) (T, error) {
+	return c.forNamespace(namespace).Apply(ctx, obj, opts)
+}
+
+func (c *namespacedClient[T, A, L]) Patch(
+	ctx context.Context,
+	namespace, name string,
+	pt types.PatchType,
+	data []byte,
+	opts metav1.PatchOptions,
+) error {
+	_, err := c.forNamespace(namespace).Patch(ctx, name, pt, data, opts)
+	return err
+}
+
+func (c *namespacedClient[T, A, L]) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	return c.forNamespace(namespace).Delete(ctx, name, opts)
+}
@@ -0,0 +1,265 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+)
+
+// fakeResourceClient stores objects in memory, and uses the "spec" annotation as the object spec.
+type fakeResourceClient struct {
+	mu          sync.Mutex
+	objects     map[string]*metav1.ObjectMeta
+	failApply   map[string]bool
+	applied     []string
+	released    []string
+	deleted     []string
+	requests    []string
+	dryRunCalls int
+}
+
+func (c *fakeResourceClient) List(_ context.Context, opts metav1.ListOptions) ([]*metav1.ObjectMeta, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	var objects []*metav1.ObjectMeta
+	for _, obj := range c.objects {
+		if selector.Matches(labels.Set(obj.Labels)) {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+func (c *fakeResourceClient) Apply(_ context.Context, namespace string, obj *metav1.ObjectMeta, opts metav1.ApplyOptions) (*metav1.ObjectMeta, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s", namespace, obj.Name)
+	if c.failApply[key] {
+		return nil, fmt.Errorf("apply rejected")
+	}
+	c.applied = append(c.applied, key)
+	c.requests = append(c.requests, "apply")
+	if len(opts.DryRun) > 0 {
+		c.dryRunCalls++
+		return obj, nil
+	}
+	c.objects[key] = obj
+	return obj, nil
+}
+
+func (c *fakeResourceClient) Patch(_ context.Context, namespace, name string, _ types.PatchType, _ []byte, opts metav1.PatchOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	c.released = append(c.released, key)
+	c.requests = append(c.requests, "patch")
+	if len(opts.DryRun) > 0 {
+		c.dryRunCalls++
+	}
+	return nil
+}
+
+func (c *fakeResourceClient) Delete(_ context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	c.deleted = append(c.deleted, key)
+	c.requests = append(c.requests, "delete")
+	if len(opts.DryRun) > 0 {
+		c.dryRunCalls++
+		return nil
+	}
+	delete(c.objects, key)
+	return nil
+}
+
+func object(name, spec string) *metav1.ObjectMeta {
+	return peerObject(name, spec, "east")
+}
+
+func peerObject(name, spec, peer string) *metav1.ObjectMeta {
+	return &metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        name,
+		Labels:      config.PeerLabels(peer),
+		Annotations: map[string]string{"spec": spec},
+	}
+}
+
+func TestResourceReconciler(t *testing.T) {
+	testCases := []struct {
+		name            string
+		opts            ReconcileOptions
+		failApply       map[string]bool
+		skipFailed      bool
+		legacy          bool
+		legacyManager   bool
+		expectedApplied []string
+		expectedRelease []string
+		expectedDeleted []string
+		expectedObjects []string
+		expectErr       bool
+	}{{
+		name:            "applies missing and outdated resources and deletes stale resources",
+		expectedApplied: []string{"ns/a", "ns/d"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/d", "ns/w"},
+	}, {
+		name:            "sends requests concurrently",
+		opts:            ReconcileOptions{MaxConcurrency: 4},
+		expectedApplied: []string{"ns/a", "ns/d"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/d", "ns/w"},
+	}, {
+		name:            "reconciles other resources when applying a resource fails",
+		failApply:       map[string]bool{"ns/a": true},
+		expectedApplied: []string{"ns/d"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/d", "ns/w"},
+		expectErr:       true,
+	}, {
+		name:            "skips resources that can't be applied in this cluster",
+		failApply:       map[string]bool{"ns/a": true},
+		skipFailed:      true,
+		expectedApplied: []string{"ns/d"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/d", "ns/w"},
+	}, {
+		name:            "does not persist changes in dry-run mode",
+		opts:            ReconcileOptions{DryRun: true},
+		expectedApplied: []string{"ns/a", "ns/d"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/c", "ns/w"},
+	}, {
+		name:            "adopts resources labeled by previous versions",
+		opts:            ReconcileOptions{MaxConcurrency: 4, LegacyNamespaces: []string{"ns"}},
+		legacy:          true,
+		expectedApplied: []string{"ns/a", "ns/b", "ns/d"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/d", "ns/w"},
+	}, {
+		name:            "does not delete resources labeled by previous versions in other namespaces",
+		opts:            ReconcileOptions{LegacyNamespaces: []string{"istio-system"}},
+		legacy:          true,
+		expectedApplied: []string{"ns/a", "ns/b", "ns/d"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/c", "ns/d", "ns/w"},
+	}, {
+		name:            "releases fields applied by the field manager of previous versions",
+		legacyManager:   true,
+		expectedApplied: []string{"ns/a", "ns/b", "ns/d"},
+		expectedRelease: []string{"ns/a", "ns/b"},
+		expectedDeleted: []string{"ns/c"},
+		expectedObjects: []string{"ns/a", "ns/b", "ns/d", "ns/w"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeResourceClient{
+				objects: map[string]*metav1.ObjectMeta{
+					"ns/a": object("a", "old"),
+					"ns/b": object("b", "current"),
+					"ns/c": object("c", "stale"),
+					// owned by another federation running in the same cluster
+					"ns/w": peerObject("w", "other", "west"),
+				},
+				failApply: tc.failApply,
+			}
+			if tc.legacy {
+				for name, spec := range map[string]string{"a": "old", "b": "current", "c": "stale"} {
+					client.objects["ns/"+name] = peerObject(name, spec, config.LegacyPeerLabelValue)
+				}
+			}
+			if tc.legacyManager {
+				for _, name := range []string{"a", "b", "c"} {
+					client.objects["ns/"+name].ManagedFields = []metav1.ManagedFieldsEntry{{
+						Manager: fieldManager, Operation: metav1.ManagedFieldsOperationApply,
+					}}
+				}
+			}
+			tc.opts.Peer = "east"
+			tc.opts.DeletedResources = NewDeletedResources()
+			r := NewResourceReconciler(ResourceReconcilerConfig[*metav1.ObjectMeta, *metav1.ObjectMeta]{
+				Name:   "object",
+				Client: client,
+				Desired: func() ([]*metav1.ObjectMeta, error) {
+					return []*metav1.ObjectMeta{object("a", "new"), object("b", "current"), object("d", "new")}, nil
+				},
+				UpToDate: func(live, desired *metav1.ObjectMeta) bool {
+					return live.Annotations["spec"] == desired.Annotations["spec"]
+				},
+				ApplyConfiguration: func(desired *metav1.ObjectMeta) *metav1.ObjectMeta {
+					return desired
+				},
+				Skipped: func(error) bool {
+					return tc.skipFailed
+				},
+			}, tc.opts)
+
+			err := r.Reconcile(context.Background())
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+
+			sort.Strings(client.applied)
+			if fmt.Sprint(client.applied) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected applied objects %v, got %v", tc.expectedApplied, client.applied)
+			}
+			sort.Strings(client.released)
+			if fmt.Sprint(client.released) != fmt.Sprint(tc.expectedRelease) {
+				t.Errorf("expected released objects %v, got %v", tc.expectedRelease, client.released)
+			}
+			if fmt.Sprint(client.deleted) != fmt.Sprint(tc.expectedDeleted) {
+				t.Errorf("expected deleted objects %v, got %v", tc.expectedDeleted, client.deleted)
+			}
+			var objects []string
+			for key := range client.objects {
+				objects = append(objects, key)
+			}
+			sort.Strings(objects)
+			if fmt.Sprint(objects) != fmt.Sprint(tc.expectedObjects) {
+				t.Errorf("expected objects %v, got %v", tc.expectedObjects, objects)
+			}
+			if i := slices.Index(client.requests, "delete"); i >= 0 && slices.Contains(client.requests[i:], "apply") {
+				t.Errorf("expected stale objects to be deleted after applying desired objects, got requests %v", client.requests)
+			}
+			for _, key := range tc.expectedDeleted {
+				namespace, name, _ := strings.Cut(key, "/")
+				deleted := &metav1.ObjectMeta{Namespace: namespace, Name: name}
+				if recorded := tc.opts.DeletedResources.consume("", deleted); recorded == tc.opts.DryRun {
+					t.Errorf("expected deletion of %s to be recorded: %t", key, !tc.opts.DryRun)
+				}
+			}
+			if tc.opts.DryRun && client.dryRunCalls != len(tc.expectedApplied)+len(tc.expectedDeleted) {
+				t.Errorf("expected all requests to be sent in dry-run mode, got %d dry-run requests", client.dryRunCalls)
+			}
+		})
+	}
+}
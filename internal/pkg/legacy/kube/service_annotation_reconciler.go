@@ -0,0 +1,174 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+	v1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/exportstatus"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+// exportStatusFieldManager owns export status annotations of local services. A separate field manager ensures that
+// applying a configuration without annotations removes only the annotations previously applied by this reconciler.
+// The name of the local peer is appended to it, so that federations running in the same cluster do not remove
+// annotations applied by each other. Annotations applied without the peer name by previous versions of the controller
+// are removed by any federation.
+const exportStatusFieldManager = "federation-controller-export-status"
+
+var _ Reconciler = (*ServiceAnnotationReconciler)(nil)
+
+// ServiceAnnotationReconciler annotates exported services with their export status
+// and removes the annotations from services that are no longer exported.
+type ServiceAnnotationReconciler struct {
+	client        kubernetes.Interface
+	serviceLister v1.ServiceLister
+	cf            *exportstatus.ConfigFactory
+	opts          ReconcileOptions
+}
+
+func NewServiceAnnotationReconciler(
+	client kubernetes.Interface,
+	serviceLister v1.ServiceLister,
+	cf *exportstatus.ConfigFactory,
+	opts ReconcileOptions,
+) *ServiceAnnotationReconciler {
+	return &ServiceAnnotationReconciler{
+		client:        client,
+		serviceLister: serviceLister,
+		cf:            cf,
+		opts:          opts,
+	}
+}
+
+func (r *ServiceAnnotationReconciler) GetTypeUrl() string {
+	return xds.ServiceTypeUrl
+}
+
+func (r *ServiceAnnotationReconciler) Reconcile(ctx context.Context) error {
+	serviceAnnotations, err := r.cf.ServiceAnnotations()
+	if err != nil {
+		return fmt.Errorf("error generating export status annotations: %w", err)
+	}
+
+	var errs []error
+	fieldManager := r.fieldManager()
+	for k, annotations := range serviceAnnotations {
+		svc, err := r.serviceLister.Services(k.Namespace).Get(k.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get service %s: %w", k, err))
+			continue
+		}
+		if annotationsUpToDate(svc.Annotations, annotations, r.cf.AnnotationKeys()) {
+			continue
+		}
+		if annotations == nil && !managedBy(svc, fieldManager) {
+			continue
+		}
+		if err := r.apply(ctx, k, annotations, fieldManager); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := r.removeLegacyAnnotations(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// removeLegacyAnnotations removes annotations without a peer name applied by previous versions of the controller.
+func (r *ServiceAnnotationReconciler) removeLegacyAnnotations(ctx context.Context) error {
+	if r.fieldManager() == exportStatusFieldManager {
+		return nil
+	}
+	services, err := r.serviceLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+	var errs []error
+	for _, svc := range services {
+		if !managedBy(svc, exportStatusFieldManager) {
+			continue
+		}
+		k := types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}
+		if err := r.apply(ctx, k, nil, exportStatusFieldManager); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply applies export status annotations of a service as the given field manager. Applying nil annotations removes
+// annotations previously applied by the field manager.
+func (r *ServiceAnnotationReconciler) apply(ctx context.Context, k types.NamespacedName, annotations map[string]string, fieldManager string) error {
+	applyOpts := metav1.ApplyOptions{FieldManager: fieldManager, Force: true}
+	if r.opts.DryRun {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	applyConfig := corev1ac.Service(k.Name, k.Namespace)
+	if annotations != nil {
+		applyConfig = applyConfig.WithAnnotations(annotations)
+	}
+	if _, err := r.client.CoreV1().Services(k.Namespace).Apply(ctx, applyConfig, applyOpts); err != nil {
+		return fmt.Errorf("failed to apply export status annotations to service %s: %w", k, err)
+	}
+	switch {
+	case r.opts.DryRun:
+		log.Infof("Dry-run: export status annotations of service %s would be set to %v", k, annotations)
+	case annotations == nil:
+		log.Infof("Removed export status annotations from service %s", k)
+	default:
+		log.Infof("Applied export status annotations to service %s: %v", k, annotations)
+	}
+	return nil
+}
+
+func (r *ServiceAnnotationReconciler) fieldManager() string {
+	if r.opts.Peer == "" {
+		return exportStatusFieldManager
+	}
+	return fmt.Sprintf("%s-%s", exportStatusFieldManager, r.opts.Peer)
+}
+
+// managedBy returns true if any fields of the service are owned by the given field manager.
+func managedBy(svc *corev1.Service, fieldManager string) bool {
+	for _, entry := range svc.ManagedFields {
+		if entry.Manager == fieldManager {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationsUpToDate returns true if the given annotations of a live service equal the desired annotations.
+func annotationsUpToDate(live, desired map[string]string, keys []string) bool {
+	for _, key := range keys {
+		liveValue, liveFound := live[key]
+		desiredValue, desiredFound := desired[key]
+		if liveFound != desiredFound || liveValue != desiredValue {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	v1 "k8s.io/client-go/listers/core/v1"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/exportstatus"
+)
+
+func TestServiceAnnotationReconciler(t *testing.T) {
+	ctx := context.Background()
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "httpbin",
+			Namespace:   "ns1",
+			Labels:      map[string]string{"export": "true"},
+			Annotations: map[string]string{"owner": "app-team"},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 8000, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	client := fake.NewClientset(svc)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	serviceLister := informerFactory.Core().V1().Services().Lister()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+
+	// Annotations applied without the peer name by a previous version of the controller.
+	legacyAnnotations := corev1ac.Service("httpbin", "ns1").WithAnnotations(map[string]string{exportstatus.AnnotationExportedTo: "west"})
+	if _, err := client.CoreV1().Services("ns1").Apply(ctx, legacyAnnotations, metav1.ApplyOptions{FieldManager: exportStatusFieldManager}); err != nil {
+		t.Fatalf("failed to apply legacy annotations: %v", err)
+	}
+	waitForService(t, serviceLister, "ns1/httpbin", func(svc *corev1.Service) bool {
+		return exportstatus.HasAnnotations(svc, "")
+	})
+
+	exportRules := config.ExportedServiceSet{
+		Rules: []config.Rules{{
+			Type:           config.LabelSelectorRuleType,
+			LabelSelectors: []config.LabelSelectors{{MatchLabels: map[string]string{"export": "true"}}},
+		}},
+	}
+	cfg := config.Federation{
+		MeshPeers: config.MeshPeers{
+			Local:   config.Local{Name: "east", ControlPlane: config.ControlPlane{Namespace: "istio-system"}},
+			Remotes: []config.Remote{{Name: "west"}},
+		},
+		ExportedServiceSet: exportRules,
+	}
+	cf := exportstatus.NewConfigFactory(cfg, serviceLister, common.NewExportedServiceLister(cfg, serviceLister, nil, nil))
+	r := NewServiceAnnotationReconciler(client, serviceLister, cf, ReconcileOptions{Peer: "east"})
+
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	annotated := waitForService(t, serviceLister, "ns1/httpbin", func(svc *corev1.Service) bool {
+		return exportstatus.HasAnnotations(svc, "east") && !exportstatus.HasAnnotations(svc, "")
+	})
+	if annotated.Annotations[exportstatus.AnnotationKey("east", exportstatus.AnnotationExportedTo)] != "west" {
+		t.Errorf("expected service to be exported to west, got annotations %v", annotated.Annotations)
+	}
+	if annotated.Annotations["owner"] != "app-team" {
+		t.Errorf("expected annotations not managed by the reconciler to be preserved, got %v", annotated.Annotations)
+	}
+
+	// Another federation exporting the same service must report its status independently.
+	otherCfg := config.Federation{
+		MeshPeers: config.MeshPeers{
+			Local:   config.Local{Name: "north", ControlPlane: config.ControlPlane{Namespace: "istio-system-north"}},
+			Remotes: []config.Remote{{Name: "south"}},
+		},
+		ExportedServiceSet: exportRules,
+	}
+	other := NewServiceAnnotationReconciler(client, serviceLister,
+		exportstatus.NewConfigFactory(otherCfg, serviceLister, common.NewExportedServiceLister(otherCfg, serviceLister, nil, nil)),
+		ReconcileOptions{Peer: "north"})
+	if err := other.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	annotated = waitForService(t, serviceLister, "ns1/httpbin", func(svc *corev1.Service) bool {
+		return exportstatus.HasAnnotations(svc, "north")
+	})
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc, _ := client.CoreV1().Services("ns1").Get(ctx, "httpbin", metav1.GetOptions{}); svc.Annotations[exportstatus.AnnotationKey("east", exportstatus.AnnotationExportedTo)] != "west" ||
+		svc.Annotations[exportstatus.AnnotationKey("north", exportstatus.AnnotationExportedTo)] != "south" {
+		t.Errorf("expected each federation to keep its own annotations, got %v", svc.Annotations)
+	}
+
+	// Removing the export label must remove export status annotations.
+	unexported := annotated.DeepCopy()
+	unexported.Labels = nil
+	if _, err := client.CoreV1().Services("ns1").Update(ctx, unexported, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update service: %v", err)
+	}
+	waitForService(t, serviceLister, "ns1/httpbin", func(svc *corev1.Service) bool {
+		return len(svc.Labels) == 0
+	})
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cleaned := waitForService(t, serviceLister, "ns1/httpbin", func(svc *corev1.Service) bool {
+		return !exportstatus.HasAnnotations(svc, "east")
+	})
+	if !exportstatus.HasAnnotations(cleaned, "north") {
+		t.Errorf("expected annotations applied by another federation to be preserved, got %v", cleaned.Annotations)
+	}
+	if cleaned.Annotations["owner"] != "app-team" {
+		t.Errorf("expected annotations not managed by the reconciler to be preserved, got %v", cleaned.Annotations)
+	}
+}
+
+func waitForService(t *testing.T, serviceLister v1.ServiceLister, key string, condition func(*corev1.Service) bool) *corev1.Service {
+	t.Helper()
+	namespace, name, _ := strings.Cut(key, "/")
+	var svc *corev1.Service
+	err := wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		var err error
+		if svc, err = serviceLister.Services(namespace).Get(name); err != nil {
+			return false, nil
+		}
+		return condition(svc), nil
+	})
+	if err != nil {
+		t.Fatalf("service %s did not reach the expected state: %v", key, svc)
+	}
+	return svc
+}
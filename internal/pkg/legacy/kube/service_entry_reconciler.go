@@ -15,107 +15,82 @@
 package kube
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 
+	networkingv1 "istio.io/client-go/pkg/apis/networking/v1"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
-	applymetav1 "istio.io/client-go/pkg/applyconfiguration/meta/v1"
+	applyv1 "istio.io/client-go/pkg/applyconfiguration/networking/v1"
 	applyv1alpha3 "istio.io/client-go/pkg/applyconfiguration/networking/v1alpha3"
 	"istio.io/istio/pkg/kube"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
-var _ Reconciler = (*ServiceEntryReconciler)(nil)
-
-type ServiceEntryReconciler struct {
-	client kube.Client
-	cf     *istio.ConfigFactory
-}
-
-func NewServiceEntryReconciler(client kube.Client, cf *istio.ConfigFactory) *ServiceEntryReconciler {
-	return &ServiceEntryReconciler{
-		client: client,
-		cf:     cf,
+// NewServiceEntryReconciler creates a reconciler of service entry resources in the networking API version served by the cluster.
+func NewServiceEntryReconciler(client kube.Client, cf *istio.ConfigFactory, apiVersions APIVersions, opts ReconcileOptions) Reconciler {
+	if apiVersions.Networking == NetworkingV1alpha3 {
+		return newServiceEntryReconcilerV1alpha3(client, cf, opts)
 	}
+	return NewResourceReconciler(ResourceReconcilerConfig[*networkingv1.ServiceEntry, *applyv1.ServiceEntryApplyConfiguration]{
+		TypeUrl: xds.ServiceEntryTypeUrl,
+		Name:    "service entry",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*networkingv1.ServiceEntry, *applyv1.ServiceEntryApplyConfiguration, *networkingv1.ServiceEntryList] {
+				return client.Istio().NetworkingV1().ServiceEntries(namespace)
+			},
+			func(list *networkingv1.ServiceEntryList) []*networkingv1.ServiceEntry {
+				return list.Items
+			},
+		),
+		Desired: cf.ServiceEntries,
+		UpToDate: func(live, desired *networkingv1.ServiceEntry) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && managedInVersion(live, opts.fieldManager(), NetworkingV1)
+		},
+		ApplyConfiguration: func(se *networkingv1.ServiceEntry) *applyv1.ServiceEntryApplyConfiguration {
+			ac := applyv1.ServiceEntry(se.Name, se.Namespace).WithLabels(se.Labels)
+			ac.Spec = &se.Spec
+			return ac
+		},
+	}, opts)
 }
 
-func (r *ServiceEntryReconciler) GetTypeUrl() string {
-	return xds.ServiceEntryTypeUrl
-}
-
-func (r *ServiceEntryReconciler) Reconcile(ctx context.Context) error {
-	serviceEntries, err := r.cf.ServiceEntries()
-	if err != nil {
-		return fmt.Errorf("error generating service entries: %w", err)
-	}
-	serviceEntriesMap := make(map[types.NamespacedName]*v1alpha3.ServiceEntry, len(serviceEntries))
-	for _, se := range serviceEntries {
-		serviceEntriesMap[types.NamespacedName{Namespace: se.Namespace, Name: se.Name}] = se
-	}
-
-	oldServiceEntries, err := r.client.Istio().NetworkingV1alpha3().ServiceEntries(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
-		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{
-			MatchLabels: map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-		}),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list service entries: %w", err)
-	}
-	oldServiceEntriesMap := make(map[types.NamespacedName]*v1alpha3.ServiceEntry, len(oldServiceEntries.Items))
-	for _, se := range oldServiceEntries.Items {
-		oldServiceEntriesMap[types.NamespacedName{Namespace: se.Namespace, Name: se.Name}] = se
-	}
-
-	kind := "ServiceEntry"
-	apiVersion := "networking.istio.io/v1alpha3"
-	for k, se := range serviceEntriesMap {
-		oldSE, ok := oldServiceEntriesMap[k]
-		if !ok || !reflect.DeepEqual(&oldSE.Spec, &se.Spec) {
-			// Service entry does not currently exist or requires update
-			newSE, err := r.client.Istio().NetworkingV1alpha3().ServiceEntries(se.GetNamespace()).Apply(ctx,
-				&applyv1alpha3.ServiceEntryApplyConfiguration{
-					TypeMetaApplyConfiguration: applymetav1.TypeMetaApplyConfiguration{
-						Kind:       &kind,
-						APIVersion: &apiVersion,
-					},
-					ObjectMetaApplyConfiguration: &applymetav1.ObjectMetaApplyConfiguration{
-						Name:      &se.Name,
-						Namespace: &se.Namespace,
-						Labels:    se.Labels,
-					},
-					Spec: &se.Spec,
-				},
-				metav1.ApplyOptions{
-					TypeMeta: metav1.TypeMeta{
-						Kind:       kind,
-						APIVersion: apiVersion,
-					},
-					Force:        true,
-					FieldManager: "federation-controller",
-				},
-			)
+// newServiceEntryReconcilerV1alpha3 applies resources in v1alpha3 for meshes that do not serve networking.istio.io/v1.
+func newServiceEntryReconcilerV1alpha3(client kube.Client, cf *istio.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1alpha3.ServiceEntry, *applyv1alpha3.ServiceEntryApplyConfiguration]{
+		TypeUrl: xds.ServiceEntryTypeUrl,
+		Name:    "service entry",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1alpha3.ServiceEntry, *applyv1alpha3.ServiceEntryApplyConfiguration, *v1alpha3.ServiceEntryList] {
+				return client.Istio().NetworkingV1alpha3().ServiceEntries(namespace)
+			},
+			func(list *v1alpha3.ServiceEntryList) []*v1alpha3.ServiceEntry {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1alpha3.ServiceEntry, error) {
+			serviceEntries, err := cf.ServiceEntries()
 			if err != nil {
-				return fmt.Errorf("failed to apply service entry: %w", err)
+				return nil, err
 			}
-			log.Infof("Applied service entry: %v", newSE)
-		}
-	}
-
-	for k, oldSE := range oldServiceEntriesMap {
-		if _, ok := serviceEntriesMap[k]; !ok {
-			err := r.client.Istio().NetworkingV1alpha3().ServiceEntries(oldSE.GetNamespace()).Delete(ctx, oldSE.GetName(), metav1.DeleteOptions{})
-			if client.IgnoreNotFound(err) != nil {
-				return fmt.Errorf("failed to delete old service entry: %w", err)
+			converted := make([]*v1alpha3.ServiceEntry, 0, len(serviceEntries))
+			for _, se := range serviceEntries {
+				c := &v1alpha3.ServiceEntry{ObjectMeta: se.ObjectMeta}
+				if err := convertSpec(&se.Spec, &c.Spec); err != nil {
+					return nil, fmt.Errorf("failed to convert service entry %s/%s to v1alpha3: %w", se.Namespace, se.Name, err)
+				}
+				converted = append(converted, c)
 			}
-			log.Infof("Deleted service entry: %v", oldSE)
-		}
-	}
-
-	return nil
+			return converted, nil
+		},
+		UpToDate: func(live, desired *v1alpha3.ServiceEntry) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: func(se *v1alpha3.ServiceEntry) *applyv1alpha3.ServiceEntryApplyConfiguration {
+			ac := applyv1alpha3.ServiceEntry(se.Name, se.Namespace).WithLabels(se.Labels)
+			ac.Spec = &se.Spec
+			return ac
+		},
+	}, opts)
 }
@@ -0,0 +1,123 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/mcs-api/pkg/client/clientset/versioned"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+	"github.com/openshift-service-mesh/federation/internal/pkg/mcs"
+)
+
+var _ Reconciler = (*ServiceExportStatusReconciler)(nil)
+
+// ServiceExportStatusReconciler reports export conditions (Valid, Conflict) in the status of ServiceExports.
+// ServiceExports are owned by users, so the reconciler only updates their status instead of using ResourceReconciler.
+// Failures of individual service exports do not stop the reconciliation of others, and are returned together.
+type ServiceExportStatusReconciler struct {
+	client versioned.Interface
+	cf     *mcs.ConfigFactory
+	opts   ReconcileOptions
+}
+
+func NewServiceExportStatusReconciler(client versioned.Interface, cf *mcs.ConfigFactory, opts ReconcileOptions) *ServiceExportStatusReconciler {
+	return &ServiceExportStatusReconciler{
+		client: client,
+		cf:     cf,
+		opts:   opts,
+	}
+}
+
+func (r *ServiceExportStatusReconciler) GetTypeUrl() string {
+	return xds.ServiceExportTypeUrl
+}
+
+func (r *ServiceExportStatusReconciler) Reconcile(ctx context.Context) error {
+	conditions, err := r.cf.ServiceExportConditions()
+	if err != nil {
+		return fmt.Errorf("error generating service export conditions: %w", err)
+	}
+
+	updateOpts := metav1.UpdateOptions{}
+	if r.opts.DryRun {
+		updateOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	var errs []error
+	for k, newConditions := range conditions {
+		se, err := r.client.MulticlusterV1alpha1().ServiceExports(k.Namespace).Get(ctx, k.Name, metav1.GetOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get service export %s: %w", k, err))
+			continue
+		}
+		updatedConditions, changed := mergeConditions(se.Status.Conditions, newConditions)
+		if !changed {
+			continue
+		}
+		se.Status.Conditions = updatedConditions
+		if _, err := r.client.MulticlusterV1alpha1().ServiceExports(k.Namespace).UpdateStatus(ctx, se, updateOpts); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update status of service export %s: %w", k, err))
+			continue
+		}
+		if r.opts.DryRun {
+			log.Infof("Dry-run: status of service export %s would be updated: %v", k, updatedConditions)
+			continue
+		}
+		log.Infof("Updated status of service export %s: %v", k, updatedConditions)
+	}
+
+	return errors.Join(errs...)
+}
+
+// mergeConditions replaces existing conditions with new conditions of the same type and appends missing ones.
+// Last transition time is updated only if the condition status changed.
+func mergeConditions(
+	existing []mcsv1alpha1.ServiceExportCondition,
+	newConditions []mcsv1alpha1.ServiceExportCondition,
+) ([]mcsv1alpha1.ServiceExportCondition, bool) {
+	now := metav1.Now()
+	merged := make([]mcsv1alpha1.ServiceExportCondition, 0, len(newConditions))
+	changed := len(existing) != len(newConditions)
+	for _, newCondition := range newConditions {
+		newCondition.LastTransitionTime = &now
+		found := false
+		for _, oldCondition := range existing {
+			if oldCondition.Type != newCondition.Type {
+				continue
+			}
+			found = true
+			if oldCondition.Status == newCondition.Status {
+				newCondition.LastTransitionTime = oldCondition.LastTransitionTime
+			}
+			if oldCondition.Status != newCondition.Status ||
+				ptr.Deref(oldCondition.Reason, "") != ptr.Deref(newCondition.Reason, "") ||
+				ptr.Deref(oldCondition.Message, "") != ptr.Deref(newCondition.Message, "") {
+				changed = true
+			}
+			break
+		}
+		if !found {
+			changed = true
+		}
+		merged = append(merged, newCondition)
+	}
+	return merged, changed
+}
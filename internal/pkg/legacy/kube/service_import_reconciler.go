@@ -0,0 +1,129 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/mcs-api/pkg/client/clientset/versioned"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+	"github.com/openshift-service-mesh/federation/internal/pkg/mcs"
+)
+
+// NewServiceImportReconciler creates a reconciler of ServiceImports of services imported from remote peers.
+// Service imports in namespaces that do not exist in this cluster are skipped.
+func NewServiceImportReconciler(client versioned.Interface, cf *mcs.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*mcsv1alpha1.ServiceImport, *mcsv1alpha1.ServiceImport]{
+		TypeUrl: xds.ServiceImportTypeUrl,
+		Name:    "service import",
+		Client:  &serviceImportClient{client: client},
+		Desired: func() ([]*mcsv1alpha1.ServiceImport, error) {
+			return cf.ServiceImports(), nil
+		},
+		UpToDate: func(live, desired *mcsv1alpha1.ServiceImport) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && reflect.DeepEqual(&live.Status, &desired.Status)
+		},
+		ApplyConfiguration: func(si *mcsv1alpha1.ServiceImport) *mcsv1alpha1.ServiceImport {
+			return &mcsv1alpha1.ServiceImport{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ServiceImport",
+					APIVersion: mcsv1alpha1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      si.Name,
+					Namespace: si.Namespace,
+					Labels:    si.Labels,
+				},
+				Spec:   si.Spec,
+				Status: si.Status,
+			}
+		},
+		Skipped: errors.IsNotFound,
+	}, opts)
+}
+
+// serviceImportClient adapts the MCS clientset, which does not provide apply configurations, to ResourceClient.
+// Service imports are applied as JSON patches built from the object.
+type serviceImportClient struct {
+	client versioned.Interface
+}
+
+func (c *serviceImportClient) List(ctx context.Context, opts metav1.ListOptions) ([]*mcsv1alpha1.ServiceImport, error) {
+	list, err := c.client.MulticlusterV1alpha1().ServiceImports(metav1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	serviceImports := make([]*mcsv1alpha1.ServiceImport, 0, len(list.Items))
+	for i := range list.Items {
+		serviceImports = append(serviceImports, &list.Items[i])
+	}
+	return serviceImports, nil
+}
+
+// Apply applies the service import and its status. In the dry-run mode, the status of a service import that does not
+// exist yet cannot be applied, and is skipped.
+func (c *serviceImportClient) Apply(
+	ctx context.Context,
+	namespace string,
+	si *mcsv1alpha1.ServiceImport,
+	opts metav1.ApplyOptions,
+) (*mcsv1alpha1.ServiceImport, error) {
+	data, err := json.Marshal(si)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize service import: %w", err)
+	}
+	patchOpts := metav1.PatchOptions{
+		Force:        ptr.To(opts.Force),
+		FieldManager: opts.FieldManager,
+		DryRun:       opts.DryRun,
+	}
+	serviceImports := c.client.MulticlusterV1alpha1().ServiceImports(namespace)
+	applied, err := serviceImports.Patch(ctx, si.Name, types.ApplyPatchType, data, patchOpts)
+	if err != nil {
+		return nil, err
+	}
+	withStatus, err := serviceImports.Patch(ctx, si.Name, types.ApplyPatchType, data, patchOpts, "status")
+	if err != nil {
+		if len(opts.DryRun) > 0 && errors.IsNotFound(err) {
+			return applied, nil
+		}
+		return nil, fmt.Errorf("failed to apply status: %w", err)
+	}
+	return withStatus, nil
+}
+
+func (c *serviceImportClient) Patch(
+	ctx context.Context,
+	namespace, name string,
+	pt types.PatchType,
+	data []byte,
+	opts metav1.PatchOptions,
+) error {
+	_, err := c.client.MulticlusterV1alpha1().ServiceImports(namespace).Patch(ctx, name, pt, data, opts)
+	return err
+}
+
+func (c *serviceImportClient) Delete(ctx context.Context, namespace, name string, opts metav1.DeleteOptions) error {
+	return c.client.MulticlusterV1alpha1().ServiceImports(namespace).Delete(ctx, name, opts)
+}
@@ -0,0 +1,77 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"reflect"
+
+	"istio.io/istio/pkg/kube"
+	gwv1apply "sigs.k8s.io/gateway-api/apis/applyconfiguration/apis/v1"
+	gwv1alpha2apply "sigs.k8s.io/gateway-api/apis/applyconfiguration/apis/v1alpha2"
+	gwv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/openshift-service-mesh/federation/internal/pkg/gatewayapi"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
+)
+
+// NewTLSRouteReconciler creates a reconciler of TLS routes exposing exported services on the federation ingress gateway.
+func NewTLSRouteReconciler(client kube.Client, cf *gatewayapi.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*gwv1alpha2.TLSRoute, *gwv1alpha2apply.TLSRouteApplyConfiguration]{
+		TypeUrl: xds.TLSRouteTypeUrl,
+		Name:    "TLS route",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*gwv1alpha2.TLSRoute, *gwv1alpha2apply.TLSRouteApplyConfiguration, *gwv1alpha2.TLSRouteList] {
+				return client.GatewayAPI().GatewayV1alpha2().TLSRoutes(namespace)
+			},
+			func(list *gwv1alpha2.TLSRouteList) []*gwv1alpha2.TLSRoute {
+				routes := make([]*gwv1alpha2.TLSRoute, 0, len(list.Items))
+				for i := range list.Items {
+					routes = append(routes, &list.Items[i])
+				}
+				return routes
+			},
+		),
+		Desired: cf.TLSRoutes,
+		UpToDate: func(live, desired *gwv1alpha2.TLSRoute) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: toTLSRouteApplyConfiguration,
+	}, opts)
+}
+
+func toTLSRouteApplyConfiguration(route *gwv1alpha2.TLSRoute) *gwv1alpha2apply.TLSRouteApplyConfiguration {
+	spec := gwv1alpha2apply.TLSRouteSpec().WithHostnames(route.Spec.Hostnames...)
+	for _, p := range route.Spec.ParentRefs {
+		parentRef := gwv1apply.ParentReference().WithName(p.Name)
+		if p.Namespace != nil {
+			parentRef.WithNamespace(*p.Namespace)
+		}
+		spec.WithParentRefs(parentRef)
+	}
+	for _, r := range route.Spec.Rules {
+		rule := gwv1alpha2apply.TLSRouteRule()
+		for _, b := range r.BackendRefs {
+			backendRef := gwv1apply.BackendRef().WithName(b.Name)
+			if b.Port != nil {
+				backendRef.WithPort(*b.Port)
+			}
+			rule.WithBackendRefs(backendRef)
+		}
+		spec.WithRules(rule)
+	}
+	return gwv1alpha2apply.TLSRoute(route.Name, route.Namespace).
+		WithLabels(route.Labels).
+		WithSpec(spec)
+}
@@ -15,108 +15,82 @@
 package kube
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 
+	networkingv1 "istio.io/client-go/pkg/apis/networking/v1"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
-	applymetav1 "istio.io/client-go/pkg/applyconfiguration/meta/v1"
+	applyv1 "istio.io/client-go/pkg/applyconfiguration/networking/v1"
 	applyv1alpha3 "istio.io/client-go/pkg/applyconfiguration/networking/v1alpha3"
 	"istio.io/istio/pkg/kube"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift-service-mesh/federation/internal/pkg/istio"
 	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/xds"
 )
 
-var _ Reconciler = (*WorkloadEntryReconciler)(nil)
-
-type WorkloadEntryReconciler struct {
-	client kube.Client
-	cf     *istio.ConfigFactory
-}
-
-func NewWorkloadEntryReconciler(client kube.Client, cf *istio.ConfigFactory) *WorkloadEntryReconciler {
-	return &WorkloadEntryReconciler{
-		client: client,
-		cf:     cf,
+// NewWorkloadEntryReconciler creates a reconciler of workload entry resources in the networking API version served by the cluster.
+func NewWorkloadEntryReconciler(client kube.Client, cf *istio.ConfigFactory, apiVersions APIVersions, opts ReconcileOptions) Reconciler {
+	if apiVersions.Networking == NetworkingV1alpha3 {
+		return newWorkloadEntryReconcilerV1alpha3(client, cf, opts)
 	}
+	return NewResourceReconciler(ResourceReconcilerConfig[*networkingv1.WorkloadEntry, *applyv1.WorkloadEntryApplyConfiguration]{
+		TypeUrl: xds.WorkloadEntryTypeUrl,
+		Name:    "workload entry",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*networkingv1.WorkloadEntry, *applyv1.WorkloadEntryApplyConfiguration, *networkingv1.WorkloadEntryList] {
+				return client.Istio().NetworkingV1().WorkloadEntries(namespace)
+			},
+			func(list *networkingv1.WorkloadEntryList) []*networkingv1.WorkloadEntry {
+				return list.Items
+			},
+		),
+		Desired: cf.WorkloadEntries,
+		UpToDate: func(live, desired *networkingv1.WorkloadEntry) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec) && managedInVersion(live, opts.fieldManager(), NetworkingV1)
+		},
+		ApplyConfiguration: func(we *networkingv1.WorkloadEntry) *applyv1.WorkloadEntryApplyConfiguration {
+			ac := applyv1.WorkloadEntry(we.Name, we.Namespace).WithLabels(we.Labels)
+			ac.Spec = &we.Spec
+			return ac
+		},
+	}, opts)
 }
 
-func (r *WorkloadEntryReconciler) GetTypeUrl() string {
-	return xds.WorkloadEntryTypeUrl
-}
-
-func (r *WorkloadEntryReconciler) Reconcile(ctx context.Context) error {
-	workloadEntries, err := r.cf.WorkloadEntries()
-	if err != nil {
-		return fmt.Errorf("error generating workload entries: %w", err)
-	}
-	workloadEntriesMap := make(map[types.NamespacedName]*v1alpha3.WorkloadEntry, len(workloadEntries))
-	for _, we := range workloadEntries {
-		workloadEntriesMap[types.NamespacedName{Namespace: we.Namespace, Name: we.Name}] = we
-	}
-
-	oldWorkloadEntries, err := r.client.Istio().NetworkingV1alpha3().WorkloadEntries(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
-		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{
-			MatchLabels: map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
-		}),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list workload entries: %w", err)
-	}
-	oldWorkloadEntriesMap := make(map[types.NamespacedName]*v1alpha3.WorkloadEntry, len(oldWorkloadEntries.Items))
-	for _, we := range oldWorkloadEntries.Items {
-		oldWorkloadEntriesMap[types.NamespacedName{Namespace: we.Namespace, Name: we.Name}] = we
-	}
-
-	kind := "WorkloadEntry"
-	apiVersion := "networking.istio.io/v1alpha3"
-	for k, we := range workloadEntriesMap {
-		oldWE, ok := oldWorkloadEntriesMap[k]
-		if !ok || !reflect.DeepEqual(&oldWE.Spec, &we.Spec) {
-			// Workload entry does not currently exist or requires update
-			newWE, err := r.client.Istio().NetworkingV1alpha3().WorkloadEntries(we.GetNamespace()).Apply(ctx,
-				&applyv1alpha3.WorkloadEntryApplyConfiguration{
-					TypeMetaApplyConfiguration: applymetav1.TypeMetaApplyConfiguration{
-						Kind:       &kind,
-						APIVersion: &apiVersion,
-					},
-					ObjectMetaApplyConfiguration: &applymetav1.ObjectMetaApplyConfiguration{
-						Name:      &we.Name,
-						Namespace: &we.Namespace,
-						Labels:    we.Labels,
-					},
-					Spec:   &we.Spec,
-					Status: nil,
-				},
-				metav1.ApplyOptions{
-					TypeMeta: metav1.TypeMeta{
-						Kind:       kind,
-						APIVersion: apiVersion,
-					},
-					Force:        true,
-					FieldManager: "federation-controller",
-				},
-			)
+// newWorkloadEntryReconcilerV1alpha3 applies resources in v1alpha3 for meshes that do not serve networking.istio.io/v1.
+func newWorkloadEntryReconcilerV1alpha3(client kube.Client, cf *istio.ConfigFactory, opts ReconcileOptions) Reconciler {
+	return NewResourceReconciler(ResourceReconcilerConfig[*v1alpha3.WorkloadEntry, *applyv1alpha3.WorkloadEntryApplyConfiguration]{
+		TypeUrl: xds.WorkloadEntryTypeUrl,
+		Name:    "workload entry",
+		Client: newNamespacedClient(
+			func(namespace string) typedClient[*v1alpha3.WorkloadEntry, *applyv1alpha3.WorkloadEntryApplyConfiguration, *v1alpha3.WorkloadEntryList] {
+				return client.Istio().NetworkingV1alpha3().WorkloadEntries(namespace)
+			},
+			func(list *v1alpha3.WorkloadEntryList) []*v1alpha3.WorkloadEntry {
+				return list.Items
+			},
+		),
+		Desired: func() ([]*v1alpha3.WorkloadEntry, error) {
+			workloadEntries, err := cf.WorkloadEntries()
 			if err != nil {
-				return fmt.Errorf("failed to apply workload entry: %w", err)
+				return nil, err
 			}
-			log.Infof("Applied workload entry: %v", newWE)
-		}
-	}
-
-	for k, oldWE := range oldWorkloadEntriesMap {
-		if _, ok := workloadEntriesMap[k]; !ok {
-			err := r.client.Istio().NetworkingV1alpha3().WorkloadEntries(oldWE.GetNamespace()).Delete(ctx, oldWE.GetName(), metav1.DeleteOptions{})
-			if client.IgnoreNotFound(err) != nil {
-				return fmt.Errorf("failed to delete old workload entry: %w", err)
+			converted := make([]*v1alpha3.WorkloadEntry, 0, len(workloadEntries))
+			for _, we := range workloadEntries {
+				c := &v1alpha3.WorkloadEntry{ObjectMeta: we.ObjectMeta}
+				if err := convertSpec(&we.Spec, &c.Spec); err != nil {
+					return nil, fmt.Errorf("failed to convert workload entry %s/%s to v1alpha3: %w", we.Namespace, we.Name, err)
+				}
+				converted = append(converted, c)
 			}
-			log.Infof("Deleted workload entry: %v", oldWE)
-		}
-	}
-
-	return nil
+			return converted, nil
+		},
+		UpToDate: func(live, desired *v1alpha3.WorkloadEntry) bool {
+			return reflect.DeepEqual(&live.Spec, &desired.Spec)
+		},
+		ApplyConfiguration: func(we *v1alpha3.WorkloadEntry) *applyv1alpha3.WorkloadEntryApplyConfiguration {
+			ac := applyv1alpha3.WorkloadEntry(we.Name, we.Namespace).WithLabels(we.Labels)
+			ac.Spec = &we.Spec
+			return ac
+		},
+	}, opts)
 }
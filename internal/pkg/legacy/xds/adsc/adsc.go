@@ -19,12 +19,17 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand/v2"
+	"net"
+	"sync/atomic"
 	"time"
 
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 	istiolog "istio.io/istio/pkg/log"
 )
 
@@ -32,128 +37,223 @@ const (
 	defaultClientMaxReceiveMessageSize = math.MaxInt32
 	defaultInitialConnWindowSize       = 1024 * 1024 // default gRPC InitialWindowSize
 	defaultInitialWindowSize           = 1024 * 1024 // default gRPC ConnWindowSize
+	defaultMinConnectTimeout           = 5 * time.Second
+
+	endpointsScheme        = "adsc"
+	pickFirstServiceConfig = `{"loadBalancingConfig": [{"pick_first": {}}]}`
 )
 
 type ADSCConfig struct {
-	RemoteName     string
-	DiscoveryAddr  string
+	RemoteName string
+	// DiscoveryAddrs are endpoints of the ADS server in the host:port format. Endpoints are tried in order
+	// on every (re)connect, so the client fails over to the next endpoint when the preceding ones are unreachable.
+	DiscoveryAddrs []string
 	Authority      string
 	Handlers       map[string]ResponseHandler
-	ReconnectDelay time.Duration
+	// Backoff configures delays between reconnects, which grow exponentially with every failed attempt
+	// and are randomized by the jitter. Defaults to backoff.DefaultConfig of gRPC.
+	Backoff *backoff.Config
+	// OnConnected is called with the active endpoint when the first response is received after establishing a stream.
+	OnConnected func(endpoint string)
+	// OnDisconnected is called when an established stream is closed by the server or the network.
+	OnDisconnected func(err error)
 }
 
+// ADSC is a client of an Aggregated Discovery Service server. It keeps a single stream open to the server
+// and reconnects with exponential backoff whenever the stream fails, moving through the states
+// Idle -> Connecting -> Syncing -> Ready, and Backoff -> Connecting after a failure.
 type ADSC struct {
-	stream discovery.AggregatedDiscoveryService_StreamAggregatedResourcesClient
-	conn   *grpc.ClientConn
-	cfg    *ADSCConfig
-	log    *istiolog.Scope
+	conn    *grpc.ClientConn
+	cfg     *ADSCConfig
+	backoff backoff.Config
+	log     *istiolog.Scope
+
+	// dialContext establishes connections to endpoints and can be replaced in tests
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// activeEndpoint is the endpoint of the last established connection
+	activeEndpoint atomic.Value
+	state          atomic.Int32
 }
 
 func New(opts *ADSCConfig) (*ADSC, error) {
 	if opts == nil {
 		return nil, errors.New("adsc: opts is nil")
 	}
+	if len(opts.DiscoveryAddrs) == 0 {
+		return nil, errors.New("adsc: no discovery addresses")
+	}
 	adsc := &ADSC{
-		cfg: opts,
-		log: istiolog.RegisterScope("adsc", "Aggregated Discovery Service Client").WithLabels("peer", opts.RemoteName),
+		cfg:         opts,
+		backoff:     backoff.DefaultConfig,
+		log:         istiolog.RegisterScope("adsc", "Aggregated Discovery Service Client").WithLabels("peer", opts.RemoteName),
+		dialContext: (&net.Dialer{}).DialContext,
+	}
+	if opts.Backoff != nil {
+		adsc.backoff = *opts.Backoff
 	}
 	if err := adsc.dial(); err != nil {
 		return nil, err
 	}
+	reportState(opts.RemoteName, StateIdle)
 
 	return adsc, nil
 }
 
-func (a *ADSC) Run(ctx context.Context) error {
-	client := discovery.NewAggregatedDiscoveryServiceClient(a.conn)
+// Run keeps a stream to the ADS server open until the context is cancelled. Handled resource types are requested
+// on every new stream, and received responses are passed to their handlers. Run blocks until the context is cancelled,
+// and then closes the connection, so a client can be run only once.
+func (a *ADSC) Run(ctx context.Context) {
+	defer func() {
+		if err := a.conn.Close(); err != nil {
+			a.log.Debugf("failed to close connection: %v", err)
+		}
+		a.setState(StateIdle)
+	}()
 
-	var err error
-	if a.stream, err = client.StreamAggregatedResources(ctx); err != nil {
-		return fmt.Errorf("failed setting resource stream: %w", err)
-	}
+	retries := 0
+	for {
+		a.setState(StateConnecting)
+		err := a.runStream(ctx, func() {
+			retries = 0
+		})
+		if ctx.Err() != nil {
+			return
+		}
 
-	for k, _ := range a.cfg.Handlers {
-		discoveryRequest := &discovery.DiscoveryRequest{TypeUrl: k}
-		if errSend := a.Send(discoveryRequest); errSend != nil {
-			a.log.Errorf("[%s] failed requesting initial discovery sync: %+v", k, errSend)
+		delay := a.backoffDelay(retries)
+		retries++
+		a.log.Errorf("connection to ADS server %v failed, will reconnect in %s: %v", a.cfg.DiscoveryAddrs, delay.Round(time.Millisecond), err)
+		a.setState(StateBackoff)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
 		}
 	}
+}
 
-	go a.handleRecv(ctx)
+// State returns the current state of the connection.
+func (a *ADSC) State() State {
+	return State(a.state.Load())
+}
 
-	return nil
+// ActiveEndpoint returns the endpoint of the ADS server the client is connected to, or an empty string
+// if no connection has been established yet.
+func (a *ADSC) ActiveEndpoint() string {
+	endpoint, _ := a.activeEndpoint.Load().(string)
+	return endpoint
 }
 
-func (a *ADSC) Restart(ctx context.Context) {
-	a.log.Infof("reconnecting to ADS server %s", a.cfg.DiscoveryAddr)
-	if err := a.Run(ctx); err != nil {
-		a.log.Errorf("failed to connect to ADS server %s, will reconnect in %s: %v", a.cfg.DiscoveryAddr, a.cfg.ReconnectDelay, err)
-		time.AfterFunc(a.cfg.ReconnectDelay, func() {
-			if errCtx := ctx.Err(); errCtx != nil {
-				a.log.Infof("Parent ctx is done: %v", errCtx)
-				return
-			}
+func (a *ADSC) setState(state State) {
+	if previous := State(a.state.Swap(int32(state))); previous != state {
+		a.log.Debugf("connection state changed from %s to %s", previous, state)
+		reportState(a.cfg.RemoteName, state)
+	}
+}
 
-			a.Restart(ctx)
-		})
+// runStream opens a stream, requests all handled resource types and handles responses until the stream fails.
+// onReady is called when the first response is received. The stream is cancelled on return, so that gRPC
+// does not leak goroutines of the stream.
+func (a *ADSC) runStream(ctx context.Context, onReady func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := discovery.NewAggregatedDiscoveryServiceClient(a.conn).StreamAggregatedResources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed setting resource stream: %w", err)
+	}
+	a.setState(StateSyncing)
+
+	for typeUrl := range a.cfg.Handlers {
+		req := &discovery.DiscoveryRequest{TypeUrl: typeUrl, ResponseNonce: time.Now().String()}
+		a.log.Infof("Sending Discovery Request to ADS server: %s", req.String())
+		if err := stream.Send(req); err != nil {
+			return fmt.Errorf("failed requesting initial discovery sync of %s: %w", typeUrl, err)
+		}
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if a.State() == StateReady && a.cfg.OnDisconnected != nil && ctx.Err() == nil {
+				a.cfg.OnDisconnected(err)
+			}
+			return fmt.Errorf("connection closed: %w", err)
+		}
+		a.log.Infof("received response for %s: %v", msg.TypeUrl, msg.Resources)
+		if a.State() != StateReady {
+			a.setState(StateReady)
+			onReady()
+			if a.cfg.OnConnected != nil {
+				a.cfg.OnConnected(a.ActiveEndpoint())
+			}
+		}
+		if handler, found := a.cfg.Handlers[msg.TypeUrl]; found {
+			if err := handler.Handle(a.cfg.RemoteName, msg.Resources); err != nil {
+				a.log.Infof("error handling resource %s: %v", msg.TypeUrl, err)
+			}
+		} else {
+			a.log.Infof("no handler found for type: %s", msg.TypeUrl)
+		}
 	}
 }
 
-func (a *ADSC) Send(req *discovery.DiscoveryRequest) error {
-	req.ResponseNonce = time.Now().String()
-	a.log.Infof("Sending Discovery Request to ADS server: %s", req.String())
-	return a.stream.Send(req)
+// backoffDelay returns the delay before the reconnect following the given number of consecutive failed attempts.
+func (a *ADSC) backoffDelay(retries int) time.Duration {
+	delay := float64(a.backoff.BaseDelay)
+	for i := 0; i < retries && delay < float64(a.backoff.MaxDelay); i++ {
+		delay *= a.backoff.Multiplier
+	}
+	delay = min(delay, float64(a.backoff.MaxDelay))
+	delay *= 1 + a.backoff.Jitter*(rand.Float64()*2-1)
+	return time.Duration(max(delay, 0))
 }
 
+// dial creates a client connection with the pick_first policy over all discovery addresses,
+// which connects to the first reachable address and tries all of them again when the connection breaks.
+// The connection is established lazily by the first stream.
 func (a *ADSC) dial() error {
-	backoffConfig := backoff.DefaultConfig
-	backoffConfig.MaxDelay = a.cfg.ReconnectDelay
+	addrs := make([]resolver.Address, 0, len(a.cfg.DiscoveryAddrs))
+	for _, addr := range a.cfg.DiscoveryAddrs {
+		addrs = append(addrs, resolver.Address{Addr: addr})
+	}
+	endpoints := manual.NewBuilderWithScheme(endpointsScheme)
+	endpoints.InitialState(resolver.State{Addresses: addrs})
 
 	var err error
 	a.conn, err = grpc.NewClient(
-		a.cfg.DiscoveryAddr,
+		endpointsScheme+":///"+a.cfg.RemoteName,
+		grpc.WithResolvers(endpoints),
+		grpc.WithDefaultServiceConfig(pickFirstServiceConfig),
+		grpc.WithContextDialer(a.dialEndpoint),
 		grpc.WithAuthority(a.cfg.Authority),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithInitialWindowSize(int32(defaultInitialWindowSize)),
 		grpc.WithInitialConnWindowSize(int32(defaultInitialConnWindowSize)),
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(defaultClientMaxReceiveMessageSize)),
 		grpc.WithConnectParams(grpc.ConnectParams{
-			Backoff:           backoff.DefaultConfig,
-			MinConnectTimeout: a.cfg.ReconnectDelay,
+			Backoff:           a.backoff,
+			MinConnectTimeout: defaultMinConnectTimeout,
 		}),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to establish connection to the ADS server %s: %w", a.cfg.DiscoveryAddr, err)
+		return fmt.Errorf("failed to establish connection to the ADS server %v: %w", a.cfg.DiscoveryAddrs, err)
 	}
 	return nil
 }
 
-func (a *ADSC) handleRecv(ctx context.Context) {
-
-loop:
-	for {
-		select {
-		case <-ctx.Done():
-			break loop
-		default:
-
-			var err error
-			msg, err := a.stream.Recv()
-			if err != nil {
-				a.log.Errorf("connection closed with err: %v", err)
-				time.AfterFunc(a.cfg.ReconnectDelay, func() {
-					a.Restart(ctx)
-				})
-				return
-			}
-			a.log.Infof("received response for %s: %v", msg.TypeUrl, msg.Resources)
-			if handler, found := a.cfg.Handlers[msg.TypeUrl]; found {
-				if err := handler.Handle(a.cfg.RemoteName, msg.Resources); err != nil {
-					a.log.Infof("error handling resource %s: %v", msg.TypeUrl, err)
-				}
-			} else {
-				a.log.Infof("no handler found for type: %s", msg.TypeUrl)
-			}
-		}
+// dialEndpoint connects to an endpoint chosen by the load balancing policy and records it as the active endpoint.
+func (a *ADSC) dialEndpoint(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := a.dialContext(ctx, "tcp", addr)
+	if err != nil {
+		a.log.Warnf("failed to connect to ADS server endpoint %s: %v", addr, err)
+		return nil, err
+	}
+	if previous := a.ActiveEndpoint(); previous != addr {
+		a.log.Infof("connected to ADS server endpoint %s", addr)
 	}
+	a.activeEndpoint.Store(addr)
+	return conn, nil
 }
@@ -0,0 +1,300 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adsc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	testTypeUrl = "federation.openshift-service-mesh.io/v1alpha1/Test"
+	timeout     = 5 * time.Second
+)
+
+var testBackoff = backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 2, Jitter: 0.2, MaxDelay: 50 * time.Millisecond}
+
+func TestFailover(t *testing.T) {
+	servers := newFakeADSServers(t)
+	servers.start("west-2:15080")
+
+	client, handler, connected, _ := newTestClient(t, servers, "west-1:15080", "west-2:15080")
+	stop := runClient(t, client)
+	defer stop()
+
+	handler.waitForResponse(t)
+	waitForState(t, client, StateReady)
+	select {
+	case endpoint := <-connected:
+		if endpoint != "west-2:15080" {
+			t.Errorf("expected to connect to west-2:15080, got %s", endpoint)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("timed out waiting for the connection")
+	}
+	if active := client.ActiveEndpoint(); active != "west-2:15080" {
+		t.Errorf("expected active endpoint west-2:15080, got %s", active)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	servers := newFakeADSServers(t)
+	servers.start("west:15080")
+
+	client, handler, connected, disconnected := newTestClient(t, servers, "west:15080")
+	if state := client.State(); state != StateIdle {
+		t.Errorf("expected a new client to be %s, got %s", StateIdle, state)
+	}
+	stop := runClient(t, client)
+	defer stop()
+
+	handler.waitForResponse(t)
+	waitForState(t, client, StateReady)
+	<-connected
+
+	servers.stop("west:15080")
+	select {
+	case <-disconnected:
+	case <-time.After(timeout):
+		t.Fatalf("timed out waiting for the disconnection")
+	}
+	waitForState(t, client, StateBackoff)
+
+	servers.start("west:15080")
+	handler.waitForResponse(t)
+	waitForState(t, client, StateReady)
+	select {
+	case <-connected:
+	case <-time.After(timeout):
+		t.Fatalf("timed out waiting for the reconnection")
+	}
+}
+
+func TestRunStopsWhenContextIsCancelled(t *testing.T) {
+	servers := newFakeADSServers(t)
+	client, _, _, _ := newTestClient(t, servers, "unreachable:15080")
+
+	stop := runClient(t, client)
+	waitForState(t, client, StateBackoff)
+	stop()
+
+	if state := client.State(); state != StateIdle {
+		t.Errorf("expected a stopped client to be %s, got %s", StateIdle, state)
+	}
+	// goroutines of the client and its connection exit asynchronously after the connection is closed
+	deadline := time.Now().Add(timeout)
+	for leaked := clientGoroutines(); leaked != ""; leaked = clientGoroutines() {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines of the client are still running:\n%s", leaked)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	client := &ADSC{backoff: testBackoff}
+	testCases := []struct {
+		retries  int
+		expected time.Duration
+	}{
+		{retries: 0, expected: 10 * time.Millisecond},
+		{retries: 1, expected: 20 * time.Millisecond},
+		{retries: 2, expected: 40 * time.Millisecond},
+		{retries: 3, expected: 50 * time.Millisecond},
+		{retries: 100, expected: 50 * time.Millisecond},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("retries=%d", tc.retries), func(t *testing.T) {
+			minDelay := time.Duration(float64(tc.expected) * (1 - testBackoff.Jitter))
+			maxDelay := time.Duration(float64(tc.expected) * (1 + testBackoff.Jitter))
+			for range 100 {
+				if delay := client.backoffDelay(tc.retries); delay < minDelay || delay > maxDelay {
+					t.Fatalf("expected delay in [%s, %s], got %s", minDelay, maxDelay, delay)
+				}
+			}
+		})
+	}
+}
+
+func newTestClient(t *testing.T, servers *fakeADSServers, addrs ...string) (*ADSC, *fakeHandler, chan string, chan error) {
+	t.Helper()
+	handler := &fakeHandler{responses: make(chan string, 100)}
+	connected := make(chan string, 10)
+	disconnected := make(chan error, 10)
+	client, err := New(&ADSCConfig{
+		RemoteName:     "west",
+		DiscoveryAddrs: addrs,
+		Authority:      "federation-discovery-service-west.istio-system.svc.cluster.local",
+		Handlers:       map[string]ResponseHandler{testTypeUrl: handler},
+		Backoff:        &testBackoff,
+		OnConnected: func(endpoint string) {
+			connected <- endpoint
+		},
+		OnDisconnected: func(err error) {
+			disconnected <- err
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.dialContext = servers.dial
+	return client, handler, connected, disconnected
+}
+
+// runClient runs the client in the background and returns a function stopping it and waiting until Run returns.
+func runClient(t *testing.T, client *ADSC) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(timeout):
+			t.Fatalf("timed out waiting for the client to stop")
+		}
+	}
+}
+
+func waitForState(t *testing.T, client *ADSC, expected State) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for client.State() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for state %s, got %s", expected, client.State())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// clientGoroutines returns stacks of goroutines running code of the client or its gRPC connection.
+func clientGoroutines() string {
+	buf := make([]byte, 1<<20)
+	stacks := strings.Split(string(buf[:runtime.Stack(buf, true)]), "\n\n")
+	var leaked []string
+	for _, stack := range stacks {
+		if strings.Contains(stack, "adsc.(*ADSC)") || strings.Contains(stack, "grpc.(*ClientConn)") ||
+			strings.Contains(stack, "grpc.(*addrConn)") {
+			leaked = append(leaked, stack)
+		}
+	}
+	return strings.Join(leaked, "\n\n")
+}
+
+// fakeADSServers serves fake ADS servers over in-memory connections at arbitrary addresses.
+type fakeADSServers struct {
+	mu      sync.Mutex
+	servers map[string]*grpc.Server
+	lis     map[string]*bufconn.Listener
+}
+
+func newFakeADSServers(t *testing.T) *fakeADSServers {
+	servers := &fakeADSServers{
+		servers: make(map[string]*grpc.Server),
+		lis:     make(map[string]*bufconn.Listener),
+	}
+	t.Cleanup(func() {
+		servers.mu.Lock()
+		defer servers.mu.Unlock()
+		for _, server := range servers.servers {
+			server.Stop()
+		}
+	})
+	return servers
+}
+
+func (s *fakeADSServers) start(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	discovery.RegisterAggregatedDiscoveryServiceServer(server, &fakeADSServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	s.servers[addr] = server
+	s.lis[addr] = listener
+}
+
+func (s *fakeADSServers) stop(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servers[addr].Stop()
+	delete(s.servers, addr)
+	delete(s.lis, addr)
+}
+
+func (s *fakeADSServers) dial(ctx context.Context, _, addr string) (net.Conn, error) {
+	s.mu.Lock()
+	listener, found := s.lis[addr]
+	s.mu.Unlock()
+	if !found {
+		return nil, fmt.Errorf("dial %s: connection refused", addr)
+	}
+	return listener.DialContext(ctx)
+}
+
+// fakeADSServer responds to every discovery request with an empty response of the requested type.
+type fakeADSServer struct {
+	discovery.UnimplementedAggregatedDiscoveryServiceServer
+}
+
+func (s *fakeADSServer) StreamAggregatedResources(stream discovery.AggregatedDiscoveryService_StreamAggregatedResourcesServer) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&discovery.DiscoveryResponse{TypeUrl: req.TypeUrl, Resources: []*anypb.Any{}}); err != nil {
+			return err
+		}
+	}
+}
+
+type fakeHandler struct {
+	responses chan string
+}
+
+func (h *fakeHandler) Handle(source string, _ []*anypb.Any) error {
+	h.responses <- source
+	return nil
+}
+
+func (h *fakeHandler) waitForResponse(t *testing.T) {
+	t.Helper()
+	select {
+	case source := <-h.responses:
+		if source != "west" {
+			t.Errorf("expected response from west, got %s", source)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("timed out waiting for a response")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adsc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// State of the connection to the ADS server.
+type State int
+
+const (
+	// StateIdle is the state of a client that is not running.
+	StateIdle State = iota
+	// StateConnecting is the state of a client opening a stream to the ADS server.
+	StateConnecting
+	// StateSyncing is the state of a client that opened a stream and waits for the first response.
+	StateSyncing
+	// StateReady is the state of a client that received a response on the current stream.
+	StateReady
+	// StateBackoff is the state of a client waiting to reconnect after the stream failed.
+	StateBackoff
+)
+
+var states = []State{StateIdle, StateConnecting, StateSyncing, StateReady, StateBackoff}
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "Idle"
+	case StateConnecting:
+		return "Connecting"
+	case StateSyncing:
+		return "Syncing"
+	case StateReady:
+		return "Ready"
+	case StateBackoff:
+		return "Backoff"
+	default:
+		return "Unknown"
+	}
+}
+
+var connectionState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "federation_discovery_client_state",
+		Help: "State of the connection to the discovery server of a remote peer, set to 1 for the current state.",
+	},
+	[]string{"remote", "state"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(connectionState)
+}
+
+func reportState(remote string, current State) {
+	for _, state := range states {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+		connectionState.WithLabelValues(remote, state.String()).Set(value)
+	}
+}
@@ -29,9 +29,11 @@ type Server struct {
 	grpc         *grpc.Server
 	ads          *adsServer
 	pushRequests <-chan xds.PushRequest
+	addr         string
 }
 
-func NewServer(pushRequests <-chan xds.PushRequest, handlers ...RequestHandler) *Server {
+// NewServer creates a discovery server listening on the given address, e.g. ":15080".
+func NewServer(addr string, pushRequests <-chan xds.PushRequest, handlers ...RequestHandler) *Server {
 	grpcServer := grpc.NewServer()
 	handlerMap := make(map[string]RequestHandler)
 	for _, g := range handlers {
@@ -47,12 +49,13 @@ func NewServer(pushRequests <-chan xds.PushRequest, handlers ...RequestHandler)
 		grpc:         grpcServer,
 		ads:          ads,
 		pushRequests: pushRequests,
+		addr:         addr,
 	}
 }
 
 // Run starts the gRPC server and awaits for push requests to broadcast configuration.
 func (s *Server) Run(ctx context.Context) error {
-	listener, err := net.Listen("tcp", ":15080")
+	listener, err := net.Listen("tcp", s.addr)
 	if err != nil {
 		return fmt.Errorf("creating TCP listener: %w", err)
 	}
@@ -15,12 +15,20 @@
 package xds
 
 const (
-	ExportedServiceTypeUrl    = "federation.openshift-service-mesh.io/v1alpha1/ExportedService"
-	DestinationRuleTypeUrl    = "networking.istio.io/v1alpha3/DestinationRule"
-	GatewayTypeUrl            = "networking.istio.io/v1alpha3/Gateway"
-	ServiceEntryTypeUrl       = "networking.istio.io/v1alpha3/ServiceEntry"
-	WorkloadEntryTypeUrl      = "networking.istio.io/v1alpha3/WorkloadEntry"
-	EnvoyFilterTypeUrl        = "networking.istio.io/v1alpha3/EnvoyFilter"
-	PeerAuthenticationTypeUrl = "security.istio.io/v1beta1/PeerAuthentication"
-	RouteTypeUrl              = "route.openshift.io/v1/Route"
+	ExportedServiceTypeUrl     = "federation.openshift-service-mesh.io/v1alpha1/ExportedService"
+	TrustBundleTypeUrl         = "federation.openshift-service-mesh.io/v1alpha1/TrustBundle"
+	DestinationRuleTypeUrl     = "networking.istio.io/v1alpha3/DestinationRule"
+	GatewayTypeUrl             = "networking.istio.io/v1alpha3/Gateway"
+	ServiceEntryTypeUrl        = "networking.istio.io/v1alpha3/ServiceEntry"
+	WorkloadEntryTypeUrl       = "networking.istio.io/v1alpha3/WorkloadEntry"
+	EnvoyFilterTypeUrl         = "networking.istio.io/v1alpha3/EnvoyFilter"
+	PeerAuthenticationTypeUrl  = "security.istio.io/v1beta1/PeerAuthentication"
+	AuthorizationPolicyTypeUrl = "security.istio.io/v1beta1/AuthorizationPolicy"
+	RouteTypeUrl               = "route.openshift.io/v1/Route"
+	ServiceExportTypeUrl       = "multicluster.x-k8s.io/v1alpha1/ServiceExport"
+	ServiceImportTypeUrl       = "multicluster.x-k8s.io/v1alpha1/ServiceImport"
+	KubernetesGatewayTypeUrl   = "gateway.networking.k8s.io/v1/Gateway"
+	TLSRouteTypeUrl            = "gateway.networking.k8s.io/v1alpha2/TLSRoute"
+	ClusterTrustBundleTypeUrl  = "certificates.k8s.io/v1alpha1/ClusterTrustBundle"
+	ServiceTypeUrl             = "v1/Service"
 )
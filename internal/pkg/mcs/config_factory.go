@@ -0,0 +1,241 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	istiolog "istio.io/istio/pkg/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	v1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/ptr"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	mcslisters "sigs.k8s.io/mcs-api/pkg/client/listers/apis/v1alpha1"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/fds"
+	"github.com/openshift-service-mesh/federation/internal/pkg/networking"
+)
+
+const (
+	ReasonServiceExported        = "ServiceExported"
+	ReasonServiceNotFound        = "ServiceNotFound"
+	ReasonUnsupportedServiceType = "UnsupportedServiceType"
+	ReasonNoConflicts            = "NoConflicts"
+	ReasonPortConflict           = "PortConflict"
+	ReasonTrustDomainsMatch      = "TrustDomainsMatch"
+	ReasonTrustDomainMismatch    = "TrustDomainMismatch"
+
+	// ServiceExportTrustDomainVerified reports whether trust domains advertised by remote peers match the configuration,
+	// which is required to authorize requests from remote peers and to verify identities of imported services.
+	ServiceExportTrustDomainVerified mcsv1alpha1.ServiceExportConditionType = "TrustDomainVerified"
+)
+
+var log = istiolog.RegisterScope("mcs-cfg-factory", "Multi-Cluster Services Config Factory")
+
+// ConfigFactory generates Kubernetes Multi-Cluster Services API (multicluster.x-k8s.io) resources.
+type ConfigFactory struct {
+	cfg                  config.Federation
+	serviceLister        v1.ServiceLister
+	serviceExportLister  mcslisters.ServiceExportLister
+	importedServiceStore *fds.ImportedServiceStore
+	trustBundleStore     *fds.TrustBundleStore
+	addressCache         *networking.AddressCache
+}
+
+func NewConfigFactory(
+	cfg config.Federation,
+	serviceLister v1.ServiceLister,
+	serviceExportLister mcslisters.ServiceExportLister,
+	importedServiceStore *fds.ImportedServiceStore,
+	trustBundleStore *fds.TrustBundleStore,
+	addressCache *networking.AddressCache,
+) *ConfigFactory {
+	return &ConfigFactory{
+		cfg:                  cfg,
+		serviceLister:        serviceLister,
+		serviceExportLister:  serviceExportLister,
+		importedServiceStore: importedServiceStore,
+		trustBundleStore:     trustBundleStore,
+		addressCache:         addressCache,
+	}
+}
+
+// ServiceImports returns a ServiceImport for each imported service. IPs of a ServiceImport are the ingress addresses
+// of all remotes exporting the service. They are informational only, because remote ingress gateways accept only
+// mTLS connections with SNI passthrough on their TLS port, so clients must still reach the service through the mesh.
+// Ports are taken from the first remote exporting the service, and remotes exporting it with different ports
+// are left out, like conflicting exports in the MCS API.
+func (cf *ConfigFactory) ServiceImports() []*mcsv1alpha1.ServiceImport {
+	serviceImportsByName := make(map[types.NamespacedName]*mcsv1alpha1.ServiceImport)
+	firstImportedByName := make(map[types.NamespacedName]*v1alpha1.FederatedService)
+	for _, remote := range cf.cfg.MeshPeers.Remotes {
+		ips := cf.addressCache.Resolve(remote.Addresses...)
+		for _, importedSvc := range cf.importedServiceStore.From(remote) {
+			svcName, svcNs := getServiceNameAndNs(importedSvc.GetHostname())
+			key := types.NamespacedName{Namespace: svcNs, Name: svcName}
+			if first, exists := firstImportedByName[key]; exists && common.ImportedPortsConflict(first, importedSvc) {
+				log.Warnf("Ignoring %s exported by %s, because its ports differ from the service exported by other remotes",
+					importedSvc.GetHostname(), remote.Name)
+				continue
+			}
+			serviceImport, exists := serviceImportsByName[key]
+			if !exists {
+				firstImportedByName[key] = importedSvc
+				serviceImport = &mcsv1alpha1.ServiceImport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      svcName,
+						Namespace: svcNs,
+						Labels:    cf.cfg.PeerLabels(),
+					},
+					Spec: mcsv1alpha1.ServiceImportSpec{
+						Type:  mcsv1alpha1.ClusterSetIP,
+						Ports: toServiceImportPorts(importedSvc.Ports),
+					},
+				}
+				serviceImportsByName[key] = serviceImport
+			}
+			if len(importedSvc.GetInstanceHostnames()) > 0 {
+				// Headless services do not have a cluster set IP and are addressed by instance hostnames.
+				serviceImport.Spec.Type = mcsv1alpha1.Headless
+			} else {
+				serviceImport.Spec.IPs = append(serviceImport.Spec.IPs, ips...)
+			}
+			serviceImport.Status.Clusters = append(serviceImport.Status.Clusters, mcsv1alpha1.ClusterStatus{Cluster: remote.Name})
+		}
+	}
+
+	serviceImports := make([]*mcsv1alpha1.ServiceImport, 0, len(serviceImportsByName))
+	for _, serviceImport := range serviceImportsByName {
+		if serviceImport.Spec.Type == mcsv1alpha1.Headless {
+			serviceImport.Spec.IPs = nil
+		}
+		sort.Strings(serviceImport.Spec.IPs)
+		serviceImports = append(serviceImports, serviceImport)
+	}
+	return serviceImports
+}
+
+// ServiceExportConditions returns conditions of all ServiceExports in the local cluster:
+//   - Valid is false if the exported service does not exist or its type is not supported;
+//   - Conflict is true if any remote exports a service with the same name and namespace, but different ports;
+//   - TrustDomainVerified is false if any remote advertises a trust domain different from the configured one.
+func (cf *ConfigFactory) ServiceExportConditions() (map[types.NamespacedName][]mcsv1alpha1.ServiceExportCondition, error) {
+	serviceExports, err := cf.serviceExportLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service exports: %w", err)
+	}
+	conditions := make(map[types.NamespacedName][]mcsv1alpha1.ServiceExportCondition, len(serviceExports))
+	trustDomainCondition := cf.trustDomainCondition()
+	for _, se := range serviceExports {
+		key := types.NamespacedName{Namespace: se.Namespace, Name: se.Name}
+		svc, err := cf.serviceLister.Services(se.Namespace).Get(se.Name)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get Service %s/%s: %w", se.Namespace, se.Name, err)
+			}
+			conditions[key] = []mcsv1alpha1.ServiceExportCondition{
+				condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionFalse, ReasonServiceNotFound,
+					fmt.Sprintf("service %s/%s not found", se.Namespace, se.Name)),
+			}
+			continue
+		}
+		if err := common.CheckExportable(svc); err != nil {
+			conditions[key] = []mcsv1alpha1.ServiceExportCondition{
+				condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionFalse, ReasonUnsupportedServiceType, err.Error()),
+			}
+			continue
+		}
+		conditions[key] = []mcsv1alpha1.ServiceExportCondition{
+			condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionTrue, ReasonServiceExported, "service is exported to federated meshes"),
+			cf.conflictCondition(svc),
+			trustDomainCondition,
+		}
+	}
+	return conditions, nil
+}
+
+func (cf *ConfigFactory) conflictCondition(svc *corev1.Service) mcsv1alpha1.ServiceExportCondition {
+	hostname := fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace)
+	var conflictingRemotes []string
+	for _, remote := range cf.cfg.MeshPeers.Remotes {
+		for _, importedSvc := range cf.importedServiceStore.From(remote) {
+			if importedSvc.GetHostname() == hostname && common.PortsConflict(svc, importedSvc) {
+				conflictingRemotes = append(conflictingRemotes, remote.Name)
+			}
+		}
+	}
+	if len(conflictingRemotes) > 0 {
+		return condition(mcsv1alpha1.ServiceExportConflict, corev1.ConditionTrue, ReasonPortConflict,
+			fmt.Sprintf("ports differ from the service exported by remotes: %s", strings.Join(conflictingRemotes, ", ")))
+	}
+	return condition(mcsv1alpha1.ServiceExportConflict, corev1.ConditionFalse, ReasonNoConflicts, "no conflicts with remote services")
+}
+
+func (cf *ConfigFactory) trustDomainCondition() mcsv1alpha1.ServiceExportCondition {
+	var mismatches []string
+	for _, remote := range cf.cfg.MeshPeers.Remotes {
+		// Remotes running older controllers do not advertise trust domains
+		trustBundle := cf.trustBundleStore.From(remote)
+		if trustBundle == nil || trustBundle.GetTrustDomain() == "" {
+			continue
+		}
+		if trustBundle.GetTrustDomain() != remote.GetTrustDomain() {
+			mismatches = append(mismatches, fmt.Sprintf("%s (advertised %s, configured %s)",
+				remote.Name, trustBundle.GetTrustDomain(), remote.GetTrustDomain()))
+		}
+	}
+	if len(mismatches) > 0 {
+		return condition(ServiceExportTrustDomainVerified, corev1.ConditionFalse, ReasonTrustDomainMismatch,
+			fmt.Sprintf("trust domains differ from the configuration of remotes: %s", strings.Join(mismatches, ", ")))
+	}
+	return condition(ServiceExportTrustDomainVerified, corev1.ConditionTrue, ReasonTrustDomainsMatch,
+		"trust domains advertised by remotes match the configuration")
+}
+
+func condition(conditionType mcsv1alpha1.ServiceExportConditionType, status corev1.ConditionStatus, reason, message string) mcsv1alpha1.ServiceExportCondition {
+	return mcsv1alpha1.ServiceExportCondition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  ptr.To(reason),
+		Message: ptr.To(message),
+	}
+}
+
+func toServiceImportPorts(ports []*v1alpha1.ServicePort) []mcsv1alpha1.ServicePort {
+	out := make([]mcsv1alpha1.ServicePort, 0, len(ports))
+	for _, port := range ports {
+		out = append(out, mcsv1alpha1.ServicePort{
+			Name:        port.Name,
+			Protocol:    corev1.ProtocolTCP,
+			AppProtocol: ptr.To(strings.ToLower(port.Protocol)),
+			Port:        int32(port.Number),
+		})
+	}
+	return out
+}
+
+func getServiceNameAndNs(hostname string) (string, string) {
+	domainLabels := strings.Split(hostname, ".")
+	return domainLabels[0], domainLabels[1]
+}
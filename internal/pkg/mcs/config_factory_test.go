@@ -0,0 +1,235 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcs
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+	mcsv1alpha1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+	mcsfake "sigs.k8s.io/mcs-api/pkg/client/clientset/versioned/fake"
+	mcsinformers "sigs.k8s.io/mcs-api/pkg/client/informers/externalversions"
+
+	"github.com/openshift-service-mesh/federation/internal/api/federation/v1alpha1"
+	"github.com/openshift-service-mesh/federation/internal/pkg/config"
+	"github.com/openshift-service-mesh/federation/internal/pkg/legacy/fds"
+)
+
+var (
+	federationConfig = config.Federation{
+		MeshPeers: config.MeshPeers{
+			Local: config.Local{Name: "east"},
+			Remotes: []config.Remote{{
+				Name:      "west",
+				Addresses: []string{"1.1.1.1"},
+			}, {
+				Name:      "central",
+				Addresses: []string{"2.2.2.2", "3.3.3.3"},
+			}},
+		},
+		ExportedServiceSet: config.ExportedServiceSet{
+			Rules: []config.Rules{{Type: config.ServiceExportRuleType}},
+		},
+		ImportedServiceSet: config.ImportedServiceSet{
+			Rules: []config.Rules{{Type: config.ServiceImportRuleType}},
+		},
+	}
+
+	httpPort = &v1alpha1.ServicePort{
+		Name:       "http",
+		Number:     80,
+		TargetPort: 8080,
+		Protocol:   "HTTP",
+	}
+	importedSvcA = &v1alpha1.FederatedService{
+		Hostname: "a.ns1.svc.cluster.local",
+		Ports:    []*v1alpha1.ServicePort{httpPort},
+	}
+	importedKafka = &v1alpha1.FederatedService{
+		Hostname:          "kafka.ns1.svc.cluster.local",
+		Ports:             []*v1alpha1.ServicePort{{Name: "tcp", Number: 9092, Protocol: "TCP"}},
+		InstanceHostnames: []string{"kafka-0.kafka.ns1.svc.cluster.local"},
+	}
+)
+
+func TestServiceImports(t *testing.T) {
+	importedServiceStore := fds.NewImportedServiceStore()
+	importedServiceStore.Update("west", []*v1alpha1.FederatedService{importedSvcA, importedKafka})
+	// Central exports kafka with a different port, so it is left out of the kafka ServiceImport
+	importedServiceStore.Update("central", []*v1alpha1.FederatedService{importedSvcA, {
+		Hostname:          "kafka.ns1.svc.cluster.local",
+		Ports:             []*v1alpha1.ServicePort{{Name: "tcp", Number: 9093, Protocol: "TCP"}},
+		InstanceHostnames: []string{"kafka-0.kafka.ns1.svc.cluster.local"},
+	}})
+
+	cf := NewConfigFactory(federationConfig, nil, nil, importedServiceStore, fds.NewTrustBundleStore(), nil)
+	actual := cf.ServiceImports()
+
+	expected := []*mcsv1alpha1.ServiceImport{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "a",
+			Namespace: "ns1",
+			Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
+		},
+		Spec: mcsv1alpha1.ServiceImportSpec{
+			Type:  mcsv1alpha1.ClusterSetIP,
+			IPs:   []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+			Ports: []mcsv1alpha1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, AppProtocol: ptr.To("http"), Port: 80}},
+		},
+		Status: mcsv1alpha1.ServiceImportStatus{
+			Clusters: []mcsv1alpha1.ClusterStatus{{Cluster: "west"}, {Cluster: "central"}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kafka",
+			Namespace: "ns1",
+			Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "east"},
+		},
+		Spec: mcsv1alpha1.ServiceImportSpec{
+			Type:  mcsv1alpha1.Headless,
+			Ports: []mcsv1alpha1.ServicePort{{Name: "tcp", Protocol: corev1.ProtocolTCP, AppProtocol: ptr.To("tcp"), Port: 9092}},
+		},
+		Status: mcsv1alpha1.ServiceImportStatus{
+			Clusters: []mcsv1alpha1.ClusterStatus{{Cluster: "west"}},
+		},
+	}}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d service imports, got %d: %s", len(expected), len(actual), toJSON(actual))
+	}
+	for _, exp := range expected {
+		found := false
+		for _, act := range actual {
+			if reflect.DeepEqual(exp, act) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected service import not found:\n%s\ngot:\n%s", toJSON(exp), toJSON(actual))
+		}
+	}
+}
+
+func TestServiceExportConditions(t *testing.T) {
+	localServices := []*corev1.Service{{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns1"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns1"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "grpc", Port: 8080}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "ns1"},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "example.com",
+		},
+	}}
+	serviceExports := []*mcsv1alpha1.ServiceExport{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "ns1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "ns1"}},
+	}
+
+	client := fake.NewSimpleClientset()
+	for _, svc := range localServices {
+		if _, err := client.CoreV1().Services(svc.Namespace).Create(context.Background(), svc, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create service %s/%s: %v", svc.Namespace, svc.Name, err)
+		}
+	}
+	mcsClient := mcsfake.NewSimpleClientset()
+	for _, se := range serviceExports {
+		if _, err := mcsClient.MulticlusterV1alpha1().ServiceExports(se.Namespace).Create(context.Background(), se, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create service export %s/%s: %v", se.Namespace, se.Name, err)
+		}
+	}
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	serviceInformer := informerFactory.Core().V1().Services().Informer()
+	serviceLister := informerFactory.Core().V1().Services().Lister()
+	mcsInformerFactory := mcsinformers.NewSharedInformerFactory(mcsClient, 0)
+	serviceExportInformer := mcsInformerFactory.Multicluster().V1alpha1().ServiceExports().Informer()
+	serviceExportLister := mcsInformerFactory.Multicluster().V1alpha1().ServiceExports().Lister()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	mcsInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, serviceInformer.HasSynced, serviceExportInformer.HasSynced) {
+		t.Fatal("failed to sync informers")
+	}
+
+	importedServiceStore := fds.NewImportedServiceStore()
+	// Service a is exported by west with the same ports, but b is exported with a different port
+	importedServiceStore.Update("west", []*v1alpha1.FederatedService{
+		importedSvcA,
+		{Hostname: "b.ns1.svc.cluster.local", Ports: []*v1alpha1.ServicePort{{Name: "grpc", Number: 9090, Protocol: "GRPC"}}},
+	})
+
+	trustBundleStore := fds.NewTrustBundleStore()
+	// West advertises a trust domain different from the default one, and central runs a controller without trust bundles
+	trustBundleStore.Update("west", &v1alpha1.TrustBundle{TrustDomain: "west.local"})
+
+	cf := NewConfigFactory(federationConfig, serviceLister, serviceExportLister, importedServiceStore, trustBundleStore, nil)
+	actual, err := cf.ServiceExportConditions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	trustDomainMismatch := condition(ServiceExportTrustDomainVerified, corev1.ConditionFalse, ReasonTrustDomainMismatch,
+		"trust domains differ from the configuration of remotes: west (advertised west.local, configured cluster.local)")
+	expected := map[types.NamespacedName][]mcsv1alpha1.ServiceExportCondition{
+		{Namespace: "ns1", Name: "a"}: {
+			condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionTrue, ReasonServiceExported, "service is exported to federated meshes"),
+			condition(mcsv1alpha1.ServiceExportConflict, corev1.ConditionFalse, ReasonNoConflicts, "no conflicts with remote services"),
+			trustDomainMismatch,
+		},
+		{Namespace: "ns1", Name: "b"}: {
+			condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionTrue, ReasonServiceExported, "service is exported to federated meshes"),
+			condition(mcsv1alpha1.ServiceExportConflict, corev1.ConditionTrue, ReasonPortConflict, "ports differ from the service exported by remotes: west"),
+			trustDomainMismatch,
+		},
+		{Namespace: "ns1", Name: "external"}: {
+			condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionFalse, ReasonUnsupportedServiceType,
+				"service ns1/external is of type ExternalName, which is not supported for export"),
+		},
+		{Namespace: "ns1", Name: "missing"}: {
+			condition(mcsv1alpha1.ServiceExportValid, corev1.ConditionFalse, ReasonServiceNotFound, "service ns1/missing not found"),
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got unexpected conditions:\nexpected:\n%v\ngot:\n%v", expected, actual)
+	}
+}
+
+func toJSON(input any) string {
+	str, err := json.Marshal(input)
+	if err != nil {
+		panic(err)
+	}
+	return string(str)
+}
@@ -0,0 +1,241 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	istiolog "istio.io/istio/pkg/log"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// Very short TTLs are not followed to avoid flooding nameservers, and very long TTLs are capped to notice
+	// address changes of remote gateways in reasonable time.
+	defaultMinRefreshInterval = 5 * time.Second
+	defaultMaxRefreshInterval = 5 * time.Minute
+
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 2 * time.Minute
+	backoffJitter         = 0.2
+
+	resolutionSuccess = "success"
+	resolutionFailure = "failure"
+)
+
+var log = istiolog.RegisterScope("networking", "Remote address resolution")
+
+var (
+	resolutionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "federation_remote_address_resolutions_total",
+			Help: "Number of DNS resolutions of remote addresses by result.",
+		},
+		[]string{"host", "result"},
+	)
+	resolvedIPs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_remote_address_resolved_ips",
+			Help: "Number of IP addresses currently cached for a remote address.",
+		},
+		[]string{"host"},
+	)
+	resolutionFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_remote_address_resolution_failures",
+			Help: "Number of consecutive failed resolutions of a remote address.",
+		},
+		[]string{"host"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(resolutionsTotal, resolvedIPs, resolutionFailures)
+}
+
+type cacheEntry struct {
+	ips         []string
+	failures    int
+	nextRefresh time.Time
+}
+
+// AddressCache resolves hostnames of remote addresses in the background and serves the last resolved IP addresses.
+// Hostnames are resolved again when the TTL of their records expires, or with exponential backoff after a failure.
+// Previously resolved addresses are kept until a resolution succeeds again.
+type AddressCache struct {
+	resolver Resolver
+	onChange func()
+
+	minRefreshInterval time.Duration
+	maxRefreshInterval time.Duration
+	initialBackoff     time.Duration
+	maxBackoff         time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]*cacheEntry
+}
+
+// NewAddressCache creates a cache of the given addresses. IP addresses are skipped, as they do not need resolving.
+// onChange is called from the background refresh whenever resolved addresses of any hostname have changed.
+func NewAddressCache(resolver Resolver, addrs []string, onChange func()) *AddressCache {
+	entries := make(map[string]*cacheEntry)
+	for _, addr := range addrs {
+		if !IsIP(addr) {
+			entries[addr] = &cacheEntry{}
+		}
+	}
+	return &AddressCache{
+		resolver:           resolver,
+		onChange:           onChange,
+		minRefreshInterval: defaultMinRefreshInterval,
+		maxRefreshInterval: defaultMaxRefreshInterval,
+		initialBackoff:     defaultInitialBackoff,
+		maxBackoff:         defaultMaxBackoff,
+		entries:            entries,
+	}
+}
+
+// Start resolves all hostnames once and then keeps refreshing them in the background until the context is cancelled.
+// onChange is not called for the initial resolution, as consumers read the cache only after Start returns.
+func (c *AddressCache) Start(ctx context.Context) {
+	if len(c.entries) == 0 {
+		return
+	}
+	c.refresh(ctx)
+	go c.run(ctx)
+}
+
+// Resolve returns the given addresses with hostnames replaced by their cached IP addresses. IP addresses are returned
+// as is, and hostnames that have not been resolved yet are skipped. A nil cache does not resolve any hostname.
+func (c *AddressCache) Resolve(addrs ...string) []string {
+	var ips []string
+	for _, addr := range addrs {
+		if IsIP(addr) {
+			ips = append(ips, addr)
+			continue
+		}
+		if c == nil {
+			continue
+		}
+		c.mu.RLock()
+		if entry, found := c.entries[addr]; found {
+			ips = append(ips, entry.ips...)
+		}
+		c.mu.RUnlock()
+	}
+	return ips
+}
+
+func (c *AddressCache) run(ctx context.Context) {
+	timer := time.NewTimer(c.untilNextRefresh())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if c.refresh(ctx) && c.onChange != nil {
+				c.onChange()
+			}
+			timer.Reset(c.untilNextRefresh())
+		}
+	}
+}
+
+// refresh resolves hostnames that are due and returns true if resolved addresses of any of them have changed.
+func (c *AddressCache) refresh(ctx context.Context) bool {
+	changed := false
+	for _, host := range c.dueHosts(time.Now()) {
+		ips, ttl, err := c.resolver.Lookup(ctx, host)
+		if ctx.Err() != nil {
+			return false
+		}
+		if c.update(host, ips, ttl, err) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// update stores the result of a resolution and returns true if resolved addresses of the host have changed.
+func (c *AddressCache) update(host string, ips []string, ttl time.Duration, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entries[host]
+	if err != nil {
+		entry.failures++
+		delay := c.backoff(entry.failures)
+		entry.nextRefresh = time.Now().Add(delay)
+		log.Warnf("failed to resolve %s (attempt %d), retrying in %s: %v", host, entry.failures, delay.Round(time.Millisecond), err)
+		resolutionsTotal.WithLabelValues(host, resolutionFailure).Inc()
+		resolutionFailures.WithLabelValues(host).Set(float64(entry.failures))
+		return false
+	}
+
+	// Nameservers may rotate records, so addresses are sorted to not report the same addresses as a change
+	sort.Strings(ips)
+	changed := !slices.Equal(entry.ips, ips)
+	if changed {
+		log.Infof("Resolved %s to %v", host, ips)
+	}
+	entry.ips = ips
+	entry.failures = 0
+	entry.nextRefresh = time.Now().Add(min(max(ttl, c.minRefreshInterval), c.maxRefreshInterval))
+	resolutionsTotal.WithLabelValues(host, resolutionSuccess).Inc()
+	resolutionFailures.WithLabelValues(host).Set(0)
+	resolvedIPs.WithLabelValues(host).Set(float64(len(ips)))
+	return changed
+}
+
+// backoff returns the exponentially growing delay after the given number of consecutive failures.
+func (c *AddressCache) backoff(failures int) time.Duration {
+	delay := c.initialBackoff
+	for i := 1; i < failures && delay < c.maxBackoff; i++ {
+		delay *= 2
+	}
+	return wait.Jitter(min(delay, c.maxBackoff), backoffJitter)
+}
+
+func (c *AddressCache) dueHosts(now time.Time) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var hosts []string
+	for host, entry := range c.entries {
+		if !now.Before(entry.nextRefresh) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func (c *AddressCache) untilNextRefresh() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var next time.Time
+	for _, entry := range c.entries {
+		if next.IsZero() || entry.nextRefresh.Before(next) {
+			next = entry.nextRefresh
+		}
+	}
+	return max(time.Until(next), 0)
+}
@@ -0,0 +1,186 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddressCacheResolve(t *testing.T) {
+	resolver := &fakeResolver{ips: []string{"2.2.2.2", "1.1.1.1"}, ttl: time.Minute}
+	addressCache := NewAddressCache(resolver, []string{"3.3.3.3", "remote.example.com"}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addressCache.Start(ctx)
+
+	if resolved := addressCache.Resolve("3.3.3.3", "remote.example.com", "unknown.example.com"); !reflect.DeepEqual(resolved, []string{"3.3.3.3", "1.1.1.1", "2.2.2.2"}) {
+		t.Errorf("expected IP addresses followed by sorted resolved addresses, got %v", resolved)
+	}
+	if lookups := resolver.lookupCount(); lookups != 1 {
+		t.Errorf("expected only the hostname to be resolved once, got %d lookups", lookups)
+	}
+
+	var nilCache *AddressCache
+	if resolved := nilCache.Resolve("3.3.3.3", "remote.example.com"); !reflect.DeepEqual(resolved, []string{"3.3.3.3"}) {
+		t.Errorf("expected only IP addresses from a nil cache, got %v", resolved)
+	}
+}
+
+func TestAddressCacheRefresh(t *testing.T) {
+	resolver := &fakeResolver{ips: []string{"1.1.1.1"}, ttl: time.Millisecond}
+	changes := make(chan struct{}, 10)
+	addressCache := NewAddressCache(resolver, []string{"remote.example.com"}, func() {
+		changes <- struct{}{}
+	})
+	addressCache.minRefreshInterval = 10 * time.Millisecond
+	addressCache.initialBackoff = 10 * time.Millisecond
+	addressCache.maxBackoff = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addressCache.Start(ctx)
+
+	// records in the same order do not report a change
+	waitForLookups(t, resolver, 3)
+	select {
+	case <-changes:
+		t.Fatalf("unexpected change notification for unchanged addresses")
+	default:
+	}
+
+	// failures keep previously resolved addresses
+	resolver.set(nil, errors.New("SERVFAIL"))
+	waitForLookups(t, resolver, resolver.lookupCount()+3)
+	if resolved := addressCache.Resolve("remote.example.com"); !reflect.DeepEqual(resolved, []string{"1.1.1.1"}) {
+		t.Errorf("expected addresses resolved before the failure, got %v", resolved)
+	}
+
+	resolver.set([]string{"2.2.2.2"}, nil)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for a change notification")
+	}
+	if resolved := addressCache.Resolve("remote.example.com"); !reflect.DeepEqual(resolved, []string{"2.2.2.2"}) {
+		t.Errorf("expected changed addresses, got %v", resolved)
+	}
+}
+
+func TestAddressCacheRefreshInterval(t *testing.T) {
+	addressCache := NewAddressCache(&fakeResolver{}, nil, nil)
+
+	testCases := []struct {
+		name          string
+		ttl           time.Duration
+		err           error
+		previousFails int
+		expectedMin   time.Duration
+		expectedMax   time.Duration
+	}{{
+		name:        "TTL is honoured",
+		ttl:         time.Minute,
+		expectedMin: time.Minute,
+		expectedMax: time.Minute,
+	}, {
+		name:        "short TTL is raised to the minimum refresh interval",
+		ttl:         time.Second,
+		expectedMin: defaultMinRefreshInterval,
+		expectedMax: defaultMinRefreshInterval,
+	}, {
+		name:        "long TTL is capped by the maximum refresh interval",
+		ttl:         24 * time.Hour,
+		expectedMin: defaultMaxRefreshInterval,
+		expectedMax: defaultMaxRefreshInterval,
+	}, {
+		name:        "first failure is retried after the initial backoff",
+		err:         errors.New("timeout"),
+		expectedMin: defaultInitialBackoff,
+		expectedMax: time.Duration(float64(defaultInitialBackoff) * (1 + backoffJitter)),
+	}, {
+		name:          "backoff grows exponentially",
+		err:           errors.New("timeout"),
+		previousFails: 3,
+		expectedMin:   8 * defaultInitialBackoff,
+		expectedMax:   time.Duration(float64(8*defaultInitialBackoff) * (1 + backoffJitter)),
+	}, {
+		name:          "backoff is capped",
+		err:           errors.New("timeout"),
+		previousFails: 100,
+		expectedMin:   defaultMaxBackoff,
+		expectedMax:   time.Duration(float64(defaultMaxBackoff) * (1 + backoffJitter)),
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addressCache.entries["remote.example.com"] = &cacheEntry{failures: tc.previousFails}
+
+			start := time.Now()
+			addressCache.update("remote.example.com", []string{"1.1.1.1"}, tc.ttl, tc.err)
+			interval := addressCache.entries["remote.example.com"].nextRefresh.Sub(start)
+
+			// allow for the time passed between taking start and the update
+			if interval < tc.expectedMin-time.Second || interval > tc.expectedMax+time.Second {
+				t.Errorf("expected refresh in [%s, %s], got %s", tc.expectedMin, tc.expectedMax, interval)
+			}
+		})
+	}
+}
+
+// fakeResolver resolves every host to the same addresses.
+type fakeResolver struct {
+	mu      sync.Mutex
+	ips     []string
+	ttl     time.Duration
+	err     error
+	lookups int
+}
+
+func (r *fakeResolver) Lookup(_ context.Context, _ string) ([]string, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	return append([]string(nil), r.ips...), r.ttl, nil
+}
+
+func (r *fakeResolver) set(ips []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ips = ips
+	r.err = err
+}
+
+func (r *fakeResolver) lookupCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lookups
+}
+
+func waitForLookups(t *testing.T, resolver *fakeResolver, count int) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for resolver.lookupCount() < count {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d lookups, got %d", count, resolver.lookupCount())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	resolvConfPath = "/etc/resolv.conf"
+	lookupTimeout  = 5 * time.Second
+)
+
+// Resolver looks up IP addresses of a host and returns them together with the time they can be cached for.
+type Resolver interface {
+	Lookup(ctx context.Context, host string) ([]string, time.Duration, error)
+}
+
+// DNSResolver queries A and AAAA records directly from nameservers, because the resolver of the standard library
+// does not expose TTLs of the records. Hosts that are not fully qualified are resolved using the search list
+// and the ndots option, like the resolver of the standard library does.
+type DNSResolver struct {
+	servers   []string
+	search    []string
+	ndots     int
+	udpClient *dns.Client
+	tcpClient *dns.Client
+}
+
+var _ Resolver = (*DNSResolver)(nil)
+
+// NewDNSResolver creates a resolver querying the given nameservers in the host:port format in order.
+// If no nameservers are given, they are read from /etc/resolv.conf together with the search list.
+func NewDNSResolver(servers ...string) (*DNSResolver, error) {
+	conf := &dns.ClientConfig{Ndots: 1}
+	if len(servers) == 0 {
+		var err error
+		conf, err = dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nameservers from %s: %w", resolvConfPath, err)
+		}
+		for _, server := range conf.Servers {
+			servers = append(servers, net.JoinHostPort(server, conf.Port))
+		}
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no nameservers configured")
+	}
+	return &DNSResolver{
+		servers:   servers,
+		search:    conf.Search,
+		ndots:     conf.Ndots,
+		udpClient: &dns.Client{Net: "udp", Timeout: lookupTimeout},
+		tcpClient: &dns.Client{Net: "tcp", Timeout: lookupTimeout},
+	}, nil
+}
+
+// Lookup returns IPv4 and IPv6 addresses of the host and the lowest TTL of the returned records.
+// Names from the search list are tried in order until one of them has any addresses.
+func (r *DNSResolver) Lookup(ctx context.Context, host string) ([]string, time.Duration, error) {
+	conf := &dns.ClientConfig{Search: r.search, Ndots: r.ndots}
+	var errs []error
+	for _, name := range conf.NameList(host) {
+		ips, ttl, err := r.lookup(ctx, name)
+		if err == nil {
+			return ips, ttl, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, 0, errors.Join(errs...)
+}
+
+// lookup returns addresses of the fully qualified name. If only one of the A and AAAA queries fails,
+// addresses of the other family are returned.
+func (r *DNSResolver) lookup(ctx context.Context, name string) ([]string, time.Duration, error) {
+	var ips []string
+	var ttl uint32 = math.MaxUint32
+	var errs []error
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		answers, err := r.exchange(ctx, name, qtype)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, rr := range answers {
+			switch record := rr.(type) {
+			case *dns.A:
+				ips = append(ips, record.A.String())
+			case *dns.AAAA:
+				ips = append(ips, record.AAAA.String())
+			default:
+				// CNAMEs are followed by the nameserver and returned along with the addresses
+				continue
+			}
+			ttl = min(ttl, rr.Header().Ttl)
+		}
+	}
+	if len(ips) == 0 {
+		if len(errs) > 0 {
+			return nil, 0, errors.Join(errs...)
+		}
+		return nil, 0, fmt.Errorf("no addresses found for %s", name)
+	}
+	if len(errs) > 0 {
+		log.Debugf("Partially resolved %s: %v", name, errors.Join(errs...))
+	}
+	return ips, time.Duration(ttl) * time.Second, nil
+}
+
+// exchange sends the query for the fully qualified name to the nameservers in order until one of them answers.
+// A non-existent domain is reported immediately, because other nameservers are expected to give the same answer.
+func (r *DNSResolver) exchange(ctx context.Context, name string, qtype uint16) ([]dns.RR, error) {
+	query := new(dns.Msg)
+	query.SetQuestion(name, qtype)
+
+	var errs []error
+	for _, server := range r.servers {
+		resp, _, err := r.udpClient.ExchangeContext(ctx, query, server)
+		if err == nil && resp.Truncated {
+			resp, _, err = r.tcpClient.ExchangeContext(ctx, query, server)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			return resp.Answer, nil
+		case dns.RcodeNameError:
+			return nil, fmt.Errorf("host %s not found", name)
+		default:
+			errs = append(errs, fmt.Errorf("%s answered %s", server, dns.RcodeToString[resp.Rcode]))
+		}
+	}
+	return nil, fmt.Errorf("failed to resolve %s: %w", name, errors.Join(errs...))
+}
@@ -0,0 +1,179 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestDNSResolver(t *testing.T) {
+	nameserver := startFakeNameserver(t, nil,
+		"remote.example.com. 60 IN A 1.1.1.1",
+		"remote.example.com. 30 IN A 2.2.2.2",
+		"remote.example.com. 45 IN AAAA 2001:db8::1",
+		"ipv4.example.com. 120 IN A 3.3.3.3",
+		"empty.example.com. 60 IN TXT \"no addresses\"",
+	)
+	failingNameserver := startFakeNameserver(t, map[uint16]int{dns.TypeA: dns.RcodeServerFailure, dns.TypeAAAA: dns.RcodeServerFailure})
+	failingIPv6Nameserver := startFakeNameserver(t, map[uint16]int{dns.TypeAAAA: dns.RcodeServerFailure},
+		"remote.example.com. 60 IN A 1.1.1.1",
+	)
+
+	testCases := []struct {
+		name        string
+		servers     []string
+		search      []string
+		host        string
+		expectedIPs []string
+		expectedTTL time.Duration
+		expectErr   bool
+	}{{
+		name:        "IPv4 and IPv6 addresses are returned with the lowest TTL",
+		servers:     []string{nameserver},
+		host:        "remote.example.com",
+		expectedIPs: []string{"1.1.1.1", "2.2.2.2", "2001:db8::1"},
+		expectedTTL: 30 * time.Second,
+	}, {
+		name:        "host without IPv6 addresses is resolved",
+		servers:     []string{nameserver},
+		host:        "ipv4.example.com",
+		expectedIPs: []string{"3.3.3.3"},
+		expectedTTL: 120 * time.Second,
+	}, {
+		name:        "next nameserver is queried when the first one fails",
+		servers:     []string{failingNameserver, nameserver},
+		host:        "ipv4.example.com",
+		expectedIPs: []string{"3.3.3.3"},
+		expectedTTL: 120 * time.Second,
+	}, {
+		name:        "addresses are returned when only the IPv6 query fails",
+		servers:     []string{failingIPv6Nameserver},
+		host:        "remote.example.com",
+		expectedIPs: []string{"1.1.1.1"},
+		expectedTTL: 60 * time.Second,
+	}, {
+		name:        "short name is resolved using the search list",
+		servers:     []string{nameserver},
+		search:      []string{"svc.cluster.local.", "example.com."},
+		host:        "ipv4",
+		expectedIPs: []string{"3.3.3.3"},
+		expectedTTL: 120 * time.Second,
+	}, {
+		name:      "fully qualified name is not resolved using the search list",
+		servers:   []string{nameserver},
+		search:    []string{"example.com."},
+		host:      "ipv4.",
+		expectErr: true,
+	}, {
+		name:      "unknown host is an error",
+		servers:   []string{nameserver},
+		host:      "unknown.example.com",
+		expectErr: true,
+	}, {
+		name:      "host without addresses is an error",
+		servers:   []string{nameserver},
+		host:      "empty.example.com",
+		expectErr: true,
+	}, {
+		name:      "failure of all nameservers is an error",
+		servers:   []string{failingNameserver},
+		host:      "remote.example.com",
+		expectErr: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver, err := NewDNSResolver(tc.servers...)
+			if err != nil {
+				t.Fatalf("failed to create resolver: %v", err)
+			}
+			resolver.search = tc.search
+
+			ips, ttl, err := resolver.Lookup(context.Background(), tc.host)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got addresses %v", ips)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ips, tc.expectedIPs) {
+				t.Errorf("expected addresses %v, got %v", tc.expectedIPs, ips)
+			}
+			if ttl != tc.expectedTTL {
+				t.Errorf("expected TTL %s, got %s", tc.expectedTTL, ttl)
+			}
+		})
+	}
+}
+
+// startFakeNameserver serves the given records over UDP on a local port and returns the address of the server.
+// Queries for names without records are answered with NXDOMAIN, and queries of types in rcodes with the given code.
+func startFakeNameserver(t *testing.T, rcodes map[uint16]int, records ...string) string {
+	t.Helper()
+	recordsByName := make(map[string][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatalf("invalid record %q: %v", record, err)
+		}
+		recordsByName[rr.Header().Name] = append(recordsByName[rr.Header().Name], rr)
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        conn,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetReply(req)
+			question := req.Question[0]
+			rrs, found := recordsByName[question.Name]
+			rcode, failed := rcodes[question.Qtype]
+			switch {
+			case failed:
+				resp.Rcode = rcode
+			case !found:
+				resp.Rcode = dns.RcodeNameError
+			default:
+				for _, rr := range rrs {
+					if rr.Header().Rrtype == question.Qtype {
+						resp.Answer = append(resp.Answer, rr)
+					}
+				}
+			}
+			_ = w.WriteMsg(resp)
+		}),
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+	return conn.LocalAddr().String()
+}
@@ -0,0 +1,215 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	probeSuccess = "success"
+	probeFailure = "failure"
+
+	maxProbeTimeout = 5 * time.Second
+)
+
+var (
+	gatewayProbesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "federation_remote_gateway_probes_total",
+			Help: "Number of TLS handshakes with addresses of remote ingress gateways by result.",
+		},
+		[]string{"remote", "address", "result"},
+	)
+	gatewayHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_remote_gateway_healthy",
+			Help: "Whether the last TLS handshake with an address of a remote ingress gateway succeeded (1) or failed (0).",
+		},
+		[]string{"remote", "address"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(gatewayProbesTotal, gatewayHealthy)
+}
+
+// ProbeTarget is an address of a remote ingress gateway and the SNI presented in the TLS handshake.
+type ProbeTarget struct {
+	Remote  string
+	Address string
+	Port    uint32
+	SNI     string
+}
+
+// ProbeResult is the health of a probe target. Err is the reason of the last failed probe.
+type ProbeResult struct {
+	Target  ProbeTarget
+	Healthy bool
+	Err     error
+}
+
+type probeKey struct {
+	remote  string
+	address string
+}
+
+// GatewayProber periodically performs TLS handshakes with addresses of remote ingress gateways.
+// An address is healthy when the gateway answers the handshake, even if it rejects the client for not presenting
+// a certificate, because the prober only verifies that the gateway is reachable and does not authenticate to it.
+// Addresses that have not been probed yet are considered healthy.
+type GatewayProber struct {
+	targets  func() []ProbeTarget
+	interval time.Duration
+	timeout  time.Duration
+	onChange func([]ProbeResult)
+
+	mu      sync.RWMutex
+	results map[probeKey]ProbeResult
+}
+
+// NewGatewayProber creates a prober of the targets returned by the given function, which is called before
+// every round of probes, so that targets may change, e.g. when hostnames resolve to new addresses.
+// onChange is called with the results of targets whose health has changed in a round of probes.
+func NewGatewayProber(targets func() []ProbeTarget, interval time.Duration, onChange func([]ProbeResult)) *GatewayProber {
+	return &GatewayProber{
+		targets:  targets,
+		interval: interval,
+		timeout:  min(interval, maxProbeTimeout),
+		onChange: onChange,
+		results:  make(map[probeKey]ProbeResult),
+	}
+}
+
+// Start probes all targets every interval in the background until the context is cancelled.
+func (p *GatewayProber) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			if changed := p.probeAll(ctx); len(changed) > 0 && p.onChange != nil {
+				p.onChange(changed)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// IsHealthy returns false only if the last probe of the address of the remote failed. A nil prober reports
+// all addresses as healthy.
+func (p *GatewayProber) IsHealthy(remote, address string) bool {
+	if p == nil {
+		return true
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	result, found := p.results[probeKey{remote: remote, address: address}]
+	return !found || result.Healthy
+}
+
+// probeAll probes all targets concurrently and returns results of targets whose health has changed.
+// Results of targets that are no longer returned by the targets function are forgotten.
+func (p *GatewayProber) probeAll(ctx context.Context) []ProbeResult {
+	targets := p.targets()
+	results := make([]ProbeResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.probe(ctx, target)
+			results[i] = ProbeResult{Target: target, Healthy: err == nil, Err: err}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var changed []ProbeResult
+	current := make(map[probeKey]ProbeResult, len(results))
+	for _, result := range results {
+		key := probeKey{remote: result.Target.Remote, address: result.Target.Address}
+		current[key] = result
+		// targets are healthy until probed, so only failures of new targets are changes
+		if previous, found := p.results[key]; (found && previous.Healthy != result.Healthy) || (!found && !result.Healthy) {
+			changed = append(changed, result)
+		}
+		outcome := probeSuccess
+		if !result.Healthy {
+			outcome = probeFailure
+			log.Debugf("Gateway of remote %s at %s is unreachable: %v", result.Target.Remote, result.Target.Address, result.Err)
+		}
+		gatewayProbesTotal.WithLabelValues(result.Target.Remote, result.Target.Address, outcome).Inc()
+		gatewayHealthy.WithLabelValues(result.Target.Remote, result.Target.Address).Set(boolToFloat(result.Healthy))
+	}
+	for key := range p.results {
+		if _, found := current[key]; !found {
+			gatewayHealthy.DeleteLabelValues(key.remote, key.address)
+		}
+	}
+	p.results = current
+	return changed
+}
+
+// probe returns nil if the gateway answers a TLS handshake at the target address.
+func (p *GatewayProber) probe(ctx context.Context, target ProbeTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName: target.SNI,
+			// Certificates are not verified, because only reachability of the gateway is probed
+			// and proxies verify identities of remote services.
+			InsecureSkipVerify: true, //nolint:gosec
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.Address, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		// The gateway answered with a TLS alert, e.g. rejecting the client for not sending a certificate.
+		// crypto/tls reports received alerts only as "remote error" operation errors.
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "remote error" {
+			return nil
+		}
+		return err
+	}
+	_ = conn.Close()
+	return nil
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
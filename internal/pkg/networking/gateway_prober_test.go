@@ -0,0 +1,155 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	stdlog "log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGatewayProber(t *testing.T) {
+	var mu sync.Mutex
+	var receivedSNIs []string
+	gateway := httptest.NewUnstartedServer(http.NotFoundHandler())
+	gateway.Listener = listen(t, "127.0.0.1")
+	gateway.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			receivedSNIs = append(receivedSNIs, hello.ServerName)
+			return nil, nil
+		},
+	}
+	gateway.StartTLS()
+	defer gateway.Close()
+
+	// TLS 1.2 handshake fails on the client side when the server requires a client certificate
+	mtlsGateway := httptest.NewUnstartedServer(http.NotFoundHandler())
+	mtlsGateway.Listener = listen(t, "127.0.0.2")
+	mtlsGateway.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MaxVersion: tls.VersionTLS12}
+	mtlsGateway.Config.ErrorLog = stdlog.New(io.Discard, "", 0)
+	mtlsGateway.StartTLS()
+	defer mtlsGateway.Close()
+
+	plainListener := listen(t, "127.0.0.3")
+	defer plainListener.Close()
+	go func() {
+		for {
+			conn, err := plainListener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	closedListener := listen(t, "127.0.0.4")
+	_ = closedListener.Close()
+
+	healthyTarget := probeTarget(t, "west", gateway.Listener.Addr().String())
+	targets := []ProbeTarget{
+		healthyTarget,
+		probeTarget(t, "west", mtlsGateway.Listener.Addr().String()),
+		probeTarget(t, "east", plainListener.Addr().String()),
+		probeTarget(t, "east", closedListener.Addr().String()),
+	}
+	var targetsMu sync.Mutex
+	prober := NewGatewayProber(func() []ProbeTarget {
+		targetsMu.Lock()
+		defer targetsMu.Unlock()
+		return targets
+	}, time.Second, nil)
+
+	changed := prober.probeAll(context.Background())
+	expectedHealth := map[string]bool{
+		healthyTarget.Address: true,
+		targets[1].Address:    true,
+		targets[2].Address:    false,
+		targets[3].Address:    false,
+	}
+	for _, target := range targets {
+		if healthy := prober.IsHealthy(target.Remote, target.Address); healthy != expectedHealth[target.Address] {
+			t.Errorf("expected health of %s/%s to be %t, got %t", target.Remote, target.Address, expectedHealth[target.Address], healthy)
+		}
+	}
+	if len(changed) != 2 || changed[0].Healthy || changed[1].Healthy {
+		t.Errorf("expected only failures of new targets to be reported as changes, got %v", changed)
+	}
+	mu.Lock()
+	if len(receivedSNIs) != 1 || receivedSNIs[0] != healthyTarget.SNI {
+		t.Errorf("expected SNI %s, got %v", healthyTarget.SNI, receivedSNIs)
+	}
+	mu.Unlock()
+
+	if changed := prober.probeAll(context.Background()); len(changed) != 0 {
+		t.Errorf("expected no changes when health does not change, got %v", changed)
+	}
+
+	// the gateway goes down and the unreachable target is removed
+	gateway.Close()
+	targetsMu.Lock()
+	targets = targets[:3]
+	targetsMu.Unlock()
+
+	changed = prober.probeAll(context.Background())
+	if len(changed) != 1 || changed[0].Target != healthyTarget || changed[0].Healthy || changed[0].Err == nil {
+		t.Errorf("expected the closed gateway to be reported as unhealthy, got %v", changed)
+	}
+	if !prober.IsHealthy("east", "127.0.0.4") {
+		t.Errorf("expected targets that are no longer probed to be considered healthy")
+	}
+
+	var nilProber *GatewayProber
+	if !nilProber.IsHealthy("west", healthyTarget.Address) {
+		t.Errorf("expected a nil prober to report all addresses as healthy")
+	}
+}
+
+// listen listens on a random port of the given loopback address, so that each target has a different address.
+func listen(t *testing.T, ip string) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	return listener
+}
+
+func probeTarget(t *testing.T, remote, addr string) ProbeTarget {
+	t.Helper()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid address %s: %v", addr, err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("invalid port %s: %v", port, err)
+	}
+	return ProbeTarget{
+		Remote:  remote,
+		Address: host,
+		Port:    uint32(portNumber),
+		SNI:     "outbound_.15080_._.federation-discovery-service-" + remote + ".istio-system.svc.cluster.local",
+	}
+}
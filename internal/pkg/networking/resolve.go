@@ -14,33 +14,20 @@
 
 package networking
 
-import (
-	"net"
+import "net"
 
-	"istio.io/istio/pkg/log"
-	"istio.io/istio/pkg/slices"
-)
-
-func Resolve(addrs ...string) []string {
-	var allIPs []string
-	for _, addr := range addrs {
-		if IsIP(addr) {
-			allIPs = append(allIPs, addr)
-			continue
-		}
+func IsIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
 
-		ips, err := net.LookupIP(addr)
-		if err != nil {
-			log.Errorf("failed to resolve '%s': %v\n", addr, err)
-		}
-		stringIPs := slices.Map(ips, func(ip net.IP) string {
-			return ip.String()
-		})
-		allIPs = append(allIPs, stringIPs...)
-	}
-	return allIPs
+// IsIPv4 returns true if s is an IPv4 address, including IPv4 addresses mapped to IPv6.
+func IsIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil
 }
 
-func IsIP(s string) bool {
-	return net.ParseIP(s) != nil
+// IsIPv6 returns true if s is an IPv6 address.
+func IsIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
 }
@@ -0,0 +1,59 @@
+// Copyright Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the License);
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an AS IS BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networking
+
+import "testing"
+
+func TestIPFamily(t *testing.T) {
+	testCases := []struct {
+		addr       string
+		expectIP   bool
+		expectIPv4 bool
+		expectIPv6 bool
+	}{{
+		addr:       "192.168.0.1",
+		expectIP:   true,
+		expectIPv4: true,
+	}, {
+		addr:       "2001:db8::1",
+		expectIP:   true,
+		expectIPv6: true,
+	}, {
+		addr:       "::1",
+		expectIP:   true,
+		expectIPv6: true,
+	}, {
+		addr:       "::ffff:192.168.0.1",
+		expectIP:   true,
+		expectIPv4: true,
+	}, {
+		addr: "remote-ingress.net",
+	}, {
+		addr: "[2001:db8::1]",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			if IsIP(tc.addr) != tc.expectIP {
+				t.Errorf("expected IsIP to return %t", tc.expectIP)
+			}
+			if IsIPv4(tc.addr) != tc.expectIPv4 {
+				t.Errorf("expected IsIPv4 to return %t", tc.expectIPv4)
+			}
+			if IsIPv6(tc.addr) != tc.expectIPv6 {
+				t.Errorf("expected IsIPv6 to return %t", tc.expectIPv6)
+			}
+		})
+	}
+}
@@ -19,41 +19,41 @@ import (
 
 	routev1 "github.com/openshift/api/route/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	v1 "k8s.io/client-go/listers/core/v1"
 
+	"github.com/openshift-service-mesh/federation/internal/pkg/common"
 	"github.com/openshift-service-mesh/federation/internal/pkg/config"
 )
 
 type ConfigFactory struct {
-	cfg           config.Federation
-	serviceLister v1.ServiceLister
+	cfg                   config.Federation
+	exportedServiceLister *common.ExportedServiceLister
 }
 
 func NewConfigFactory(
 	cfg config.Federation,
-	serviceLister v1.ServiceLister,
+	exportedServiceLister *common.ExportedServiceLister,
 ) *ConfigFactory {
 	return &ConfigFactory{
-		cfg:           cfg,
-		serviceLister: serviceLister,
+		cfg:                   cfg,
+		exportedServiceLister: exportedServiceLister,
 	}
 }
 
 func (cf *ConfigFactory) Routes() ([]*routev1.Route, error) {
+	gatewayName := cf.cfg.MeshPeers.Local.Gateways.Ingress.GetName()
 	createRoute := func(svcName, svcNamespace string, port int32) *routev1.Route {
 		return &routev1.Route{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-%s-%d-to-federation-ingress-gateway", svcName, svcNamespace, port),
+				Name:      fmt.Sprintf("%s-%s-%d-to-%s", svcName, svcNamespace, port, gatewayName),
 				Namespace: cf.cfg.MeshPeers.Local.ControlPlane.Namespace,
-				Labels:    map[string]string{"federation.openshift-service-mesh.io/peer": "todo"},
+				Labels:    cf.cfg.PeerLabels(),
 			},
 			Spec: routev1.RouteSpec{
 				Host: fmt.Sprintf("%s-%d.%s.svc.cluster.local", svcName, port, svcNamespace),
 				To: routev1.RouteTargetReference{
 					Kind: "Service",
-					Name: "federation-ingress-gateway",
+					Name: gatewayName,
 				},
 				Port: &routev1.RoutePort{
 					TargetPort: intstr.FromString(cf.cfg.MeshPeers.Local.Gateways.Ingress.Port.Name),
@@ -66,18 +66,18 @@ func (cf *ConfigFactory) Routes() ([]*routev1.Route, error) {
 	}
 
 	routes := []*routev1.Route{
-		createRoute(fmt.Sprintf("federation-discovery-service-%s", cf.cfg.MeshPeers.Local.Name), "istio-system", 15080),
+		createRoute(cf.cfg.DiscoveryServiceName(), cf.cfg.DiscoveryServiceNamespace(), int32(cf.cfg.DiscoveryServicePort())),
 	}
-	for _, exportLabelSelector := range cf.cfg.ExportedServiceSet.GetLabelSelectors() {
-		matchLabels := labels.SelectorFromSet(exportLabelSelector.MatchLabels)
-		services, err := cf.serviceLister.List(matchLabels)
-		if err != nil {
-			return nil, fmt.Errorf("error listing services (selector=%s): %w", matchLabels, err)
+	services, err := cf.exportedServiceLister.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services {
+		if common.CheckExportable(svc) != nil {
+			continue
 		}
-		for _, svc := range services {
-			for _, port := range svc.Spec.Ports {
-				routes = append(routes, createRoute(svc.Name, svc.Namespace, port.Port))
-			}
+		for _, port := range svc.Spec.Ports {
+			routes = append(routes, createRoute(svc.Name, svc.Namespace, port.Port))
 		}
 	}
 	return routes, nil
@@ -37,9 +37,7 @@ type DeployOption interface {
 }
 
 // WithSpire configures spire integration for the deployed app.
-type WithSpire struct {
-	NoClusterArgs
-}
+type WithSpire struct{}
 
 func (w WithSpire) ApplyToEcho(appConfig *echo.Config) {
 	appConfig.Subsets = []echo.SubsetConfig{{
@@ -96,6 +94,16 @@ func (w WithSpire) ApplyGlobalArgs(args []string) ([]string, error) {
 	return append(args, "--set", "istio.spire.enabled=true"), nil
 }
 
+// ApplyRemoteClusterArgs sets trust domains of remote peers, because each cluster has its own trust domain in spire setup.
+func (w WithSpire) ApplyRemoteClusterArgs(clusters cluster.Clusters, args []string) ([]string, error) {
+	for idx, c := range clusters {
+		remoteCluster := Resolve(c)
+		args = append(args, "--set", fmt.Sprintf("federation.meshPeers.remotes[%d].trustDomain=%s.local", idx, remoteCluster.ContextName))
+	}
+
+	return args, nil
+}
+
 type RemoteAddressIngressIP struct {
 	NoGlobalArgs
 }